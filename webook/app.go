@@ -3,9 +3,11 @@ package main
 import (
 	"github.com/gin-gonic/gin"
 	"webook/internal/event"
+	"webook/internal/job"
 )
 
 type App struct {
 	server    *gin.Engine
 	consumers []event.Consumer
+	jobs      []job.Job
 }
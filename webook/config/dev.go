@@ -12,4 +12,8 @@ var Config = WebookConfig{
 	Kafka: KafkaConfig{
 		Addr: []string{"localhost:9092"},
 	},
+	Wechat: WechatConfig{
+		RedirectURL: "http://localhost:8080/oauth2/wechat/callback",
+		BaseURL:     "https://api.weixin.qq.com",
+	},
 }
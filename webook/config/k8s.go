@@ -9,4 +9,8 @@ var Config = WebookConfig{
 	Redis: RedisConfig{
 		Addr: "webook-redis:16381",
 	},
+	Wechat: WechatConfig{
+		RedirectURL: "https://webook.com/oauth2/wechat/callback",
+		BaseURL:     "https://api.weixin.qq.com",
+	},
 }
@@ -1,9 +1,10 @@
 package config
 
 type WebookConfig struct {
-	DB    DBConfig
-	Redis RedisConfig
-	Kafka KafkaConfig
+	DB     DBConfig
+	Redis  RedisConfig
+	Kafka  KafkaConfig
+	Wechat WechatConfig
 }
 
 type DBConfig struct {
@@ -17,3 +18,12 @@ type RedisConfig struct {
 type KafkaConfig struct {
 	Addr []string
 }
+
+type WechatConfig struct {
+	AppID     string
+	AppSecret string
+	// 微信登录成功之后回调我们的地址
+	RedirectURL string
+	// 微信开放平台 API 的地址
+	BaseURL string
+}
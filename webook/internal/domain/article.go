@@ -10,6 +10,36 @@ type Article struct {
 	Status  ArticleStatus
 	Ctime   time.Time
 	Utime   time.Time
+	// 编辑的时候拿到的版本号，保存的时候带回来，对不上就是冲突
+	Version int64
+	// 定时发表的时间，零值表示马上发表
+	PublishAt time.Time
+	// 归一化之后的标签
+	Tags []string
+	// 0 表示没有分类
+	CategoryId int64
+}
+
+// ArticleCursor 按照时间翻页的时候上一页的最后一条，零值表示从第一页开始
+type ArticleCursor struct {
+	// 毫秒数
+	Time int64
+	Aid  int64
+}
+
+func (c ArticleCursor) IsZero() bool {
+	return c.Time == 0 && c.Aid == 0
+}
+
+// ArticleFilter 创作者查自己的文章列表的过滤条件，零值表示不过滤
+type ArticleFilter struct {
+	Status ArticleStatus
+	// 标题里面包含这个关键字
+	Keyword string
+}
+
+func (f ArticleFilter) IsZero() bool {
+	return f.Status == ArticleStatusUnknown && f.Keyword == ""
 }
 
 func (a Article) Abstract() string {
@@ -18,7 +48,7 @@ func (a Article) Abstract() string {
 	if len(cs) < 100 {
 		return a.Content
 	}
-	return a.Content[:100]
+	return string(cs[:100])
 }
 
 const (
@@ -26,6 +56,8 @@ const (
 	ArticleStatusUnpublished
 	ArticleStatusPublished
 	ArticleStatusPrivate
+	// ArticleStatusScheduled 等着到点了自动发表
+	ArticleStatusScheduled
 )
 
 type ArticleStatus uint8
@@ -34,6 +66,10 @@ func (a ArticleStatus) ToUint8() uint8 {
 	return uint8(a)
 }
 
+func (a ArticleStatus) Valid() bool {
+	return a >= ArticleStatusUnpublished && a <= ArticleStatusScheduled
+}
+
 func (a ArticleStatus) NonPublished() bool {
 	return a != ArticleStatusPublished
 }
@@ -46,6 +82,8 @@ func (a ArticleStatus) String() string {
 		return "Published"
 	case ArticleStatusPrivate:
 		return "Private"
+	case ArticleStatusScheduled:
+		return "Scheduled"
 	default:
 		return "Unknown"
 
@@ -0,0 +1,45 @@
+package domain
+
+import "time"
+
+// ArticleRevision 文章某一次保存或者发表时候的样子
+type ArticleRevision struct {
+	Aid int64
+	// 同一篇文章从 1 开始递增
+	Version int64
+	Title   string
+	Content string
+	Status  ArticleStatus
+	Ctime   time.Time
+}
+
+type DiffOp uint8
+
+const (
+	DiffOpEqual DiffOp = iota
+	DiffOpInsert
+	DiffOpDelete
+)
+
+func (o DiffOp) String() string {
+	switch o {
+	case DiffOpInsert:
+		return "insert"
+	case DiffOpDelete:
+		return "delete"
+	default:
+		return "equal"
+	}
+}
+
+// DiffLine 按行比较的结果，Delete 是只有旧版本有的行，Insert 是只有新版本有的行
+type DiffLine struct {
+	Op   DiffOp
+	Text string
+}
+
+type RevisionDiff struct {
+	From  ArticleRevision
+	To    ArticleRevision
+	Lines []DiffLine
+}
@@ -0,0 +1,13 @@
+package domain
+
+// AuthorProfile 作者主页上公开的信息
+type AuthorProfile struct {
+	Id       int64
+	Nickname string
+	AboutMe  string
+
+	Followers int64
+	Followees int64
+	// 已经发表的文章数
+	Articles int64
+}
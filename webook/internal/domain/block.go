@@ -0,0 +1,10 @@
+package domain
+
+import "time"
+
+// BlockRelation Blocker 拉黑了 Blocked
+type BlockRelation struct {
+	Blocker int64
+	Blocked int64
+	Ctime   time.Time
+}
@@ -0,0 +1,41 @@
+package domain
+
+import "slices"
+
+// Category 分类是一棵固定的树，要改分类就改这里然后发版
+type Category struct {
+	Id   int64
+	Name string
+	// 0 表示顶层分类
+	ParentId int64
+}
+
+var categories = []Category{
+	{Id: 1, Name: "后端"},
+	{Id: 101, Name: "Go", ParentId: 1},
+	{Id: 102, Name: "Java", ParentId: 1},
+	{Id: 103, Name: "数据库", ParentId: 1},
+	{Id: 2, Name: "前端"},
+	{Id: 201, Name: "JavaScript", ParentId: 2},
+	{Id: 202, Name: "CSS", ParentId: 2},
+	{Id: 3, Name: "移动端"},
+	{Id: 301, Name: "Android", ParentId: 3},
+	{Id: 302, Name: "iOS", ParentId: 3},
+	{Id: 4, Name: "人工智能"},
+	{Id: 5, Name: "运维"},
+	{Id: 6, Name: "其他"},
+}
+
+// Categories 父分类一定排在子分类前面
+func Categories() []Category {
+	return slices.Clone(categories)
+}
+
+func CategoryById(id int64) (Category, bool) {
+	for _, c := range categories {
+		if c.Id == id {
+			return c, true
+		}
+	}
+	return Category{}, false
+}
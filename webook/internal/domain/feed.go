@@ -0,0 +1,16 @@
+package domain
+
+import "time"
+
+// FeedItem 首页信息流里面的一篇文章
+type FeedItem struct {
+	Article Article
+	// 进入信息流的时间，也就是发表的时间
+	Time time.Time
+}
+
+// Before 按照时间倒序，同一毫秒的按照 id 倒序
+func (c ArticleCursor) Before(item FeedItem) bool {
+	t := item.Time.UnixMilli()
+	return t < c.Time || (t == c.Time && item.Article.Id < c.Aid)
+}
@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// FollowRelation Follower 关注了 Followee
+type FollowRelation struct {
+	Follower int64
+	Followee int64
+	Ctime    time.Time
+}
+
+// FollowStatics 某个用户的关注数据
+type FollowStatics struct {
+	// 有多少粉丝
+	Followers int64
+	// 关注了多少人
+	Followees int64
+}
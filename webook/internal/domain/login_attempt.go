@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// LoginAttempts 最近一段时间内登录失败的情况
+type LoginAttempts struct {
+	// 这个账号连续失败了几次
+	AccountFails int
+	LastFail     time.Time
+	// 这个 IP 失败了几次，不区分账号
+	IPFails int
+	// 账号是否被临时锁定
+	Locked bool
+}
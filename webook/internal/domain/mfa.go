@@ -0,0 +1,9 @@
+package domain
+
+// MFA 用户的两步验证（TOTP）配置
+type MFA struct {
+	Uid    int64
+	Secret string
+	// 确认过一次验证码之后才算开启
+	Enabled bool
+}
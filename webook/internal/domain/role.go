@@ -0,0 +1,25 @@
+package domain
+
+// Role 用户的角色，一个用户可以有多个角色
+type Role string
+
+const (
+	// RoleReader 所有用户都有
+	RoleReader    Role = "reader"
+	RoleAuthor    Role = "author"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+func (r Role) Valid() bool {
+	switch r {
+	case RoleReader, RoleAuthor, RoleModerator, RoleAdmin:
+		return true
+	default:
+		return false
+	}
+}
+
+func (r Role) String() string {
+	return string(r)
+}
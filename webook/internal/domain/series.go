@@ -0,0 +1,56 @@
+package domain
+
+import "time"
+
+// Series 作者把自己发表过的文章按照顺序串起来，比如分成好几篇的教程
+type Series struct {
+	Id          int64
+	AuthorId    int64
+	Title       string
+	Description string
+	// 按照顺序排好的已发表文章，列表的时候不填
+	Articles []SeriesArticle
+	Ctime    time.Time
+	Utime    time.Time
+}
+
+// SeriesArticle 系列里面的一篇文章，只有导航要用的信息
+type SeriesArticle struct {
+	Id    int64
+	Title string
+}
+
+// SeriesNav 一篇文章在系列里面的位置
+type SeriesNav struct {
+	Sid   int64
+	Title string
+	// 从 1 开始
+	Index int
+	Total int
+	// Id 是 0 表示没有上一篇或者下一篇
+	Prev SeriesArticle
+	Next SeriesArticle
+}
+
+// Nav 文章不在系列里面，比如已经撤回了，返回 false
+func (s Series) Nav(aid int64) (SeriesNav, bool) {
+	for i, art := range s.Articles {
+		if art.Id != aid {
+			continue
+		}
+		nav := SeriesNav{
+			Sid:   s.Id,
+			Title: s.Title,
+			Index: i + 1,
+			Total: len(s.Articles),
+		}
+		if i > 0 {
+			nav.Prev = s.Articles[i-1]
+		}
+		if i < len(s.Articles)-1 {
+			nav.Next = s.Articles[i+1]
+		}
+		return nav, true
+	}
+	return SeriesNav{}, false
+}
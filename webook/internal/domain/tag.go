@@ -0,0 +1,33 @@
+package domain
+
+import "strings"
+
+const (
+	// MaxArticleTags 一篇文章最多几个标签
+	MaxArticleTags = 5
+	// MaxTagLength 标签最长多少个字符
+	MaxTagLength = 20
+)
+
+// NormalizeTag 去掉首尾空白，中间连续的空白换成一个空格，英文统一小写
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.Join(strings.Fields(tag), " "))
+}
+
+// NormalizeTags 归一化之后去掉空的和重复的，保持原来的顺序
+func NormalizeTags(tags []string) []string {
+	var res []string
+	seen := make(map[string]struct{}, len(tags))
+	for _, tag := range tags {
+		tag = NormalizeTag(tag)
+		if tag == "" {
+			continue
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		res = append(res, tag)
+	}
+	return res
+}
@@ -7,6 +7,8 @@ type User struct {
 	Id       int64
 	Email    string
 	Password string
+	// 邮箱是否已经通过邮件里面的链接确认过
+	EmailVerified bool
 
 	Nickname string
 	// YYYY-MM-DD
@@ -15,8 +17,33 @@ type User struct {
 
 	Phone string
 
+	// 微信登录绑定的身份
+	WechatInfo WechatInfo
+
+	// 至少有 RoleReader
+	Roles []Role
+
 	// UTC 0 的时区
 	Ctime time.Time
 
+	// 申请注销之后，过了这个时间就会被清除，零值表示没有申请
+	DeleteAfter time.Time
+	// 已经清除了个人数据，只剩一个空壳
+	Purged bool
+
 	//Addr Address
 }
+
+// HasVerifiedEmail 需要给用户发邮件的功能可以要求邮箱已经验证过
+func (u User) HasVerifiedEmail() bool {
+	return u.Email != "" && u.EmailVerified
+}
+
+func (u User) HasRole(role Role) bool {
+	for _, r := range u.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
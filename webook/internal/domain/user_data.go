@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// UserDataExport 我们保存的某个用户的全部个人数据
+type UserDataExport struct {
+	Profile User
+	// 所有草稿，包括没发表的
+	Articles []Article
+	// 线上读者看到的版本
+	PublishedArticles []Article
+	Likes             []UserBiz
+	Collections       []UserBiz
+}
+
+// UserBiz 用户对某个业务对象的一次点赞或者收藏
+type UserBiz struct {
+	Biz   string
+	BizId int64
+	// 收藏夹 ID，点赞没有
+	Cid   int64
+	Ctime time.Time
+}
@@ -0,0 +1,9 @@
+package domain
+
+// WechatInfo 微信扫码登录拿到的身份
+type WechatInfo struct {
+	// 在这个应用下唯一
+	OpenId string
+	// 在同一个公司主体的所有应用下唯一
+	UnionId string
+}
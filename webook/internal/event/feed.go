@@ -0,0 +1,55 @@
+package event
+
+import (
+	"context"
+	"github.com/IBM/sarama"
+	"time"
+	"webook/internal/domain"
+	"webook/pkg/logger"
+	"webook/pkg/samarax"
+)
+
+// FeedFanOut 由 service.FeedService 实现，
+// 这里不能直接依赖 service，service 要用这个包来发消息
+type FeedFanOut interface {
+	FanOut(ctx context.Context, art domain.Article) error
+}
+
+type FeedPublishedEventConsumer struct {
+	svc    FeedFanOut
+	client sarama.Client
+	l      logger.LoggerV1
+}
+
+func NewFeedPublishedEventConsumer(svc FeedFanOut, client sarama.Client, l logger.LoggerV1) *FeedPublishedEventConsumer {
+	return &FeedPublishedEventConsumer{svc: svc, client: client, l: l}
+}
+
+func (f *FeedPublishedEventConsumer) Start() error {
+	cg, err := sarama.NewConsumerGroupFromClient("feed", f.client)
+	if err != nil {
+		return err
+	}
+	go func() {
+		er := cg.Consume(context.Background(),
+			[]string{TopicPublishedEvent},
+			samarax.NewHandler[PublishedEvent](f.l, f.Consume))
+		if er != nil {
+			f.l.Error("退出消费", logger.Error(er))
+		}
+	}()
+	return err
+}
+
+func (f *FeedPublishedEventConsumer) Consume(msg *sarama.ConsumerMessage, evt PublishedEvent) error {
+	// 小作者的读者最多一千个，推一次不会太久
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+	return f.svc.FanOut(ctx, domain.Article{
+		Id: evt.Aid,
+		Author: domain.Author{
+			Id: evt.Uid,
+		},
+		Utime: time.UnixMilli(evt.Ctime),
+	})
+}
@@ -5,10 +5,14 @@ import (
 	"github.com/IBM/sarama"
 )
 
-const TopicReadEvent = "article_read"
+const (
+	TopicReadEvent      = "article_read"
+	TopicPublishedEvent = "article_published"
+)
 
 type Producer interface {
 	ProduceReadEvent(evt ReadEvent) error
+	ProducePublishedEvent(evt PublishedEvent) error
 }
 
 type ReadEvent struct {
@@ -16,6 +20,15 @@ type ReadEvent struct {
 	Uid int64
 }
 
+// PublishedEvent 文章发表了，用来推信息流
+type PublishedEvent struct {
+	Aid int64
+	// 作者
+	Uid int64
+	// 发表的时间，毫秒数
+	Ctime int64
+}
+
 type BatchReadEvent struct {
 	Aids []int64
 	Uids []int64
@@ -40,3 +53,15 @@ func (s *SaramaSyncProducer) ProduceReadEvent(evt ReadEvent) error {
 	})
 	return err
 }
+
+func (s *SaramaSyncProducer) ProducePublishedEvent(evt PublishedEvent) error {
+	val, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+	_, _, err = s.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: TopicPublishedEvent,
+		Value: sarama.StringEncoder(val),
+	})
+	return err
+}
@@ -0,0 +1,6 @@
+package job
+
+// Job 后台任务，Start 不能阻塞
+type Job interface {
+	Start() error
+}
@@ -0,0 +1,57 @@
+package job
+
+import (
+	"context"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/service"
+	"webook/pkg/logger"
+)
+
+// ScheduledPublishJob 每隔一段时间把到点的定时文章发表出去
+// 每个实例都会跑，重复发表由 service 里面的版本号保证不会发生
+type ScheduledPublishJob struct {
+	svc      service.ArticleService
+	interval time.Duration
+	// 一次最多发表多少篇
+	batch int
+	l     logger.LoggerV1
+}
+
+func NewScheduledPublishJob(svc service.ArticleService, l logger.LoggerV1) *ScheduledPublishJob {
+	return &ScheduledPublishJob{
+		svc:      svc,
+		interval: time.Second * 10,
+		batch:    100,
+		l:        l,
+	}
+}
+
+func (j *ScheduledPublishJob) Start() error {
+	go func() {
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			j.run()
+		}
+	}()
+	return nil
+}
+
+func (j *ScheduledPublishJob) run() {
+	// 积压的多就一批接一批地往后翻，直到翻到头
+	var cursor domain.ArticleCursor
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		next, err := j.svc.PublishDue(ctx, cursor, j.batch)
+		cancel()
+		if err != nil {
+			j.l.Error("查询到点的定时文章失败", logger.Error(err))
+			return
+		}
+		if next.IsZero() {
+			return
+		}
+		cursor = next
+	}
+}
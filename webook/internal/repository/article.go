@@ -4,6 +4,8 @@ import (
 	"context"
 	"github.com/ecodeclub/ekit/slice"
 	"gorm.io/gorm"
+	"math"
+	"slices"
 	"time"
 	"webook/internal/domain"
 	"webook/internal/repository/cache"
@@ -16,14 +18,53 @@ type ArticleRepository interface {
 	Update(ctx context.Context, art domain.Article) error
 	Sync(ctx context.Context, art domain.Article) (int64, error)
 	SyncStatus(ctx context.Context, uid int64, aid int64, status domain.ArticleStatus) error
-	GetByAuthor(ctx context.Context, uid int64, offset int, limit int) ([]domain.Article, error)
+	// GetByAuthor 创作者自己的文章，按照更新时间倒序
+	GetByAuthor(ctx context.Context, uid int64, filter domain.ArticleFilter,
+		cursor domain.ArticleCursor, limit int) ([]domain.Article, error)
 	GetByID(ctx context.Context, id int64) (domain.Article, error)
 	GetPubById(ctx context.Context, id int64) (domain.Article, error)
+	// GetPubByAuthor 作者已经发表的文章，只有摘要
+	GetPubByAuthor(ctx context.Context, uid int64, offset int, limit int) ([]domain.Article, error)
+	CountPubByAuthor(ctx context.Context, uid int64) (int64, error)
+	// ListPub 所有作者已经发表的文章，按照发表时间倒序，只有摘要
+	// uid 是读者，会过滤掉他拉黑的作者，没登录传 0
+	ListPub(ctx context.Context, uid int64, cursor domain.ArticleCursor, limit int) ([]domain.Article, error)
+
+	GetRevisions(ctx context.Context, uid int64, aid int64, offset int, limit int) ([]domain.ArticleRevision, error)
+	GetRevision(ctx context.Context, uid int64, aid int64, version int64) (domain.ArticleRevision, error)
+	PruneRevisions(ctx context.Context, aid int64, keep int) error
+
+	// GetScheduledByAuthor 作者还没到点的定时文章，先发表的在前面
+	GetScheduledByAuthor(ctx context.Context, uid int64, offset int, limit int) ([]domain.Article, error)
+	// GetDueScheduled 已经到点，等着发表的定时文章，按照发表时间正序排在 cursor 后面的
+	GetDueScheduled(ctx context.Context, now time.Time, cursor domain.ArticleCursor, limit int) ([]domain.Article, error)
+	// CancelSchedule 取消定时发表，文章回到草稿
+	CancelSchedule(ctx context.Context, uid int64, aid int64) error
+
+	// GetPubByTag 带这个标签的已发表文章，按照发表时间倒序，只有摘要
+	// uid 是读者，会过滤掉他拉黑的作者，没登录传 0
+	GetPubByTag(ctx context.Context, uid int64, tag string, offset int, limit int) ([]domain.Article, error)
+	CountPubByTag(ctx context.Context, uid int64, tag string) (int64, error)
 }
 
+var (
+	ErrRevisionNotFound = dao.ErrRecordNotFound
+	ErrArticleConflict  = dao.ErrArticleVersionConflict
+	ErrScheduleNotFound = dao.ErrRecordNotFound
+)
+
+const (
+	// 创作者文章列表缓存第一页的时候一次查这么多，比这个小的 limit 都可以用缓存
+	firstPageSize = 100
+	// 作者主页缓存第一页的时候一次查这么多
+	pubFirstPageSize = 50
+)
+
 type CachedArticleRepository struct {
 	dao      dao.ArticleDAO
 	userRepo UserRepository
+	// 系列里面缓存了文章标题和顺序，发表、撤回之后要删掉
+	seriesRepo SeriesRepository
 
 	// sync v1  with 2-dao
 	readDAO dao.ReaderDAO
@@ -37,11 +78,14 @@ type CachedArticleRepository struct {
 	log logger.LoggerV1
 }
 
-func NewArticleRepository(dao dao.ArticleDAO, log logger.LoggerV1) ArticleRepository {
-
+func NewArticleRepository(dao dao.ArticleDAO, cache cache.ArticleCache,
+	userRepo UserRepository, seriesRepo SeriesRepository, log logger.LoggerV1) ArticleRepository {
 	return &CachedArticleRepository{
-		dao: dao,
-		log: log,
+		dao:        dao,
+		cache:      cache,
+		userRepo:   userRepo,
+		seriesRepo: seriesRepo,
+		log:        log,
 	}
 }
 
@@ -63,6 +107,13 @@ func (c *CachedArticleRepository) Update(ctx context.Context, art domain.Article
 		if er != nil {
 			// 也要记录日志
 		}
+		// 恢复历史版本之后马上就会打开编辑，不能读到旧的草稿
+		er = c.cache.Del(ctx, art.Id)
+		if er != nil {
+			c.log.Error("删除文章缓存失败",
+				logger.Int64("aid", art.Id),
+				logger.Error(er))
+		}
 	}
 	return err
 }
@@ -74,26 +125,24 @@ func (c *CachedArticleRepository) Sync(ctx context.Context, art domain.Article)
 		if er != nil {
 			// 也要记录日志
 		}
-	}
-	// 在这里尝试，设置缓存
-	go func() {
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-		defer cancel()
-		// 你可以灵活设置过期时间
-		user, er := c.userRepo.FindById(ctx, art.Author.Id)
+		er = c.cache.DelPubFirstPage(ctx, art.Author.Id)
 		if er != nil {
-			// 要记录日志
-			return
-		}
-		art.Author = domain.Author{
-			Id:   user.Id,
-			Name: user.Nickname,
+			c.log.Error("删除作者主页缓存失败",
+				logger.Int64("uid", art.Author.Id),
+				logger.Error(er))
 		}
-		er = c.cache.SetPub(ctx, art)
+		// 版本号和状态都变了
+		er = c.cache.Del(ctx, id)
 		if er != nil {
-			// 记录日志
+			c.log.Error("删除文章缓存失败",
+				logger.Int64("aid", id),
+				logger.Error(er))
 		}
-	}()
+		// 标题可能改了，也可能是重新发表
+		c.delSeriesCache(ctx, id)
+	}
+	// 不在这里回写线上版本的缓存：失败了不能写，成功了版本号也是数据库里加的，
+	// 上面已经删掉了，等第一次读的时候再缓存
 	return id, err
 }
 
@@ -104,25 +153,55 @@ func (c *CachedArticleRepository) SyncStatus(ctx context.Context, uid int64, aid
 		if er != nil {
 			// 也要记录日志
 		}
+		er = c.cache.DelPubFirstPage(ctx, uid)
+		if er != nil {
+			c.log.Error("删除作者主页缓存失败",
+				logger.Int64("uid", uid),
+				logger.Error(er))
+		}
+		// 版本号加了一，草稿不删的话作者怎么保存都是冲突；线上版本撤回了也不能再给人看
+		er = c.cache.Del(ctx, aid)
+		if er != nil {
+			c.log.Error("删除文章缓存失败",
+				logger.Int64("aid", aid),
+				logger.Error(er))
+		}
+		// 撤回了的文章不能再出现在系列的上一篇下一篇里面
+		c.delSeriesCache(ctx, aid)
 	}
 	return err
 }
 
-func (c *CachedArticleRepository) GetByAuthor(ctx context.Context, uid int64, offset int, limit int) ([]domain.Article, error) {
+// delSeriesCache 删不掉就等系列缓存自己过期
+func (c *CachedArticleRepository) delSeriesCache(ctx context.Context, aid int64) {
+	err := c.seriesRepo.DelCacheByArticle(ctx, aid)
+	if err != nil {
+		c.log.Error("删除文章所在系列的缓存失败",
+			logger.Int64("aid", aid),
+			logger.Error(err))
+	}
+}
 
+func (c *CachedArticleRepository) GetByAuthor(ctx context.Context, uid int64, filter domain.ArticleFilter,
+	cursor domain.ArticleCursor, limit int) ([]domain.Article, error) {
 	// 首先第一步，判定要不要查询缓存
-	// 事实上， limit <= 100 都可以查询缓存
-	if offset == 0 && limit == 100 {
-		//if offset == 0 && limit <= 100 {
+	// 没有过滤条件的第一页，limit <= 100 都可以查询缓存
+	cacheable := cursor.IsZero() && filter.IsZero() && limit <= firstPageSize
+	if cacheable {
 		res, err := c.cache.GetFirstPage(ctx, uid)
 		if err == nil {
-			return res, err
-		} else {
-			// 要考虑记录日志
-			// 缓存未命中，你是可以忽略的
+			return c.truncate(res, limit), nil
 		}
+		// 缓存未命中，你是可以忽略的
 	}
-	arts, err := c.dao.GetByAuthor(ctx, uid, offset, limit)
+	size := limit
+	if cacheable {
+		// 多查一点，凑满整个第一页再缓存
+		size = firstPageSize
+	}
+	cursor = normalizeCursor(cursor)
+	arts, err := c.dao.GetByAuthor(ctx, uid, filter.Status.ToUint8(), filter.Keyword,
+		cursor.Time, cursor.Aid, size)
 	if err != nil {
 		return nil, err
 	}
@@ -130,18 +209,17 @@ func (c *CachedArticleRepository) GetByAuthor(ctx context.Context, uid int64, of
 		return c.toDomain(src)
 	})
 
-	go func() {
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
-		defer cancel()
-		if offset == 0 && limit == 100 {
+	if cacheable {
+		// SetFirstPage 会把内容换成摘要，给它一份拷贝
+		err = c.cache.SetFirstPage(ctx, uid, slices.Clone(res))
+		if err != nil {
 			// 缓存回写失败，不一定是大问题，但有可能是大问题
-			err = c.cache.SetFirstPage(ctx, uid, res)
-			if err != nil {
-				// 记录日志
-				// 我需要监控这里
-			}
+			c.log.Error("回写文章列表第一页缓存失败",
+				logger.Int64("uid", uid),
+				logger.Error(err))
 		}
-	}()
+		res = c.truncate(res, limit)
+	}
 	go func() {
 		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 		defer cancel()
@@ -198,18 +276,180 @@ func (c *CachedArticleRepository) GetPubById(ctx context.Context, id int64) (dom
 	return res, nil
 }
 
+func (c *CachedArticleRepository) GetPubByAuthor(ctx context.Context, uid int64, offset int, limit int) ([]domain.Article, error) {
+	cacheable := offset == 0 && limit <= pubFirstPageSize
+	if cacheable {
+		res, err := c.cache.GetPubFirstPage(ctx, uid)
+		if err == nil {
+			return c.truncate(res, limit), nil
+		}
+	}
+	size := limit
+	if cacheable {
+		// 缓存未命中，多查一点把第一页缓存起来
+		size = pubFirstPageSize
+	}
+	arts, err := c.dao.GetPubByAuthor(ctx, uid, domain.ArticleStatusPublished.ToUint8(), offset, size)
+	if err != nil {
+		return nil, err
+	}
+	res := slice.Map[dao.PublishedArticle, domain.Article](arts, func(idx int, src dao.PublishedArticle) domain.Article {
+		art := c.toDomain(dao.Article(src))
+		art.Content = art.Abstract()
+		return art
+	})
+	if cacheable {
+		err = c.cache.SetPubFirstPage(ctx, uid, slices.Clone(res))
+		if err != nil {
+			c.log.Error("回写作者主页缓存失败",
+				logger.Int64("uid", uid),
+				logger.Error(err))
+		}
+		return c.truncate(res, limit), nil
+	}
+	return res, nil
+}
+
+func (c *CachedArticleRepository) CountPubByAuthor(ctx context.Context, uid int64) (int64, error) {
+	return c.dao.CountPubByAuthor(ctx, uid, domain.ArticleStatusPublished.ToUint8())
+}
+
+func (c *CachedArticleRepository) ListPub(ctx context.Context, uid int64, cursor domain.ArticleCursor, limit int) ([]domain.Article, error) {
+	cursor = normalizeCursor(cursor)
+	arts, err := c.dao.ListPub(ctx, uid, domain.ArticleStatusPublished.ToUint8(), cursor.Time, cursor.Aid, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map[dao.PublishedArticle, domain.Article](arts, func(idx int, src dao.PublishedArticle) domain.Article {
+		art := c.toDomain(dao.Article(src))
+		art.Content = art.Abstract()
+		return art
+	}), nil
+}
+
+func (c *CachedArticleRepository) GetRevisions(ctx context.Context, uid int64, aid int64,
+	offset int, limit int) ([]domain.ArticleRevision, error) {
+	revs, err := c.dao.GetRevisions(ctx, aid, uid, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(revs, func(idx int, src dao.ArticleRevision) domain.ArticleRevision {
+		return c.revisionToDomain(src)
+	}), nil
+}
+
+func (c *CachedArticleRepository) GetRevision(ctx context.Context, uid int64, aid int64, version int64) (domain.ArticleRevision, error) {
+	rev, err := c.dao.GetRevision(ctx, aid, uid, version)
+	if err != nil {
+		return domain.ArticleRevision{}, err
+	}
+	return c.revisionToDomain(rev), nil
+}
+
+func (c *CachedArticleRepository) PruneRevisions(ctx context.Context, aid int64, keep int) error {
+	return c.dao.PruneRevisions(ctx, aid, keep)
+}
+
+func (c *CachedArticleRepository) GetScheduledByAuthor(ctx context.Context, uid int64,
+	offset int, limit int) ([]domain.Article, error) {
+	arts, err := c.dao.GetScheduledByAuthor(ctx, uid, domain.ArticleStatusScheduled.ToUint8(), offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(arts, func(idx int, src dao.Article) domain.Article {
+		art := c.toDomain(src)
+		art.Content = art.Abstract()
+		return art
+	}), nil
+}
+
+func (c *CachedArticleRepository) GetDueScheduled(ctx context.Context, now time.Time,
+	cursor domain.ArticleCursor, limit int) ([]domain.Article, error) {
+	// 发表的时候要用全文，不能走缓存
+	arts, err := c.dao.GetDueScheduled(ctx, domain.ArticleStatusScheduled.ToUint8(), now.UnixMilli(),
+		cursor.Time, cursor.Aid, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(arts, func(idx int, src dao.Article) domain.Article {
+		return c.toDomain(src)
+	}), nil
+}
+
+func (c *CachedArticleRepository) CancelSchedule(ctx context.Context, uid int64, aid int64) error {
+	err := c.dao.CancelSchedule(ctx, uid, aid,
+		domain.ArticleStatusScheduled.ToUint8(), domain.ArticleStatusUnpublished.ToUint8())
+	if err != nil {
+		return err
+	}
+	er := c.cache.DelFirstPage(ctx, uid)
+	if er != nil {
+		c.log.Error("删除文章列表缓存失败",
+			logger.Int64("uid", uid),
+			logger.Error(er))
+	}
+	er = c.cache.Del(ctx, aid)
+	if er != nil {
+		c.log.Error("删除文章缓存失败",
+			logger.Int64("aid", aid),
+			logger.Error(er))
+	}
+	return nil
+}
+
+func (c *CachedArticleRepository) GetPubByTag(ctx context.Context, uid int64, tag string, offset int, limit int) ([]domain.Article, error) {
+	arts, err := c.dao.GetPubByTag(ctx, uid, tag, domain.ArticleStatusPublished.ToUint8(), offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return slice.Map(arts, func(idx int, src dao.PublishedArticle) domain.Article {
+		art := c.toDomain(dao.Article(src))
+		art.Content = art.Abstract()
+		return art
+	}), nil
+}
+
+func (c *CachedArticleRepository) CountPubByTag(ctx context.Context, uid int64, tag string) (int64, error) {
+	return c.dao.CountPubByTag(ctx, uid, tag, domain.ArticleStatusPublished.ToUint8())
+}
+
+func (c *CachedArticleRepository) revisionToDomain(rev dao.ArticleRevision) domain.ArticleRevision {
+	return domain.ArticleRevision{
+		Aid:     rev.Aid,
+		Version: rev.Version,
+		Title:   rev.Title,
+		Content: rev.Content,
+		Status:  domain.ArticleStatus(rev.Status),
+		Ctime:   time.UnixMilli(rev.Ctime),
+	}
+}
+
+func (c *CachedArticleRepository) truncate(arts []domain.Article, limit int) []domain.Article {
+	if len(arts) > limit {
+		return arts[:limit]
+	}
+	return arts
+}
+
 func (c *CachedArticleRepository) toEntity(art domain.Article) dao.Article {
-	return dao.Article{
-		Id:       art.Id,
-		Title:    art.Title,
-		Content:  art.Content,
-		AuthorId: art.Author.Id,
-		Status:   art.Status.ToUint8(),
+	res := dao.Article{
+		Id:         art.Id,
+		Title:      art.Title,
+		Content:    art.Content,
+		AuthorId:   art.Author.Id,
+		Status:     art.Status.ToUint8(),
+		Version:    art.Version,
+		Tags:       art.Tags,
+		CategoryId: art.CategoryId,
+	}
+	if !art.PublishAt.IsZero() {
+		res.PublishAt = art.PublishAt.UnixMilli()
 	}
+	return res
 }
 
 func (c *CachedArticleRepository) toDomain(art dao.Article) domain.Article {
-	return domain.Article{
+	res := domain.Article{
 		Id:      art.Id,
 		Title:   art.Title,
 		Content: art.Content,
@@ -217,10 +457,17 @@ func (c *CachedArticleRepository) toDomain(art dao.Article) domain.Article {
 			// 这里有一个错误
 			Id: art.AuthorId,
 		},
-		Ctime:  time.UnixMilli(art.Ctime),
-		Utime:  time.UnixMilli(art.Utime),
-		Status: domain.ArticleStatus(art.Status),
+		Ctime:      time.UnixMilli(art.Ctime),
+		Utime:      time.UnixMilli(art.Utime),
+		Status:     domain.ArticleStatus(art.Status),
+		Version:    art.Version,
+		Tags:       art.Tags,
+		CategoryId: art.CategoryId,
+	}
+	if art.PublishAt > 0 {
+		res.PublishAt = time.UnixMilli(art.PublishAt)
 	}
+	return res
 }
 
 func (c *CachedArticleRepository) preCache(ctx context.Context, arts []domain.Article) {
@@ -276,3 +523,11 @@ func (c *CachedArticleRepository) preCache(ctx context.Context, arts []domain.Ar
 //	return id, err
 //
 //}
+
+// normalizeCursor 第一页的时候，什么都比游标早
+func normalizeCursor(cursor domain.ArticleCursor) domain.ArticleCursor {
+	if cursor.IsZero() {
+		return domain.ArticleCursor{Time: math.MaxInt64, Aid: math.MaxInt64}
+	}
+	return cursor
+}
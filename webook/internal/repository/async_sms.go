@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"webook/internal/repository/dao"
+	"webook/pkg/sms/async"
+)
+
+// AsyncSMSRepository 就是 async.Store 的 MySQL 实现
+type AsyncSMSRepository interface {
+	Add(ctx context.Context, msg async.Message) error
+	Preempt(ctx context.Context) (async.Message, error)
+	Success(ctx context.Context, msg async.Message) error
+	Retry(ctx context.Context, msg async.Message, nextRetry time.Time) error
+	Fail(ctx context.Context, msg async.Message) error
+}
+
+type GORMAsyncSMSRepository struct {
+	dao dao.AsyncSMSDAO
+}
+
+func NewAsyncSMSRepository(dao dao.AsyncSMSDAO) AsyncSMSRepository {
+	return &GORMAsyncSMSRepository{
+		dao: dao,
+	}
+}
+
+func (r *GORMAsyncSMSRepository) Add(ctx context.Context, msg async.Message) error {
+	return r.dao.Insert(ctx, dao.AsyncSMS{
+		Config: dao.SMSConfig{
+			TplId:   msg.TplId,
+			Args:    msg.Args,
+			Numbers: msg.Numbers,
+		},
+	})
+}
+
+func (r *GORMAsyncSMSRepository) Preempt(ctx context.Context) (async.Message, error) {
+	s, err := r.dao.GetWaitingSMS(ctx)
+	if err == dao.ErrWaitingSMSNotFound {
+		return async.Message{}, async.ErrNoMessage
+	}
+	if err != nil {
+		return async.Message{}, err
+	}
+	return async.Message{
+		Id:          s.Id,
+		TplId:       s.Config.TplId,
+		Args:        s.Config.Args,
+		Numbers:     s.Config.Numbers,
+		RetryCnt:    s.RetryCnt,
+		PreemptedAt: time.UnixMilli(s.Utime),
+	}, nil
+}
+
+func (r *GORMAsyncSMSRepository) Success(ctx context.Context, msg async.Message) error {
+	return r.toStoreErr(r.dao.MarkSuccess(ctx, msg.Id, msg.PreemptedAt.UnixMilli()))
+}
+
+func (r *GORMAsyncSMSRepository) Retry(ctx context.Context, msg async.Message, nextRetry time.Time) error {
+	return r.toStoreErr(r.dao.MarkRetry(ctx, msg.Id, msg.PreemptedAt.UnixMilli(), nextRetry.UnixMilli()))
+}
+
+func (r *GORMAsyncSMSRepository) Fail(ctx context.Context, msg async.Message) error {
+	return r.toStoreErr(r.dao.MarkFailed(ctx, msg.Id, msg.PreemptedAt.UnixMilli()))
+}
+
+func (r *GORMAsyncSMSRepository) toStoreErr(err error) error {
+	if err == dao.ErrAsyncSMSPreemptLost {
+		return async.ErrPreemptLost
+	}
+	return err
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository/cache"
+	"webook/internal/repository/dao"
+	"webook/pkg/logger"
+)
+
+type BlockRepository interface {
+	Block(ctx context.Context, blocker, blocked int64) error
+	Unblock(ctx context.Context, blocker, blocked int64) error
+	// Blocked blocker 有没有拉黑 blocked
+	Blocked(ctx context.Context, blocker, blocked int64) (bool, error)
+	GetBlockedList(ctx context.Context, blocker int64, offset, limit int) ([]domain.BlockRelation, error)
+}
+
+type CachedBlockRepository struct {
+	dao   dao.BlockDAO
+	cache cache.BlockCache
+	log   logger.LoggerV1
+}
+
+func NewBlockRepository(dao dao.BlockDAO, cache cache.BlockCache, l logger.LoggerV1) BlockRepository {
+	return &CachedBlockRepository{
+		dao:   dao,
+		cache: cache,
+		log:   l,
+	}
+}
+
+func (r *CachedBlockRepository) Block(ctx context.Context, blocker, blocked int64) error {
+	err := r.dao.Block(ctx, blocker, blocked)
+	if err != nil {
+		return err
+	}
+	return r.cache.Del(ctx, blocker)
+}
+
+func (r *CachedBlockRepository) Unblock(ctx context.Context, blocker, blocked int64) error {
+	err := r.dao.Unblock(ctx, blocker, blocked)
+	if err != nil {
+		return err
+	}
+	return r.cache.Del(ctx, blocker)
+}
+
+func (r *CachedBlockRepository) Blocked(ctx context.Context, blocker, blocked int64) (bool, error) {
+	res, err := r.cache.Blocked(ctx, blocker, blocked)
+	if err == nil {
+		return res, nil
+	}
+	// 缓存没有就把整个名单加载进来
+	ids, err := r.dao.BlockedIds(ctx, blocker)
+	if err != nil {
+		return false, err
+	}
+	err = r.cache.SetBlocked(ctx, blocker, ids)
+	if err != nil {
+		r.log.Error("回写拉黑名单缓存失败",
+			logger.Int64("blocker", blocker),
+			logger.Error(err))
+	}
+	for _, id := range ids {
+		if id == blocked {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *CachedBlockRepository) GetBlockedList(ctx context.Context, blocker int64, offset, limit int) ([]domain.BlockRelation, error) {
+	rs, err := r.dao.BlockedList(ctx, blocker, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.BlockRelation, 0, len(rs))
+	for _, src := range rs {
+		res = append(res, domain.BlockRelation{
+			Blocker: src.Blocker,
+			Blocked: src.Blocked,
+			Ctime:   time.UnixMilli(src.Utime),
+		})
+	}
+	return res, nil
+}
@@ -19,6 +19,12 @@ type ArticleCache interface {
 
 	GetPub(ctx context.Context, id int64) (domain.Article, error)
 	SetPub(ctx context.Context, art domain.Article) error
+	// GetPubFirstPage 作者主页上已发表文章的第一页
+	GetPubFirstPage(ctx context.Context, uid int64) ([]domain.Article, error)
+	SetPubFirstPage(ctx context.Context, uid int64, arts []domain.Article) error
+	DelPubFirstPage(ctx context.Context, uid int64) error
+	// Del 删除文章的缓存，包括草稿和线上版本
+	Del(ctx context.Context, ids ...int64) error
 }
 
 type RedisArticleCache struct {
@@ -96,10 +102,51 @@ func (r *RedisArticleCache) SetPub(ctx context.Context, art domain.Article) erro
 	return r.client.Set(ctx, r.pubKey(art.Id), val, time.Minute*10).Err()
 }
 
+func (r *RedisArticleCache) GetPubFirstPage(ctx context.Context, uid int64) ([]domain.Article, error) {
+	val, err := r.client.Get(ctx, r.pubFirstPageKey(uid)).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var res []domain.Article
+	err = json.Unmarshal(val, &res)
+	return res, err
+}
+
+func (r *RedisArticleCache) SetPubFirstPage(ctx context.Context, uid int64, arts []domain.Article) error {
+	// 列表只要摘要
+	for i := 0; i < len(arts); i++ {
+		arts[i].Content = arts[i].Abstract()
+	}
+	val, err := json.Marshal(arts)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, r.pubFirstPageKey(uid), val, time.Minute*10).Err()
+}
+
+func (r *RedisArticleCache) DelPubFirstPage(ctx context.Context, uid int64) error {
+	return r.client.Del(ctx, r.pubFirstPageKey(uid)).Err()
+}
+
+func (r *RedisArticleCache) Del(ctx context.Context, ids ...int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(ids)*2)
+	for _, id := range ids {
+		keys = append(keys, r.key(id), r.pubKey(id))
+	}
+	return r.client.Del(ctx, keys...).Err()
+}
+
 func (r *RedisArticleCache) firstPageKey(uid int64) string {
 	return fmt.Sprintf("article:first_page:%d", uid)
 }
 
+func (r *RedisArticleCache) pubFirstPageKey(uid int64) string {
+	return fmt.Sprintf("article:pub:first_page:%d", uid)
+}
+
 func (r *RedisArticleCache) key(uid int64) string {
 	return fmt.Sprintf("article:%d", uid)
 }
@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+// 空集合在 redis 里面是不存在的，塞一个占位的，没有 id 是 0 的用户
+const blockPlaceholder = 0
+
+type BlockCache interface {
+	// Blocked blocker 有没有拉黑 blocked，没有缓存返回 ErrKeyNotExist
+	Blocked(ctx context.Context, blocker, blocked int64) (bool, error)
+	SetBlocked(ctx context.Context, blocker int64, blockedIds []int64) error
+	Del(ctx context.Context, blocker int64) error
+}
+
+type RedisBlockCache struct {
+	client     redis.Cmdable
+	expiration time.Duration
+}
+
+func NewRedisBlockCache(client redis.Cmdable) BlockCache {
+	return &RedisBlockCache{
+		client:     client,
+		expiration: time.Minute * 15,
+	}
+}
+
+func (r *RedisBlockCache) Blocked(ctx context.Context, blocker, blocked int64) (bool, error) {
+	key := r.key(blocker)
+	var (
+		exists *redis.IntCmd
+		member *redis.BoolCmd
+	)
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		exists = pipe.Exists(ctx, key)
+		member = pipe.SIsMember(ctx, key, blocked)
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	if exists.Val() == 0 {
+		return false, ErrKeyNotExist
+	}
+	return member.Val(), nil
+}
+
+func (r *RedisBlockCache) SetBlocked(ctx context.Context, blocker int64, blockedIds []int64) error {
+	key := r.key(blocker)
+	members := make([]any, 0, len(blockedIds)+1)
+	members = append(members, blockPlaceholder)
+	for _, id := range blockedIds {
+		members = append(members, id)
+	}
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key)
+		pipe.SAdd(ctx, key, members...)
+		pipe.Expire(ctx, key, r.expiration)
+		return nil
+	})
+	return err
+}
+
+func (r *RedisBlockCache) Del(ctx context.Context, blocker int64) error {
+	return r.client.Del(ctx, r.key(blocker)).Err()
+}
+
+func (r *RedisBlockCache) key(blocker int64) string {
+	return fmt.Sprintf("block:blocked:%d", blocker)
+}
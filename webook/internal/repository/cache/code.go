@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+var (
+	ErrCodeSendTooMany        = errors.New("发送验证码太频繁")
+	ErrCodeVerifyTooManyTimes = errors.New("验证次数太多")
+	ErrUnknownForCode         = errors.New("我也不知发生什么了，反正是跟 code 有关")
+)
+
+var (
+	//go:embed set_code.lua
+	luaSetCode string
+	//go:embed verify_code.lua
+	luaVerifyCode string
+)
+
+type CodeCache interface {
+	Set(ctx context.Context, biz, phone, code string) error
+	Verify(ctx context.Context, biz, phone, inputCode string) (bool, error)
+}
+
+type RedisCodeCache struct {
+	client redis.Cmdable
+	// 验证码有效期
+	expiration time.Duration
+	// 两次发送之间至少间隔多久
+	interval time.Duration
+	// 一个验证码最多验证几次
+	maxVerifyCnt int
+}
+
+func NewRedisCodeCache(client redis.Cmdable) CodeCache {
+	return &RedisCodeCache{
+		client:       client,
+		expiration:   time.Minute * 10,
+		interval:     time.Minute,
+		maxVerifyCnt: 3,
+	}
+}
+
+func (c *RedisCodeCache) Set(ctx context.Context, biz, phone, code string) error {
+	res, err := c.client.Eval(ctx, luaSetCode, []string{c.key(biz, phone)}, code,
+		int(c.expiration.Seconds()), int(c.interval.Seconds()), c.maxVerifyCnt).Int()
+	if err != nil {
+		return err
+	}
+	switch res {
+	case 0:
+		return nil
+	case -1:
+		return ErrCodeSendTooMany
+	default:
+		return ErrUnknownForCode
+	}
+}
+
+func (c *RedisCodeCache) Verify(ctx context.Context, biz, phone, inputCode string) (bool, error) {
+	res, err := c.client.Eval(ctx, luaVerifyCode, []string{c.key(biz, phone)}, inputCode).Int()
+	if err != nil {
+		return false, err
+	}
+	switch res {
+	case 0:
+		return true, nil
+	case -1:
+		// 正常来说，如果频繁出现这个错误，就要告警，因为有人搞你
+		return false, ErrCodeVerifyTooManyTimes
+	case -2:
+		return false, nil
+	}
+	return false, ErrUnknownForCode
+}
+
+func (c *RedisCodeCache) key(biz, phone string) string {
+	return fmt.Sprintf("phone_code:%s:%s", biz, phone)
+}
@@ -0,0 +1,12 @@
+local key = KEYS[1]
+-- 令牌里面带的 id
+local expectedId = ARGV[1]
+
+local id = redis.call("get", key)
+if id == false or id ~= expectedId then
+    -- 过期了、已经用过了，或者已经签发了新的
+    return -1
+end
+-- 只能用一次
+redis.call("del", key)
+return 0
@@ -0,0 +1,91 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"time"
+	"webook/internal/domain"
+)
+
+const (
+	fieldFollowers = "followers"
+	fieldFollowees = "followees"
+)
+
+type FollowCache interface {
+	GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error)
+	SetStatics(ctx context.Context, uid int64, statics domain.FollowStatics) error
+	// Follow follower 的关注数和 followee 的粉丝数都加一，缓存里面有才加
+	Follow(ctx context.Context, follower, followee int64) error
+	CancelFollow(ctx context.Context, follower, followee int64) error
+	DelStatics(ctx context.Context, uids ...int64) error
+}
+
+type RedisFollowCache struct {
+	client redis.Cmdable
+}
+
+func NewRedisFollowCache(client redis.Cmdable) FollowCache {
+	return &RedisFollowCache{
+		client: client,
+	}
+}
+
+func (r *RedisFollowCache) GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error) {
+	res, err := r.client.HGetAll(ctx, r.staticsKey(uid)).Result()
+	if err != nil {
+		return domain.FollowStatics{}, err
+	}
+	if len(res) == 0 {
+		return domain.FollowStatics{}, ErrKeyNotExist
+	}
+	var statics domain.FollowStatics
+	statics.Followers, _ = strconv.ParseInt(res[fieldFollowers], 10, 64)
+	statics.Followees, _ = strconv.ParseInt(res[fieldFollowees], 10, 64)
+	return statics, nil
+}
+
+func (r *RedisFollowCache) SetStatics(ctx context.Context, uid int64, statics domain.FollowStatics) error {
+	key := r.staticsKey(uid)
+	err := r.client.HSet(ctx, key,
+		fieldFollowers, statics.Followers,
+		fieldFollowees, statics.Followees,
+	).Err()
+	if err != nil {
+		return err
+	}
+	return r.client.Expire(ctx, key, time.Minute*15).Err()
+}
+
+func (r *RedisFollowCache) Follow(ctx context.Context, follower, followee int64) error {
+	return r.updateStatics(ctx, follower, followee, 1)
+}
+
+func (r *RedisFollowCache) CancelFollow(ctx context.Context, follower, followee int64) error {
+	return r.updateStatics(ctx, follower, followee, -1)
+}
+
+func (r *RedisFollowCache) updateStatics(ctx context.Context, follower, followee int64, delta int) error {
+	err := r.client.Eval(ctx, luaIncrCnt, []string{r.staticsKey(follower)}, fieldFollowees, delta).Err()
+	if err != nil {
+		return err
+	}
+	return r.client.Eval(ctx, luaIncrCnt, []string{r.staticsKey(followee)}, fieldFollowers, delta).Err()
+}
+
+func (r *RedisFollowCache) DelStatics(ctx context.Context, uids ...int64) error {
+	if len(uids) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(uids))
+	for _, uid := range uids {
+		keys = append(keys, r.staticsKey(uid))
+	}
+	return r.client.Del(ctx, keys...).Err()
+}
+
+func (r *RedisFollowCache) staticsKey(uid int64) string {
+	return fmt.Sprintf("follow:statics:%d", uid)
+}
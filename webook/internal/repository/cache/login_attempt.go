@@ -0,0 +1,101 @@
+package cache
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"strconv"
+	"strings"
+	"time"
+	"webook/internal/domain"
+)
+
+//go:embed record_login_fail.lua
+var luaRecordLoginFail string
+
+// LoginAttemptCache 按账号和按 IP 分别记录登录失败的次数
+type LoginAttemptCache interface {
+	Get(ctx context.Context, email, ip string) (domain.LoginAttempts, error)
+	// RecordFailure 记一次失败，达到阈值的时候会顺便把账号锁上
+	RecordFailure(ctx context.Context, email, ip string) (domain.LoginAttempts, error)
+	// Reset 登录成功之后清掉账号的失败次数
+	Reset(ctx context.Context, email string) error
+	Unlock(ctx context.Context, email string) error
+}
+
+type RedisLoginAttemptCache struct {
+	client redis.Cmdable
+	// 失败次数的统计窗口
+	window time.Duration
+	// 账号连续失败多少次就锁定
+	lockThreshold int
+	lockDuration  time.Duration
+}
+
+func NewRedisLoginAttemptCache(client redis.Cmdable) LoginAttemptCache {
+	return &RedisLoginAttemptCache{
+		client:        client,
+		window:        time.Minute * 15,
+		lockThreshold: 10,
+		lockDuration:  time.Minute * 30,
+	}
+}
+
+func (c *RedisLoginAttemptCache) Get(ctx context.Context, email, ip string) (domain.LoginAttempts, error) {
+	pipe := c.client.Pipeline()
+	account := pipe.HGetAll(ctx, c.accountKey(email))
+	ipCnt := pipe.Get(ctx, c.ipKey(ip))
+	locked := pipe.Exists(ctx, c.lockKey(email))
+	_, err := pipe.Exec(ctx)
+	// 没有失败过的时候 GET 会返回 redis.Nil
+	if err != nil && err != redis.Nil {
+		return domain.LoginAttempts{}, err
+	}
+	var res domain.LoginAttempts
+	vals := account.Val()
+	res.AccountFails, _ = strconv.Atoi(vals["cnt"])
+	if last, err := strconv.ParseInt(vals["last"], 10, 64); err == nil {
+		res.LastFail = time.UnixMilli(last)
+	}
+	res.IPFails, _ = strconv.Atoi(ipCnt.Val())
+	res.Locked = locked.Val() > 0
+	return res, nil
+}
+
+func (c *RedisLoginAttemptCache) RecordFailure(ctx context.Context, email, ip string) (domain.LoginAttempts, error) {
+	now := time.Now()
+	vals, err := c.client.Eval(ctx, luaRecordLoginFail,
+		[]string{c.accountKey(email), c.ipKey(ip), c.lockKey(email)},
+		now.UnixMilli(), int(c.window.Seconds()), c.lockThreshold, int(c.lockDuration.Seconds())).
+		Int64Slice()
+	if err != nil {
+		return domain.LoginAttempts{}, err
+	}
+	return domain.LoginAttempts{
+		AccountFails: int(vals[0]),
+		LastFail:     now,
+		IPFails:      int(vals[1]),
+		Locked:       vals[2] == 1,
+	}, nil
+}
+
+func (c *RedisLoginAttemptCache) Reset(ctx context.Context, email string) error {
+	return c.client.Del(ctx, c.accountKey(email)).Err()
+}
+
+func (c *RedisLoginAttemptCache) Unlock(ctx context.Context, email string) error {
+	return c.client.Del(ctx, c.accountKey(email), c.lockKey(email)).Err()
+}
+
+func (c *RedisLoginAttemptCache) accountKey(email string) string {
+	return fmt.Sprintf("users:login:fail:account:%s", strings.ToLower(email))
+}
+
+func (c *RedisLoginAttemptCache) ipKey(ip string) string {
+	return fmt.Sprintf("users:login:fail:ip:%s", ip)
+}
+
+func (c *RedisLoginAttemptCache) lockKey(email string) string {
+	return fmt.Sprintf("users:login:lock:%s", strings.ToLower(email))
+}
@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+//go:embed use_totp_step.lua
+var luaUseTOTPStep string
+
+// MFACache 两步验证登录的防爆破、防重放
+type MFACache interface {
+	// Failures 统计窗口内第二步输错了几次
+	Failures(ctx context.Context, uid int64) (int, error)
+	// RecordFailure 记一次失败，返回窗口内一共失败了几次
+	RecordFailure(ctx context.Context, uid int64) (int, error)
+	ResetFailures(ctx context.Context, uid int64) error
+	// UseStep 记下这次通过的 TOTP 时间步，不比上一次大的返回 false
+	UseStep(ctx context.Context, uid int64, step int64) (bool, error)
+}
+
+type RedisMFACache struct {
+	client redis.Cmdable
+	// 失败次数的统计窗口，最后一次失败之后这么久没再错就清零
+	window time.Duration
+	// 验证码前后偏一个周期都认，记住的时间步要比这个活得久
+	stepExpiration time.Duration
+}
+
+func NewRedisMFACache(client redis.Cmdable) MFACache {
+	return &RedisMFACache{
+		client:         client,
+		window:         time.Minute * 15,
+		stepExpiration: time.Minute * 2,
+	}
+}
+
+func (c *RedisMFACache) Failures(ctx context.Context, uid int64) (int, error) {
+	cnt, err := c.client.Get(ctx, c.failKey(uid)).Int()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return cnt, err
+}
+
+func (c *RedisMFACache) RecordFailure(ctx context.Context, uid int64) (int, error) {
+	var incr *redis.IntCmd
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, c.failKey(uid))
+		pipe.Expire(ctx, c.failKey(uid), c.window)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(incr.Val()), nil
+}
+
+func (c *RedisMFACache) ResetFailures(ctx context.Context, uid int64) error {
+	return c.client.Del(ctx, c.failKey(uid)).Err()
+}
+
+func (c *RedisMFACache) UseStep(ctx context.Context, uid int64, step int64) (bool, error) {
+	res, err := c.client.Eval(ctx, luaUseTOTPStep, []string{c.stepKey(uid)},
+		step, int(c.stepExpiration.Seconds())).Int()
+	if err != nil {
+		return false, err
+	}
+	return res == 0, nil
+}
+
+func (c *RedisMFACache) failKey(uid int64) string {
+	return fmt.Sprintf("users:mfa:fail:%d", uid)
+}
+
+func (c *RedisMFACache) stepKey(uid int64) string {
+	return fmt.Sprintf("users:mfa:step:%d", uid)
+}
@@ -0,0 +1,29 @@
+local accountKey = KEYS[1]
+local ipKey = KEYS[2]
+local lockKey = KEYS[3]
+local now = ARGV[1]
+-- 统计窗口，秒
+local window = tonumber(ARGV[2])
+local lockThreshold = tonumber(ARGV[3])
+-- 锁定多久，秒
+local lockDuration = tonumber(ARGV[4])
+
+local cnt = redis.call("hincrby", accountKey, "cnt", 1)
+redis.call("hset", accountKey, "last", now)
+if cnt == 1 then
+    redis.call("expire", accountKey, window)
+end
+
+local ipCnt = redis.call("incr", ipKey)
+if ipCnt == 1 then
+    redis.call("expire", ipKey, window)
+end
+
+local locked = 0
+if cnt >= lockThreshold then
+    redis.call("set", lockKey, "1", "EX", lockDuration)
+    -- 解锁之后重新开始计数
+    redis.call("del", accountKey)
+    locked = 1
+end
+return {cnt, ipCnt, locked}
@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"time"
+	"webook/internal/domain"
+)
+
+type SeriesCache interface {
+	Get(ctx context.Context, sid int64) (domain.Series, error)
+	Set(ctx context.Context, s domain.Series) error
+	Del(ctx context.Context, sid int64) error
+	// GetSid 文章所在的系列，0 表示不在任何系列里面
+	GetSid(ctx context.Context, aid int64) (int64, error)
+	SetSid(ctx context.Context, aid int64, sid int64) error
+	DelSid(ctx context.Context, aids ...int64) error
+}
+
+type RedisSeriesCache struct {
+	client redis.Cmdable
+	// 系列里面缓存了文章标题，文章发表、撤回的时候会主动删，这里只是兜底
+	expiration time.Duration
+}
+
+func NewRedisSeriesCache(client redis.Cmdable) SeriesCache {
+	return &RedisSeriesCache{
+		client:     client,
+		expiration: time.Minute * 5,
+	}
+}
+
+func (r *RedisSeriesCache) Get(ctx context.Context, sid int64) (domain.Series, error) {
+	val, err := r.client.Get(ctx, r.key(sid)).Bytes()
+	if err != nil {
+		return domain.Series{}, err
+	}
+	var s domain.Series
+	err = json.Unmarshal(val, &s)
+	return s, err
+}
+
+func (r *RedisSeriesCache) Set(ctx context.Context, s domain.Series) error {
+	val, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, r.key(s.Id), val, r.expiration).Err()
+}
+
+func (r *RedisSeriesCache) Del(ctx context.Context, sid int64) error {
+	return r.client.Del(ctx, r.key(sid)).Err()
+}
+
+func (r *RedisSeriesCache) GetSid(ctx context.Context, aid int64) (int64, error) {
+	return r.client.Get(ctx, r.sidKey(aid)).Int64()
+}
+
+// SetSid 不在系列里面的文章也要缓存一个 0，不然每次看文章都要查数据库
+func (r *RedisSeriesCache) SetSid(ctx context.Context, aid int64, sid int64) error {
+	return r.client.Set(ctx, r.sidKey(aid), sid, r.expiration).Err()
+}
+
+func (r *RedisSeriesCache) DelSid(ctx context.Context, aids ...int64) error {
+	if len(aids) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(aids))
+	for _, aid := range aids {
+		keys = append(keys, r.sidKey(aid))
+	}
+	return r.client.Del(ctx, keys...).Err()
+}
+
+func (r *RedisSeriesCache) key(sid int64) string {
+	return fmt.Sprintf("series:info:%d", sid)
+}
+
+func (r *RedisSeriesCache) sidKey(aid int64) string {
+	return fmt.Sprintf("series:article:%d", aid)
+}
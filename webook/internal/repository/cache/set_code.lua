@@ -0,0 +1,27 @@
+-- 验证码的 key，phone_code:login:152xxxxxxxx
+local key = KEYS[1]
+-- 还能验证几次
+local cntKey = key..":cnt"
+local val = ARGV[1]
+-- 过期时间，秒
+local expiration = tonumber(ARGV[2])
+-- 发送间隔，秒
+local interval = tonumber(ARGV[3])
+-- 最多验证几次
+local maxCnt = tonumber(ARGV[4])
+
+local ttl = tonumber(redis.call("ttl", key))
+if ttl == -1 then
+    -- key 存在，但是没有过期时间，肯定是有人手动设置了
+    return -2
+elseif ttl == -2 or ttl < expiration - interval then
+    -- 没有发过，或者已经过了发送间隔
+    redis.call("set", key, val)
+    redis.call("expire", key, expiration)
+    redis.call("set", cntKey, maxCnt)
+    redis.call("expire", cntKey, expiration)
+    return 0
+else
+    -- 发送太频繁
+    return -1
+end
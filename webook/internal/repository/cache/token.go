@@ -0,0 +1,69 @@
+package cache
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+var ErrTokenInvalid = errors.New("令牌无效或者已经用过了")
+
+//go:embed consume_token.lua
+var luaConsumeToken string
+
+// TokenCache 记录每个用户在某个业务下当前有效的一次性令牌，
+// 比如重置密码、验证邮箱的链接，保证令牌只能用一次
+type TokenCache interface {
+	Set(ctx context.Context, biz string, uid int64, tokenId string, expiration time.Duration) error
+	// Check 只校验令牌，不作废
+	Check(ctx context.Context, biz string, uid int64, tokenId string) error
+	// Consume 校验令牌并且作废它
+	Consume(ctx context.Context, biz string, uid int64, tokenId string) error
+}
+
+type RedisTokenCache struct {
+	client redis.Cmdable
+}
+
+func NewRedisTokenCache(client redis.Cmdable) TokenCache {
+	return &RedisTokenCache{
+		client: client,
+	}
+}
+
+func (c *RedisTokenCache) Set(ctx context.Context, biz string, uid int64, tokenId string, expiration time.Duration) error {
+	// 重新申请会覆盖掉之前的，旧的链接就失效了
+	return c.client.Set(ctx, c.key(biz, uid), tokenId, expiration).Err()
+}
+
+func (c *RedisTokenCache) Check(ctx context.Context, biz string, uid int64, tokenId string) error {
+	id, err := c.client.Get(ctx, c.key(biz, uid)).Result()
+	if err == redis.Nil {
+		return ErrTokenInvalid
+	}
+	if err != nil {
+		return err
+	}
+	if id != tokenId {
+		return ErrTokenInvalid
+	}
+	return nil
+}
+
+func (c *RedisTokenCache) Consume(ctx context.Context, biz string, uid int64, tokenId string) error {
+	res, err := c.client.Eval(ctx, luaConsumeToken, []string{c.key(biz, uid)}, tokenId).Int()
+	if err != nil {
+		return err
+	}
+	if res != 0 {
+		return ErrTokenInvalid
+	}
+	return nil
+}
+
+func (c *RedisTokenCache) key(biz string, uid int64) string {
+	return fmt.Sprintf("users:token:%s:%d", biz, uid)
+}
@@ -0,0 +1,12 @@
+local key = KEYS[1]
+-- 这一次验证码对应的时间步
+local step = tonumber(ARGV[1])
+local expiration = tonumber(ARGV[2])
+
+local last = redis.call("get", key)
+if last ~= false and tonumber(last) >= step then
+    -- 同一个验证码用第二次，或者拿了更早的验证码
+    return -1
+end
+redis.call("set", key, step, "EX", expiration)
+return 0
@@ -12,6 +12,7 @@ import (
 type UserCache interface {
 	Get(ctx context.Context, id int64) (domain.User, error)
 	Set(ctx context.Context, u domain.User) error
+	Del(ctx context.Context, id int64) error
 }
 type RedisUserCache struct {
 	//传 单机 redis
@@ -51,6 +52,10 @@ func (uc *RedisUserCache) Set(ctx context.Context, u domain.User) error {
 	return uc.client.Set(ctx, key, val, uc.expiration).Err()
 }
 
+func (uc *RedisUserCache) Del(ctx context.Context, id int64) error {
+	return uc.client.Del(ctx, uc.key(id)).Err()
+}
+
 func (uc *RedisUserCache) key(id int64) string {
 	return fmt.Sprintf("id:info:%d", id)
 }
@@ -0,0 +1,21 @@
+local key = KEYS[1]
+local cntKey = key..":cnt"
+-- 用户输入的验证码
+local expectedCode = ARGV[1]
+
+local cnt = tonumber(redis.call("get", cntKey))
+local code = redis.call("get", key)
+
+if cnt == nil or cnt <= 0 then
+    -- 验证次数耗尽了，或者根本没发过
+    return -1
+end
+
+if code == expectedCode then
+    -- 验证码只能用一次
+    redis.call("set", cntKey, 0)
+    return 0
+else
+    redis.call("decr", cntKey)
+    return -2
+end
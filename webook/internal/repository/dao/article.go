@@ -5,6 +5,7 @@ import (
 	"errors"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"strings"
 	"time"
 )
 
@@ -13,27 +14,69 @@ type ArticleDAO interface {
 	UpdateById(ctx context.Context, art Article) error
 	Sync(ctx context.Context, art Article) (int64, error)
 	SyncStatus(ctx context.Context, uid int64, aid int64, stat uint8) error
-	GetByAuthor(ctx context.Context, uid int64, offset int, limit int) ([]Article, error)
+	// GetByAuthor 游标翻页，status 是 0 或者 keyword 为空就是不过滤
+	GetByAuthor(ctx context.Context, uid int64, status uint8, keyword string,
+		cursorTime, cursorAid int64, limit int) ([]Article, error)
 	GetById(ctx context.Context, id int64) (Article, error)
 	GetPubById(ctx context.Context, id int64) (PublishedArticle, error)
+	GetPubByAuthor(ctx context.Context, uid int64, status uint8, offset int, limit int) ([]PublishedArticle, error)
+	CountPubByAuthor(ctx context.Context, uid int64, status uint8) (int64, error)
+	// ListPub 按照第一次发表的时间游标翻页，取 (ctime, id) 比游标小的，uid 是读者，会过滤掉他拉黑的作者，没登录传 0
+	ListPub(ctx context.Context, uid int64, status uint8, cursorTime, cursorAid int64, limit int) ([]PublishedArticle, error)
+
+	// GetRevisions 不带内容，按照版本号倒序
+	GetRevisions(ctx context.Context, aid int64, uid int64, offset int, limit int) ([]ArticleRevision, error)
+	GetRevision(ctx context.Context, aid int64, uid int64, version int64) (ArticleRevision, error)
+	// PruneRevisions 只保留最新的 keep 个版本
+	PruneRevisions(ctx context.Context, aid int64, keep int) error
+
+	// GetScheduledByAuthor 作者定时发表的文章，按照发表时间顺序
+	GetScheduledByAuthor(ctx context.Context, uid int64, status uint8, offset int, limit int) ([]Article, error)
+	// GetDueScheduled 发表时间不晚于 now 的定时文章，所有作者的，
+	// 按照发表时间正序排在 (cursorTime, cursorAid) 后面的
+	GetDueScheduled(ctx context.Context, status uint8, now int64,
+		cursorTime, cursorAid int64, limit int) ([]Article, error)
+	// CancelSchedule 状态是 from 的才会改成 to，不是定时发表的返回 ErrRecordNotFound
+	CancelSchedule(ctx context.Context, uid int64, aid int64, from uint8, to uint8) error
+
+	// GetPubByTag 带这个标签的线上文章，按照发表时间倒序
+	// uid 是读者，会过滤掉他拉黑的作者，没登录传 0
+	GetPubByTag(ctx context.Context, uid int64, tag string, status uint8, offset int, limit int) ([]PublishedArticle, error)
+	CountPubByTag(ctx context.Context, uid int64, tag string, status uint8) (int64, error)
 	//Transaction(ctx context.Context, bizFunc func(txDAO ArticleDAO) error) error
 	//Upsert(ctx context.Context, article PublishedArticle) error
 }
 
 type Article struct {
-	Id      int64  `gorm:"primary_key,autoIncrement"`
+	Id      int64  `gorm:"primary_key,autoIncrement;index:status_ctime,priority:3"`
 	Title   string `gorm:"type=varchar(1024)"`
 	Content string `gorm:"type=BLOB"`
 
-	//SLECT * FROM articles WHERE author_id = 1 ORDER BY `ctime`
+	//SLECT * FROM articles WHERE author_id = 1 ORDER BY `utime` DESC, `id` DESC
 	//SLECT * FROM articles WHERE id = 1
-	AuthorId int64 `gorm:"index"`
+	AuthorId int64 `gorm:"index:author_utime,priority:1"`
 	//AuthorId int64 `gorm:"index=aid_ctime"`
 	//Ctime    int64
-	Ctime  int64
-	Utime  int64
-	Status uint8
+	// 线上库的 ctime 是第一次发表的时间，重新发表、撤回都不会改
+	// 线上库按照发表时间翻页
+	// SELECT * FROM published_articles WHERE status = ? AND (ctime, id) < (?, ?) ORDER BY ctime DESC, id DESC
+	Ctime  int64 `gorm:"index:status_ctime,priority:2"`
+	Utime  int64 `gorm:"index:author_utime,priority:2"`
+	Status uint8 `gorm:"index:status_ctime,priority:1;index:status_publish_at,priority:1"`
+	// 定时发表的时间，毫秒数，0 表示没有定时
+	// SELECT * FROM articles WHERE status = ? AND publish_at <= ? ORDER BY publish_at
+	PublishAt int64 `gorm:"index:status_publish_at,priority:2"`
+	// 乐观锁，每次更新加一，更新的时候要带上编辑时候拿到的版本号
+	Version int64
+
+	// 标签和分类存在关联表里面，只有查单篇文章的时候才会填上
+	Tags       []string `gorm:"-"`
+	CategoryId int64    `gorm:"-"`
 }
+
+// ErrArticleVersionConflict 文章在别的地方被改过了
+var ErrArticleVersionConflict = errors.New("article version conflict")
+
 type GORMArticleDAO struct {
 	db *gorm.DB
 }
@@ -48,7 +91,19 @@ func (dao *GORMArticleDAO) Insert(ctx context.Context, art Article) (int64, erro
 	now := time.Now().UnixMilli()
 	art.Ctime = now
 	art.Utime = now
-	err := dao.db.WithContext(ctx).Create(&art).Error
+	art.Version = 1
+	// 每次保存都留一个版本
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(&art).Error
+		if err != nil {
+			return err
+		}
+		err = saveMeta[ArticleTag, ArticleCategory](tx, art, now)
+		if err != nil {
+			return err
+		}
+		return dao.addRevision(tx, art)
+	})
 	return art.Id, err
 }
 
@@ -56,24 +111,44 @@ func (dao *GORMArticleDAO) UpdateById(ctx context.Context, art Article) error {
 	now := time.Now().UnixMilli()
 	//art.Ctime = now
 	art.Utime = now
-	res := dao.db.WithContext(ctx).Model(&art).Where("id=? AND author_id=?", art.Id, art.AuthorId).
-		Updates(map[string]any{
-			"status":  art.Status,
-			"title":   art.Title,
-			"content": art.Content,
-			"utime":   art.Utime,
-		})
-
-	err := res.Error
-	if err != nil {
-		return err
-	}
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// compare-and-set，版本号对不上就说明别人先改了
+		res := tx.Model(&Article{}).
+			Where("id=? AND author_id=? AND version=?", art.Id, art.AuthorId, art.Version).
+			Updates(map[string]any{
+				"status":     art.Status,
+				"title":      art.Title,
+				"content":    art.Content,
+				"utime":      art.Utime,
+				"publish_at": art.PublishAt,
+				"version":    gorm.Expr("`version` + 1"),
+			})
 
-	if res.RowsAffected == 0 {
-		return errors.New("更新数据失败")
-	}
+		err := res.Error
+		if err != nil {
+			return err
+		}
 
-	return nil
+		if res.RowsAffected == 0 {
+			var cnt int64
+			err = tx.Model(&Article{}).
+				Where("id=? AND author_id=?", art.Id, art.AuthorId).
+				Count(&cnt).Error
+			if err != nil {
+				return err
+			}
+			if cnt > 0 {
+				return ErrArticleVersionConflict
+			}
+			return errors.New("更新数据失败")
+		}
+		err = saveMeta[ArticleTag, ArticleCategory](tx, art, now)
+		if err != nil {
+			return err
+		}
+		// 上面的更新已经锁住了文章这一行，版本号不会重复
+		return dao.addRevision(tx, art)
+	})
 }
 
 func (dao *GORMArticleDAO) Sync(ctx context.Context, art Article) (int64, error) {
@@ -85,8 +160,10 @@ func (dao *GORMArticleDAO) Sync(ctx context.Context, art Article) (int64, error)
 		dao := NewGORMArticleDAO(tx)
 		if id > 0 {
 			err = dao.UpdateById(ctx, art)
+			art.Version++
 		} else {
 			id, err = dao.Insert(ctx, art)
+			art.Version = 1
 		}
 		if err != nil {
 			return err
@@ -107,9 +184,13 @@ func (dao *GORMArticleDAO) Sync(ctx context.Context, art Article) (int64, error)
 				"content": pubArt.Content,
 				"utime":   now,
 				"status":  pubArt.Status,
+				"version": pubArt.Version,
 			}),
 		}).Create(&pubArt).Error
-		return err
+		if err != nil {
+			return err
+		}
+		return saveMeta[PublishedArticleTag, PublishedArticleCategory](tx, art, now)
 	})
 	return id, err
 }
@@ -117,11 +198,13 @@ func (dao *GORMArticleDAO) Sync(ctx context.Context, art Article) (int64, error)
 func (dao *GORMArticleDAO) SyncStatus(ctx context.Context, uid int64, aid int64, stat uint8) error {
 	now := time.Now().UnixMilli()
 	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 状态变了也算修改，定时发表的时候拿着旧版本号就发不出去了
 		res := tx.Model(&Article{}).
-			Where("id = ? and author_id = ?", uid, aid).
+			Where("id = ? AND author_id = ?", aid, uid).
 			Updates(map[string]any{
-				"utime":  now,
-				"status": stat,
+				"utime":   now,
+				"status":  stat,
+				"version": gorm.Expr("`version` + 1"),
 			})
 		if res.Error != nil {
 			return res.Error
@@ -130,7 +213,7 @@ func (dao *GORMArticleDAO) SyncStatus(ctx context.Context, uid int64, aid int64,
 			return errors.New("ID 不对或者创作者不对")
 		}
 		return tx.Model(&PublishedArticle{}).
-			Where("id = ?", uid).
+			Where("id = ?", aid).
 			Updates(map[string]any{
 				"utime":  now,
 				"status": stat,
@@ -138,22 +221,39 @@ func (dao *GORMArticleDAO) SyncStatus(ctx context.Context, uid int64, aid int64,
 	})
 }
 
-func (dao *GORMArticleDAO) GetByAuthor(ctx context.Context, uid int64, offset int, limit int) ([]Article, error) {
-	var arts []Article
-	err := dao.db.WithContext(ctx).
+func (dao *GORMArticleDAO) GetByAuthor(ctx context.Context, uid int64, status uint8, keyword string,
+	cursorTime, cursorAid int64, limit int) ([]Article, error) {
+	query := dao.db.WithContext(ctx).
 		Where("author_id = ?", uid).
-		Offset(offset).Limit(limit).
+		Where("utime < ? OR (utime = ? AND id < ?)", cursorTime, cursorTime, cursorAid)
+	if status != 0 {
+		query = query.Where("status = ?", status)
+	}
+	if keyword != "" {
+		query = query.Where("title LIKE ?", "%"+likeEscaper.Replace(keyword)+"%")
+	}
+	var arts []Article
+	err := query.
 		// a ASC, B DESC
-		Order("utime DESC").
+		Order("utime DESC, id DESC").
+		Limit(limit).
 		Find(&arts).Error
 	return arts, err
 }
 
+// likeEscaper 关键字里面的通配符要当成普通字符
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
 func (dao *GORMArticleDAO) GetById(ctx context.Context, id int64) (Article, error) {
 	var art Article
 	err := dao.db.WithContext(ctx).
 		Where("id = ?", id).First(&art).Error
-	return art, err
+	if err != nil {
+		return art, err
+	}
+	arts := []Article{art}
+	err = loadMeta[ArticleTag, ArticleCategory](dao.db.WithContext(ctx), arts)
+	return arts[0], err
 }
 
 func (dao *GORMArticleDAO) GetPubById(ctx context.Context, id int64) (PublishedArticle, error) {
@@ -161,6 +261,44 @@ func (dao *GORMArticleDAO) GetPubById(ctx context.Context, id int64) (PublishedA
 	err := dao.db.WithContext(ctx).
 		Where("id = ?", id).
 		First(&res).Error
+	if err != nil {
+		return res, err
+	}
+	arts := []Article{Article(res)}
+	err = loadMeta[PublishedArticleTag, PublishedArticleCategory](dao.db.WithContext(ctx), arts)
+	return PublishedArticle(arts[0]), err
+}
+
+func (dao *GORMArticleDAO) GetPubByAuthor(ctx context.Context, uid int64, status uint8,
+	offset int, limit int) ([]PublishedArticle, error) {
+	var res []PublishedArticle
+	err := dao.db.WithContext(ctx).
+		Where("author_id = ? AND status = ?", uid, status).
+		Order("utime DESC, id DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMArticleDAO) CountPubByAuthor(ctx context.Context, uid int64, status uint8) (int64, error) {
+	var cnt int64
+	err := dao.db.WithContext(ctx).Model(&PublishedArticle{}).
+		Where("author_id = ? AND status = ?", uid, status).
+		Count(&cnt).Error
+	return cnt, err
+}
+
+func (dao *GORMArticleDAO) ListPub(ctx context.Context, uid int64, status uint8,
+	cursorTime, cursorAid int64, limit int) ([]PublishedArticle, error) {
+	var res []PublishedArticle
+	db := dao.db.WithContext(ctx)
+	err := excludeBlocked(db, uid).
+		Where("status = ?", status).
+		// utime 每次重新发表都会变，老文章改一下就跑到最前面了
+		Where("ctime < ? OR (ctime = ? AND id < ?)", cursorTime, cursorTime, cursorAid).
+		Order("ctime DESC, id DESC").
+		Limit(limit).
+		Find(&res).Error
 	return res, err
 }
 
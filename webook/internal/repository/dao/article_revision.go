@@ -0,0 +1,76 @@
+package dao
+
+import (
+	"context"
+	"gorm.io/gorm"
+)
+
+func (dao *GORMArticleDAO) addRevision(tx *gorm.DB, art Article) error {
+	var version int64
+	err := tx.Model(&ArticleRevision{}).
+		Select("COALESCE(MAX(version), 0)").
+		Where("aid = ?", art.Id).
+		Scan(&version).Error
+	if err != nil {
+		return err
+	}
+	return tx.Create(&ArticleRevision{
+		Aid:      art.Id,
+		AuthorId: art.AuthorId,
+		Version:  version + 1,
+		Title:    art.Title,
+		Content:  art.Content,
+		Status:   art.Status,
+		Ctime:    art.Utime,
+	}).Error
+}
+
+func (dao *GORMArticleDAO) GetRevisions(ctx context.Context, aid int64, uid int64,
+	offset int, limit int) ([]ArticleRevision, error) {
+	var res []ArticleRevision
+	// 列表不需要内容
+	err := dao.db.WithContext(ctx).
+		Omit("content").
+		Where("aid = ? AND author_id = ?", aid, uid).
+		Order("version DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMArticleDAO) GetRevision(ctx context.Context, aid int64, uid int64, version int64) (ArticleRevision, error) {
+	var res ArticleRevision
+	err := dao.db.WithContext(ctx).
+		Where("aid = ? AND author_id = ? AND version = ?", aid, uid, version).
+		First(&res).Error
+	return res, err
+}
+
+func (dao *GORMArticleDAO) PruneRevisions(ctx context.Context, aid int64, keep int) error {
+	db := dao.db.WithContext(ctx)
+	var latest int64
+	err := db.Model(&ArticleRevision{}).
+		Select("COALESCE(MAX(version), 0)").
+		Where("aid = ?", aid).
+		Scan(&latest).Error
+	if err != nil {
+		return err
+	}
+	if latest <= int64(keep) {
+		return nil
+	}
+	return db.Where("aid = ? AND version <= ?", aid, latest-int64(keep)).
+		Delete(&ArticleRevision{}).Error
+}
+
+// ArticleRevision 每次保存或者发表的时候文章的快照，写进去之后就不会再改
+type ArticleRevision struct {
+	Id       int64 `gorm:"primaryKey,autoIncrement"`
+	Aid      int64 `gorm:"uniqueIndex:aid_version"`
+	Version  int64 `gorm:"uniqueIndex:aid_version"`
+	AuthorId int64
+	Title    string `gorm:"type:varchar(1024)"`
+	Content  string
+	Status   uint8
+	Ctime    int64
+}
@@ -0,0 +1,54 @@
+package dao
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"time"
+)
+
+func (dao *GORMArticleDAO) GetScheduledByAuthor(ctx context.Context, uid int64, status uint8,
+	offset int, limit int) ([]Article, error) {
+	var res []Article
+	err := dao.db.WithContext(ctx).
+		Where("author_id = ? AND status = ?", uid, status).
+		Order("publish_at ASC, id ASC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMArticleDAO) GetDueScheduled(ctx context.Context, status uint8, now int64,
+	cursorTime, cursorAid int64, limit int) ([]Article, error) {
+	var res []Article
+	err := dao.db.WithContext(ctx).
+		Where("status = ? AND publish_at <= ?", status, now).
+		Where("publish_at > ? OR (publish_at = ? AND id > ?)", cursorTime, cursorTime, cursorAid).
+		Order("publish_at ASC, id ASC").
+		Limit(limit).
+		Find(&res).Error
+	if err != nil {
+		return nil, err
+	}
+	// 发表的时候会用这里的标签和分类覆盖线上库
+	err = loadMeta[ArticleTag, ArticleCategory](dao.db.WithContext(ctx), res)
+	return res, err
+}
+
+func (dao *GORMArticleDAO) CancelSchedule(ctx context.Context, uid int64, aid int64, from uint8, to uint8) error {
+	// 版本号加一，已经查出来准备发表的那边就会冲突
+	res := dao.db.WithContext(ctx).Model(&Article{}).
+		Where("id = ? AND author_id = ? AND status = ?", aid, uid, from).
+		Updates(map[string]any{
+			"status":     to,
+			"publish_at": 0,
+			"utime":      time.Now().UnixMilli(),
+			"version":    gorm.Expr("`version` + 1"),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package dao
+
+import (
+	"context"
+	"gorm.io/gorm"
+)
+
+// ArticleTag 文章和标签的关联，标签已经归一化过了
+type ArticleTag struct {
+	Id  int64  `gorm:"primaryKey,autoIncrement"`
+	Aid int64  `gorm:"uniqueIndex:aid_tag"`
+	Tag string `gorm:"type:varchar(128);uniqueIndex:aid_tag;index"`
+
+	Ctime int64
+}
+
+// PublishedArticleTag 线上库的标签，按照标签找文章走这张表
+type PublishedArticleTag ArticleTag
+
+// ArticleCategory 文章所属的分类，一篇文章最多一个分类
+type ArticleCategory struct {
+	Id         int64 `gorm:"primaryKey,autoIncrement"`
+	Aid        int64 `gorm:"uniqueIndex"`
+	CategoryId int64 `gorm:"index"`
+
+	Ctime int64
+}
+
+type PublishedArticleCategory ArticleCategory
+
+// saveMeta 用 art 里面的标签和分类覆盖掉原来的
+func saveMeta[T ArticleTag | PublishedArticleTag, C ArticleCategory | PublishedArticleCategory](
+	tx *gorm.DB, art Article, now int64) error {
+	var tag T
+	err := tx.Where("aid = ?", art.Id).Delete(&tag).Error
+	if err != nil {
+		return err
+	}
+	if len(art.Tags) > 0 {
+		tags := make([]T, 0, len(art.Tags))
+		for _, t := range art.Tags {
+			tags = append(tags, T(ArticleTag{Aid: art.Id, Tag: t, Ctime: now}))
+		}
+		err = tx.Create(&tags).Error
+		if err != nil {
+			return err
+		}
+	}
+	var category C
+	err = tx.Where("aid = ?", art.Id).Delete(&category).Error
+	if err != nil || art.CategoryId == 0 {
+		return err
+	}
+	category = C(ArticleCategory{Aid: art.Id, CategoryId: art.CategoryId, Ctime: now})
+	return tx.Create(&category).Error
+}
+
+// loadMeta 批量把标签和分类填到 arts 里面
+func loadMeta[T ArticleTag | PublishedArticleTag, C ArticleCategory | PublishedArticleCategory](
+	tx *gorm.DB, arts []Article) error {
+	if len(arts) == 0 {
+		return nil
+	}
+	ids := make([]int64, 0, len(arts))
+	for _, art := range arts {
+		ids = append(ids, art.Id)
+	}
+	var tags []T
+	err := tx.Where("aid IN ?", ids).Order("id ASC").Find(&tags).Error
+	if err != nil {
+		return err
+	}
+	var categories []C
+	err = tx.Where("aid IN ?", ids).Find(&categories).Error
+	if err != nil {
+		return err
+	}
+	tagMap := make(map[int64][]string, len(arts))
+	for _, t := range tags {
+		tag := ArticleTag(t)
+		tagMap[tag.Aid] = append(tagMap[tag.Aid], tag.Tag)
+	}
+	categoryMap := make(map[int64]int64, len(categories))
+	for _, c := range categories {
+		category := ArticleCategory(c)
+		categoryMap[category.Aid] = category.CategoryId
+	}
+	for i := range arts {
+		arts[i].Tags = tagMap[arts[i].Id]
+		arts[i].CategoryId = categoryMap[arts[i].Id]
+	}
+	return nil
+}
+
+// deleteMeta 删掉这些文章在两个库里面的标签和分类
+func deleteMeta(tx *gorm.DB, aids []int64) error {
+	if len(aids) == 0 {
+		return nil
+	}
+	for _, model := range []any{&ArticleTag{}, &PublishedArticleTag{},
+		&ArticleCategory{}, &PublishedArticleCategory{}} {
+		err := tx.Where("aid IN ?", aids).Delete(model).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (dao *GORMArticleDAO) GetPubByTag(ctx context.Context, uid int64, tag string, status uint8,
+	offset int, limit int) ([]PublishedArticle, error) {
+	var res []PublishedArticle
+	db := dao.db.WithContext(ctx)
+	err := excludeBlocked(db, uid).
+		Joins("JOIN published_article_tags ON published_article_tags.aid = published_articles.id").
+		Where("published_article_tags.tag = ? AND published_articles.status = ?", tag, status).
+		Order("published_articles.utime DESC, published_articles.id DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMArticleDAO) CountPubByTag(ctx context.Context, uid int64, tag string, status uint8) (int64, error) {
+	var cnt int64
+	db := dao.db.WithContext(ctx)
+	err := excludeBlocked(db, uid).Model(&PublishedArticleTag{}).
+		Joins("JOIN published_articles ON published_articles.id = published_article_tags.aid").
+		Where("published_article_tags.tag = ? AND published_articles.status = ?", tag, status).
+		Count(&cnt).Error
+	return cnt, err
+}
+
+// excludeBlocked 登录了的读者看不到自己拉黑的作者，uid 为 0 是没登录
+func excludeBlocked(db *gorm.DB, uid int64) *gorm.DB {
+	if uid <= 0 {
+		return db
+	}
+	return db.Where("published_articles.author_id NOT IN (?)", blockedBy(db, uid))
+}
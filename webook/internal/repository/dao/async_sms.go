@@ -0,0 +1,144 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+const (
+	asyncStatusWaiting = iota
+	// 已经被某个实例抢占了，正在发送
+	asyncStatusSending
+	asyncStatusSuccess
+	// 重试次数耗尽
+	asyncStatusFailed
+)
+
+// 正在发送的短信超过这个时间还没有结果，就认为抢占它的实例已经崩了
+const asyncSendingTimeout = time.Minute
+
+var (
+	ErrWaitingSMSNotFound = gorm.ErrRecordNotFound
+	// ErrAsyncSMSPreemptLost 短信已经不是自己抢占的那一次了
+	ErrAsyncSMSPreemptLost = errors.New("短信已经被别的实例抢占了")
+)
+
+type AsyncSMSDAO interface {
+	Insert(ctx context.Context, s AsyncSMS) error
+	GetWaitingSMS(ctx context.Context) (AsyncSMS, error)
+	// 下面这几个 preemptedAt 是抢占的时候的 utime，只有还是那一次抢占才会更新
+	MarkSuccess(ctx context.Context, id int64, preemptedAt int64) error
+	MarkRetry(ctx context.Context, id int64, preemptedAt int64, nextRetry int64) error
+	MarkFailed(ctx context.Context, id int64, preemptedAt int64) error
+}
+
+type AsyncSMS struct {
+	Id     int64     `gorm:"primaryKey,autoIncrement"`
+	Config SMSConfig `gorm:"serializer:json"`
+	// 已经重试了几次
+	RetryCnt int
+	Status   uint8 `gorm:"index:status_next_retry"`
+	// 下一次可以重试的时间，毫秒
+	NextRetry int64 `gorm:"index:status_next_retry"`
+	Ctime     int64
+	Utime     int64
+}
+
+type SMSConfig struct {
+	TplId   string
+	Args    []string
+	Numbers []string
+}
+
+type GORMAsyncSMSDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMAsyncSMSDAO(db *gorm.DB) AsyncSMSDAO {
+	return &GORMAsyncSMSDAO{
+		db: db,
+	}
+}
+
+func (dao *GORMAsyncSMSDAO) Insert(ctx context.Context, s AsyncSMS) error {
+	now := time.Now().UnixMilli()
+	s.Ctime = now
+	s.Utime = now
+	s.NextRetry = now
+	s.Status = asyncStatusWaiting
+	return dao.db.WithContext(ctx).Create(&s).Error
+}
+
+func (dao *GORMAsyncSMSDAO) GetWaitingSMS(ctx context.Context) (AsyncSMS, error) {
+	var s AsyncSMS
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UnixMilli()
+		deadline := now - asyncSendingTimeout.Milliseconds()
+		// SELECT ... FOR UPDATE，多个实例同时来抢，只有一个能拿到
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("(status = ? AND next_retry <= ?) OR (status = ? AND utime < ?)",
+				asyncStatusWaiting, now, asyncStatusSending, deadline).
+			Order("next_retry ASC").
+			First(&s).Error
+		if err != nil {
+			return err
+		}
+		updates := map[string]any{
+			"status": asyncStatusSending,
+			"utime":  now,
+		}
+		if s.Status == asyncStatusSending {
+			// 上一个实例崩了或者卡住了，也算一次重试，不然这条短信会一直被抢来抢去
+			updates["retry_cnt"] = gorm.Expr("`retry_cnt` + 1")
+			s.RetryCnt++
+		}
+		s.Status = asyncStatusSending
+		s.Utime = now
+		return tx.Model(&AsyncSMS{}).
+			Where("id = ?", s.Id).
+			Updates(updates).Error
+	})
+	return s, err
+}
+
+func (dao *GORMAsyncSMSDAO) MarkSuccess(ctx context.Context, id int64, preemptedAt int64) error {
+	return dao.markPreempted(ctx, id, preemptedAt, map[string]any{
+		"status": asyncStatusSuccess,
+		"utime":  time.Now().UnixMilli(),
+	})
+}
+
+func (dao *GORMAsyncSMSDAO) MarkRetry(ctx context.Context, id int64, preemptedAt int64, nextRetry int64) error {
+	return dao.markPreempted(ctx, id, preemptedAt, map[string]any{
+		"status":     asyncStatusWaiting,
+		"retry_cnt":  gorm.Expr("`retry_cnt` + 1"),
+		"next_retry": nextRetry,
+		"utime":      time.Now().UnixMilli(),
+	})
+}
+
+func (dao *GORMAsyncSMSDAO) MarkFailed(ctx context.Context, id int64, preemptedAt int64) error {
+	return dao.markPreempted(ctx, id, preemptedAt, map[string]any{
+		"status":    asyncStatusFailed,
+		"retry_cnt": gorm.Expr("`retry_cnt` + 1"),
+		"utime":     time.Now().UnixMilli(),
+	})
+}
+
+// markPreempted compare-and-set，发得太慢被别的实例抢走了，就不能再改状态
+func (dao *GORMAsyncSMSDAO) markPreempted(ctx context.Context, id int64, preemptedAt int64,
+	updates map[string]any) error {
+	res := dao.db.WithContext(ctx).Model(&AsyncSMS{}).
+		Where("id = ? AND status = ? AND utime = ?", id, asyncStatusSending, preemptedAt).
+		Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrAsyncSMSPreemptLost
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package dao
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+const (
+	blockStatusInactive uint8 = iota
+	blockStatusActive
+)
+
+type BlockDAO interface {
+	Block(ctx context.Context, blocker, blocked int64) error
+	Unblock(ctx context.Context, blocker, blocked int64) error
+	// BlockedIds blocker 拉黑的所有人，一个人拉黑的不会很多，直接全部查出来
+	BlockedIds(ctx context.Context, blocker int64) ([]int64, error)
+	BlockedList(ctx context.Context, blocker int64, offset, limit int) ([]BlockRelation, error)
+}
+
+type GORMBlockDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMBlockDAO(db *gorm.DB) BlockDAO {
+	return &GORMBlockDAO{
+		db: db,
+	}
+}
+
+func (dao *GORMBlockDAO) Block(ctx context.Context, blocker, blocked int64) error {
+	now := time.Now().UnixMilli()
+	// 解除过拉黑的话，唯一索引冲突，直接改状态
+	return dao.db.WithContext(ctx).Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{
+			"status": blockStatusActive,
+			"utime":  now,
+		}),
+	}).Create(&BlockRelation{
+		Blocker: blocker,
+		Blocked: blocked,
+		Status:  blockStatusActive,
+		Ctime:   now,
+		Utime:   now,
+	}).Error
+}
+
+func (dao *GORMBlockDAO) Unblock(ctx context.Context, blocker, blocked int64) error {
+	return dao.db.WithContext(ctx).Model(&BlockRelation{}).
+		Where("blocker = ? AND blocked = ? AND status = ?",
+			blocker, blocked, blockStatusActive).
+		Updates(map[string]any{
+			"status": blockStatusInactive,
+			"utime":  time.Now().UnixMilli(),
+		}).Error
+}
+
+func (dao *GORMBlockDAO) BlockedIds(ctx context.Context, blocker int64) ([]int64, error) {
+	var res []int64
+	err := dao.db.WithContext(ctx).Model(&BlockRelation{}).
+		Where("blocker = ? AND status = ?", blocker, blockStatusActive).
+		Pluck("blocked", &res).Error
+	return res, err
+}
+
+func (dao *GORMBlockDAO) BlockedList(ctx context.Context, blocker int64, offset, limit int) ([]BlockRelation, error) {
+	var res []BlockRelation
+	err := dao.db.WithContext(ctx).
+		Where("blocker = ? AND status = ?", blocker, blockStatusActive).
+		Order("utime DESC").Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+// blockedBy blocker 拉黑了的人，给别的查询过滤作者用
+func blockedBy(db *gorm.DB, blocker int64) *gorm.DB {
+	return db.Model(&BlockRelation{}).
+		Select("blocked").
+		Where("blocker = ? AND status = ?", blocker, blockStatusActive)
+}
+
+type BlockRelation struct {
+	Id      int64 `gorm:"primaryKey,autoIncrement"`
+	Blocker int64 `gorm:"uniqueIndex:blocker_blocked"`
+	Blocked int64 `gorm:"uniqueIndex:blocker_blocked"`
+	// 解除拉黑不删除记录，只改状态
+	Status uint8
+	Ctime  int64
+	Utime  int64
+}
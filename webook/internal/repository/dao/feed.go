@@ -0,0 +1,152 @@
+package dao
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+type FeedDAO interface {
+	// CountAudience 有多少读者点赞或者收藏过这个作者的文章
+	CountAudience(ctx context.Context, biz string, author int64) (int64, error)
+	Audience(ctx context.Context, biz string, author int64) ([]int64, error)
+	// SetAudience 记录作者的读者数，拉模式要靠这个来判断大作者
+	SetAudience(ctx context.Context, author int64, audience int64) error
+	PushInbox(ctx context.Context, inboxes []FeedInbox) error
+	// InboxArticles 推模式，从收件箱里面读
+	InboxArticles(ctx context.Context, uid int64, status uint8,
+		cursorTime, cursorAid int64, limit int) ([]FeedArticle, error)
+	// PullArticles 拉模式，直接查读者喜欢过的大作者最近发表的文章
+	PullArticles(ctx context.Context, biz string, uid int64, minAudience int64, status uint8,
+		cursorTime, cursorAid int64, limit int) ([]FeedArticle, error)
+}
+
+type GORMFeedDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMFeedDAO(db *gorm.DB) FeedDAO {
+	return &GORMFeedDAO{
+		db: db,
+	}
+}
+
+func (dao *GORMFeedDAO) CountAudience(ctx context.Context, biz string, author int64) (int64, error) {
+	var cnt int64
+	err := dao.db.WithContext(ctx).
+		Raw("SELECT COUNT(*) FROM (? UNION ?) AS audience",
+			dao.likeAudience(ctx, biz, author), dao.collectAudience(ctx, biz, author)).
+		Scan(&cnt).Error
+	return cnt, err
+}
+
+func (dao *GORMFeedDAO) Audience(ctx context.Context, biz string, author int64) ([]int64, error) {
+	var res []int64
+	err := dao.db.WithContext(ctx).
+		Raw("? UNION ?", dao.likeAudience(ctx, biz, author), dao.collectAudience(ctx, biz, author)).
+		Scan(&res).Error
+	return res, err
+}
+
+func (dao *GORMFeedDAO) likeAudience(ctx context.Context, biz string, author int64) *gorm.DB {
+	return dao.db.WithContext(ctx).Table("user_like_bizs AS l").
+		Select("l.uid").
+		Joins("JOIN published_articles AS p ON p.id = l.biz_id").
+		Where("l.biz = ? AND l.status = ? AND p.author_id = ? AND l.uid <> ?", biz, 1, author, author)
+}
+
+func (dao *GORMFeedDAO) collectAudience(ctx context.Context, biz string, author int64) *gorm.DB {
+	return dao.db.WithContext(ctx).Table("user_collection_bizs AS c").
+		Select("c.uid").
+		Joins("JOIN published_articles AS p ON p.id = c.biz_id").
+		Where("c.biz = ? AND p.author_id = ? AND c.uid <> ?", biz, author, author)
+}
+
+func (dao *GORMFeedDAO) SetAudience(ctx context.Context, author int64, audience int64) error {
+	now := time.Now().UnixMilli()
+	return dao.db.WithContext(ctx).Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{
+			"audience": audience,
+			"utime":    now,
+		}),
+	}).Create(&FeedAuthor{
+		AuthorId: author,
+		Audience: audience,
+		Ctime:    now,
+		Utime:    now,
+	}).Error
+}
+
+func (dao *GORMFeedDAO) PushInbox(ctx context.Context, inboxes []FeedInbox) error {
+	// 重新发表的文章要排到前面去
+	return dao.db.WithContext(ctx).Clauses(clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{"ctime"}),
+	}).CreateInBatches(inboxes, 500).Error
+}
+
+func (dao *GORMFeedDAO) InboxArticles(ctx context.Context, uid int64, status uint8,
+	cursorTime, cursorAid int64, limit int) ([]FeedArticle, error) {
+	db := dao.db.WithContext(ctx)
+	var res []FeedArticle
+	err := db.Table("feed_inboxes AS i").
+		Select("p.*, i.ctime AS feed_time").
+		Joins("JOIN published_articles AS p ON p.id = i.aid").
+		Where("i.uid = ? AND p.status = ?", uid, status).
+		// 拉黑的作者的文章可能早就推进收件箱了
+		Where("p.author_id NOT IN (?)", blockedBy(db, uid)).
+		Where("i.ctime < ? OR (i.ctime = ? AND i.aid < ?)", cursorTime, cursorTime, cursorAid).
+		Order("i.ctime DESC, i.aid DESC").
+		Limit(limit).Scan(&res).Error
+	return res, err
+}
+
+func (dao *GORMFeedDAO) PullArticles(ctx context.Context, biz string, uid int64, minAudience int64, status uint8,
+	cursorTime, cursorAid int64, limit int) ([]FeedArticle, error) {
+	db := dao.db.WithContext(ctx)
+	likedAuthors := db.Table("user_like_bizs AS l").
+		Select("p.author_id").
+		Joins("JOIN published_articles AS p ON p.id = l.biz_id").
+		Where("l.uid = ? AND l.biz = ? AND l.status = ?", uid, biz, 1)
+	collectedAuthors := db.Table("user_collection_bizs AS c").
+		Select("p.author_id").
+		Joins("JOIN published_articles AS p ON p.id = c.biz_id").
+		Where("c.uid = ? AND c.biz = ?", uid, biz)
+	bigAuthors := db.Model(&FeedAuthor{}).
+		Select("author_id").
+		Where("audience > ?", minAudience)
+	var res []FeedArticle
+	err := db.Model(&PublishedArticle{}).
+		Select("*, utime AS feed_time").
+		Where("status = ? AND author_id IN (?)", status, bigAuthors).
+		Where("author_id IN (?) OR author_id IN (?)", likedAuthors, collectedAuthors).
+		Where("author_id NOT IN (?)", blockedBy(db, uid)).
+		Where("utime < ? OR (utime = ? AND id < ?)", cursorTime, cursorTime, cursorAid).
+		Order("utime DESC, id DESC").
+		Limit(limit).Scan(&res).Error
+	return res, err
+}
+
+// FeedInbox 推模式下读者的收件箱，一篇文章一行
+type FeedInbox struct {
+	Id  int64 `gorm:"primaryKey,autoIncrement"`
+	Uid int64 `gorm:"uniqueIndex:uid_aid;index:uid_ctime"`
+	Aid int64 `gorm:"uniqueIndex:uid_aid"`
+	// 推进来的时间，也就是发表的时间
+	Ctime int64 `gorm:"index:uid_ctime"`
+}
+
+// FeedAuthor 作者最近一次发表文章的时候有多少读者
+type FeedAuthor struct {
+	Id       int64 `gorm:"primaryKey,autoIncrement"`
+	AuthorId int64 `gorm:"unique"`
+	Audience int64 `gorm:"index"`
+	Ctime    int64
+	Utime    int64
+}
+
+// FeedArticle 信息流里面的文章，FeedTime 用来排序和翻页
+type FeedArticle struct {
+	PublishedArticle `gorm:"embedded"`
+	FeedTime         int64
+}
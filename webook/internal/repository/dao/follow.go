@@ -0,0 +1,173 @@
+package dao
+
+import (
+	"context"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+const (
+	followStatusInactive uint8 = iota
+	followStatusActive
+)
+
+type FollowDAO interface {
+	// CreateFollowRelation 返回关注关系有没有变化，已经关注过了就是 false
+	CreateFollowRelation(ctx context.Context, follower, followee int64) (bool, error)
+	// CancelFollowRelation 返回关注关系有没有变化，本来就没有关注就是 false
+	CancelFollowRelation(ctx context.Context, follower, followee int64) (bool, error)
+	FollowRelationDetail(ctx context.Context, follower, followee int64) (FollowRelation, error)
+	FollowerList(ctx context.Context, followee int64, offset, limit int) ([]FollowRelation, error)
+	FolloweeList(ctx context.Context, follower int64, offset, limit int) ([]FollowRelation, error)
+	GetStatics(ctx context.Context, uid int64) (FollowStatics, error)
+}
+
+type GORMFollowDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMFollowDAO(db *gorm.DB) FollowDAO {
+	return &GORMFollowDAO{
+		db: db,
+	}
+}
+
+func (dao *GORMFollowDAO) CreateFollowRelation(ctx context.Context, follower, followee int64) (bool, error) {
+	now := time.Now().UnixMilli()
+	changed := false
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 锁住这一行，并发关注同一个人的时候计数不会多加
+		var existing FollowRelation
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("follower = ? AND followee = ?", follower, followee).
+			First(&existing).Error
+		switch err {
+		case nil:
+			if existing.Status == followStatusActive {
+				return nil
+			}
+		case gorm.ErrRecordNotFound:
+		default:
+			return err
+		}
+		// 取消过关注的话，唯一索引冲突，直接改状态
+		err = tx.Clauses(clause.OnConflict{
+			DoUpdates: clause.Assignments(map[string]any{
+				"status": followStatusActive,
+				"utime":  now,
+			}),
+		}).Create(&FollowRelation{
+			Follower: follower,
+			Followee: followee,
+			Status:   followStatusActive,
+			Ctime:    now,
+			Utime:    now,
+		}).Error
+		if err != nil {
+			return err
+		}
+		err = dao.incrStatics(tx, follower, 0, 1, now)
+		if err != nil {
+			return err
+		}
+		changed = true
+		return dao.incrStatics(tx, followee, 1, 0, now)
+	})
+	return changed, err
+}
+
+func (dao *GORMFollowDAO) CancelFollowRelation(ctx context.Context, follower, followee int64) (bool, error) {
+	now := time.Now().UnixMilli()
+	changed := false
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&FollowRelation{}).
+			Where("follower = ? AND followee = ? AND status = ?",
+				follower, followee, followStatusActive).
+			Updates(map[string]any{
+				"status": followStatusInactive,
+				"utime":  now,
+			})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		err := dao.incrStatics(tx, follower, 0, -1, now)
+		if err != nil {
+			return err
+		}
+		changed = true
+		return dao.incrStatics(tx, followee, -1, 0, now)
+	})
+	return changed, err
+}
+
+// incrStatics 没有记录就插入，只有加的时候才可能没有记录
+func (dao *GORMFollowDAO) incrStatics(tx *gorm.DB, uid int64, followers, followees int64, now int64) error {
+	return tx.Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{
+			"followers": gorm.Expr("`followers` + ?", followers),
+			"followees": gorm.Expr("`followees` + ?", followees),
+			"utime":     now,
+		}),
+	}).Create(&FollowStatics{
+		Uid:       uid,
+		Followers: followers,
+		Followees: followees,
+		Ctime:     now,
+		Utime:     now,
+	}).Error
+}
+
+func (dao *GORMFollowDAO) FollowRelationDetail(ctx context.Context, follower, followee int64) (FollowRelation, error) {
+	var res FollowRelation
+	err := dao.db.WithContext(ctx).
+		Where("follower = ? AND followee = ? AND status = ?",
+			follower, followee, followStatusActive).
+		First(&res).Error
+	return res, err
+}
+
+func (dao *GORMFollowDAO) FollowerList(ctx context.Context, followee int64, offset, limit int) ([]FollowRelation, error) {
+	var res []FollowRelation
+	err := dao.db.WithContext(ctx).
+		Where("followee = ? AND status = ?", followee, followStatusActive).
+		Order("utime DESC").Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMFollowDAO) FolloweeList(ctx context.Context, follower int64, offset, limit int) ([]FollowRelation, error) {
+	var res []FollowRelation
+	err := dao.db.WithContext(ctx).
+		Where("follower = ? AND status = ?", follower, followStatusActive).
+		Order("utime DESC").Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMFollowDAO) GetStatics(ctx context.Context, uid int64) (FollowStatics, error) {
+	var res FollowStatics
+	err := dao.db.WithContext(ctx).Where("uid = ?", uid).First(&res).Error
+	return res, err
+}
+
+type FollowRelation struct {
+	Id int64 `gorm:"primaryKey,autoIncrement"`
+	// 唯一索引保证不会重复关注，同时也可以查我关注了谁
+	Follower int64 `gorm:"uniqueIndex:follower_followee"`
+	Followee int64 `gorm:"uniqueIndex:follower_followee;index:followee_status"`
+	// 取消关注不删除记录，只改状态
+	Status uint8 `gorm:"index:followee_status"`
+	Ctime  int64
+	Utime  int64
+}
+
+// FollowStatics 关注数和粉丝数，和关注关系在同一个事务里面更新
+type FollowStatics struct {
+	Id        int64 `gorm:"primaryKey,autoIncrement"`
+	Uid       int64 `gorm:"unique"`
+	Followers int64
+	Followees int64
+	Ctime     int64
+	Utime     int64
+}
@@ -3,5 +3,9 @@ package dao
 import "gorm.io/gorm"
 
 func InitTable(db *gorm.DB) error {
-	return db.AutoMigrate(&User{}, &Article{}, &PublishedArticle{})
+	return db.AutoMigrate(&User{}, &Article{}, &PublishedArticle{}, &AsyncSMS{},
+		&UserMFA{}, &MFARecoveryCode{}, &FollowRelation{}, &FollowStatics{},
+		&FeedInbox{}, &FeedAuthor{}, &BlockRelation{}, &ArticleRevision{},
+		&ArticleTag{}, &PublishedArticleTag{}, &ArticleCategory{}, &PublishedArticleCategory{},
+		&Series{}, &SeriesArticle{})
 }
@@ -0,0 +1,110 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+var ErrRecoveryCodeNotFound = errors.New("恢复码不存在或者已经用过了")
+
+type MFADAO interface {
+	FindByUid(ctx context.Context, uid int64) (UserMFA, error)
+	// Upsert 保存还没有确认的密钥，重新绑定会覆盖
+	Upsert(ctx context.Context, m UserMFA) error
+	// Enable 开启两步验证，同时换掉所有的恢复码
+	Enable(ctx context.Context, uid int64, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, uid int64, codeHash string) error
+}
+
+type UserMFA struct {
+	Id      int64 `gorm:"primaryKey,autoIncrement"`
+	Uid     int64 `gorm:"unique"`
+	Secret  string
+	Enabled bool
+	Ctime   int64
+	Utime   int64
+}
+
+// MFARecoveryCode 手机丢了的时候用的恢复码，只存哈希
+type MFARecoveryCode struct {
+	Id    int64  `gorm:"primaryKey,autoIncrement"`
+	Uid   int64  `gorm:"index:uid_hash"`
+	Hash  string `gorm:"type:varchar(64);index:uid_hash"`
+	Used  bool
+	Ctime int64
+	Utime int64
+}
+
+type GORMMFADAO struct {
+	db *gorm.DB
+}
+
+func NewGORMMFADAO(db *gorm.DB) MFADAO {
+	return &GORMMFADAO{
+		db: db,
+	}
+}
+
+func (dao *GORMMFADAO) FindByUid(ctx context.Context, uid int64) (UserMFA, error) {
+	var m UserMFA
+	err := dao.db.WithContext(ctx).Where("uid = ?", uid).First(&m).Error
+	return m, err
+}
+
+func (dao *GORMMFADAO) Upsert(ctx context.Context, m UserMFA) error {
+	now := time.Now().UnixMilli()
+	m.Ctime = now
+	m.Utime = now
+	return dao.db.WithContext(ctx).Clauses(clause.OnConflict{
+		DoUpdates: clause.AssignmentColumns([]string{"secret", "enabled", "utime"}),
+	}).Create(&m).Error
+}
+
+func (dao *GORMMFADAO) Enable(ctx context.Context, uid int64, codeHashes []string) error {
+	now := time.Now().UnixMilli()
+	return dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&UserMFA{}).Where("uid = ?", uid).
+			Updates(map[string]any{
+				"enabled": true,
+				"utime":   now,
+			}).Error
+		if err != nil {
+			return err
+		}
+		// 旧的恢复码全部作废
+		err = tx.Where("uid = ?", uid).Delete(&MFARecoveryCode{}).Error
+		if err != nil {
+			return err
+		}
+		codes := make([]MFARecoveryCode, 0, len(codeHashes))
+		for _, h := range codeHashes {
+			codes = append(codes, MFARecoveryCode{
+				Uid:   uid,
+				Hash:  h,
+				Ctime: now,
+				Utime: now,
+			})
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+func (dao *GORMMFADAO) UseRecoveryCode(ctx context.Context, uid int64, codeHash string) error {
+	// 带上 used = false 的条件，并发的时候只有一个能用成功
+	res := dao.db.WithContext(ctx).Model(&MFARecoveryCode{}).
+		Where("uid = ? AND hash = ? AND used = ?", uid, codeHash, false).
+		Updates(map[string]any{
+			"used":  true,
+			"utime": time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrRecoveryCodeNotFound
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+var (
+	// ErrSeriesInvalidArticle 不是作者自己已经发表的文章
+	ErrSeriesInvalidArticle = errors.New("article is not published by the author")
+	// ErrArticleInOtherSeries 一篇文章只能在一个系列里面
+	ErrArticleInOtherSeries = errors.New("article already in another series")
+)
+
+type SeriesDAO interface {
+	Insert(ctx context.Context, s Series) (int64, error)
+	// Update 只改标题和简介，不是作者的系列返回 ErrRecordNotFound
+	Update(ctx context.Context, s Series) error
+	// Delete 返回原来在系列里面的文章，用来清缓存
+	Delete(ctx context.Context, uid int64, sid int64) ([]int64, error)
+	// SetArticles 按照 aids 的顺序覆盖原来的文章，返回原来的文章
+	SetArticles(ctx context.Context, uid int64, sid int64, aids []int64, status uint8) ([]int64, error)
+	GetById(ctx context.Context, sid int64) (Series, error)
+	// GetArticles 系列里面状态是 status 的线上文章，按照顺序，只有 id 和标题
+	GetArticles(ctx context.Context, sid int64, status uint8) ([]PublishedArticle, error)
+	GetByAuthor(ctx context.Context, uid int64, offset int, limit int) ([]Series, error)
+	// GetSid 文章所在的系列，不在任何系列里面返回 ErrRecordNotFound
+	GetSid(ctx context.Context, aid int64) (int64, error)
+}
+
+type GORMSeriesDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMSeriesDAO(db *gorm.DB) SeriesDAO {
+	return &GORMSeriesDAO{
+		db: db,
+	}
+}
+
+func (dao *GORMSeriesDAO) Insert(ctx context.Context, s Series) (int64, error) {
+	now := time.Now().UnixMilli()
+	s.Ctime = now
+	s.Utime = now
+	err := dao.db.WithContext(ctx).Create(&s).Error
+	return s.Id, err
+}
+
+func (dao *GORMSeriesDAO) Update(ctx context.Context, s Series) error {
+	res := dao.db.WithContext(ctx).Model(&Series{}).
+		Where("id = ? AND author_id = ?", s.Id, s.AuthorId).
+		Updates(map[string]any{
+			"title":       s.Title,
+			"description": s.Description,
+			"utime":       time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+func (dao *GORMSeriesDAO) Delete(ctx context.Context, uid int64, sid int64) ([]int64, error) {
+	var aids []int64
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ? AND author_id = ?", sid, uid).Delete(&Series{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrRecordNotFound
+		}
+		err := tx.Model(&SeriesArticle{}).Where("sid = ?", sid).Pluck("aid", &aids).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("sid = ?", sid).Delete(&SeriesArticle{}).Error
+	})
+	return aids, err
+}
+
+func (dao *GORMSeriesDAO) SetArticles(ctx context.Context, uid int64, sid int64,
+	aids []int64, status uint8) ([]int64, error) {
+	var old []int64
+	now := time.Now().UnixMilli()
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 锁住系列，同一个系列的修改排队
+		var s Series
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND author_id = ?", sid, uid).
+			First(&s).Error
+		if err != nil {
+			return err
+		}
+		if len(aids) > 0 {
+			var cnt int64
+			err = tx.Model(&PublishedArticle{}).
+				Where("id IN ? AND author_id = ? AND status = ?", aids, uid, status).
+				Count(&cnt).Error
+			if err != nil {
+				return err
+			}
+			if cnt != int64(len(aids)) {
+				return ErrSeriesInvalidArticle
+			}
+		}
+		err = tx.Model(&SeriesArticle{}).Where("sid = ?", sid).Pluck("aid", &old).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("sid = ?", sid).Delete(&SeriesArticle{}).Error
+		if err != nil {
+			return err
+		}
+		if len(aids) > 0 {
+			rows := make([]SeriesArticle, 0, len(aids))
+			for i, aid := range aids {
+				rows = append(rows, SeriesArticle{
+					Sid:      sid,
+					Aid:      aid,
+					Position: i,
+					Ctime:    now,
+				})
+			}
+			// aid 上面有唯一索引，别的系列已经有了就会冲突
+			err = dao.translateErr(tx.Create(&rows).Error)
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Model(&Series{}).Where("id = ?", sid).
+			Update("utime", now).Error
+	})
+	return old, err
+}
+
+func (dao *GORMSeriesDAO) GetById(ctx context.Context, sid int64) (Series, error) {
+	var s Series
+	err := dao.db.WithContext(ctx).Where("id = ?", sid).First(&s).Error
+	return s, err
+}
+
+func (dao *GORMSeriesDAO) GetArticles(ctx context.Context, sid int64, status uint8) ([]PublishedArticle, error) {
+	var res []PublishedArticle
+	err := dao.db.WithContext(ctx).
+		Select("published_articles.id", "published_articles.title").
+		Joins("JOIN series_articles ON series_articles.aid = published_articles.id").
+		Where("series_articles.sid = ? AND published_articles.status = ?", sid, status).
+		Order("series_articles.position ASC").
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMSeriesDAO) GetByAuthor(ctx context.Context, uid int64, offset int, limit int) ([]Series, error) {
+	var res []Series
+	err := dao.db.WithContext(ctx).
+		Where("author_id = ?", uid).
+		Order("utime DESC, id DESC").
+		Offset(offset).Limit(limit).
+		Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMSeriesDAO) GetSid(ctx context.Context, aid int64) (int64, error) {
+	var sa SeriesArticle
+	err := dao.db.WithContext(ctx).Where("aid = ?", aid).First(&sa).Error
+	return sa.Sid, err
+}
+
+func (dao *GORMSeriesDAO) translateErr(err error) error {
+	if mysqlError, ok := err.(*mysql.MySQLError); ok {
+		const uniqueConflictsErrNo uint16 = 1062
+		if mysqlError.Number == uniqueConflictsErrNo {
+			return ErrArticleInOtherSeries
+		}
+	}
+	return err
+}
+
+type Series struct {
+	Id          int64  `gorm:"primaryKey,autoIncrement"`
+	AuthorId    int64  `gorm:"index"`
+	Title       string `gorm:"type:varchar(256)"`
+	Description string `gorm:"type:varchar(2048)"`
+	Ctime       int64
+	Utime       int64
+}
+
+// SeriesArticle 系列和文章的关联，一篇文章只能在一个系列里面
+type SeriesArticle struct {
+	Id  int64 `gorm:"primaryKey,autoIncrement"`
+	Sid int64 `gorm:"index:sid_position,priority:1"`
+	Aid int64 `gorm:"uniqueIndex"`
+	// 在系列里面的顺序，从 0 开始
+	Position int `gorm:"index:sid_position,priority:2"`
+	Ctime    int64
+}
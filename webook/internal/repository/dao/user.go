@@ -2,6 +2,7 @@ package dao
 
 import (
 	"context"
+	"database/sql"
 	"github.com/go-sql-driver/mysql"
 	"github.com/pkg/errors"
 	"gorm.io/gorm"
@@ -9,21 +10,49 @@ import (
 )
 
 var (
-	ErrUserDuplicated = errors.New("email conflict")
+	ErrUserDuplicated = errors.New("email or phone conflict")
 	ErrRecordNotFound = gorm.ErrRecordNotFound
 )
 
 type UserDAO interface {
 	FindByEmail(ctx context.Context, email string) (User, error)
 	FindById(ctx context.Context, id int64) (User, error)
+	FindByPhone(ctx context.Context, phone string) (User, error)
+	FindByWechat(ctx context.Context, openId string) (User, error)
 	Insert(ctx context.Context, u User) error
+	UpdateNonZeroFields(ctx context.Context, u User) error
+	// SetDeleteAfter 申请注销或者撤销注销，0 表示撤销
+	SetDeleteAfter(ctx context.Context, id int64, deleteAfter int64) error
+	FindDueDeletion(ctx context.Context, now int64, limit int) ([]User, error)
+	UpdateRoles(ctx context.Context, id int64, roles string) error
 }
 
 // 负责数据库对接 要有gorm的标签
 type User struct {
-	Id       int64  `gorm:"primaryKey,autoIncrement"`
-	Email    string `gorm:"unique"`
-	Password string
+	Id int64 `gorm:"primaryKey,autoIncrement"`
+	// 手机号登录的用户没有邮箱，唯一索引允许多个 NULL
+	Email         sql.NullString `gorm:"unique"`
+	EmailVerified bool
+	Password      string
+
+	Phone sql.NullString `gorm:"unique"`
+
+	// 微信的身份，同样允许 NULL
+	WechatOpenId  sql.NullString `gorm:"unique"`
+	WechatUnionId sql.NullString `gorm:"index"`
+
+	Nickname string `gorm:"type:varchar(128)"`
+	// 毫秒数，0 表示没填
+	Birthday int64
+	AboutMe  string `gorm:"type:varchar(4096)"`
+
+	// 逗号分隔的角色，空字符串表示只是普通读者
+	Roles string `gorm:"type:varchar(255)"`
+
+	// 申请注销之后的清除时间，0 表示没有申请
+	DeleteAfter int64 `gorm:"index"`
+	// 已经清除过个人数据了
+	Purged bool
 
 	Ctime int64
 	Utime int64
@@ -53,12 +82,69 @@ func (dao *GORMUserDAO) FindById(ctx context.Context, id int64) (User, error) {
 	return u, err
 }
 
+func (dao *GORMUserDAO) FindByPhone(ctx context.Context, phone string) (User, error) {
+	var u User
+	err := dao.db.WithContext(ctx).Where("phone = ?", phone).First(&u).Error
+	return u, err
+}
+
+func (dao *GORMUserDAO) FindByWechat(ctx context.Context, openId string) (User, error) {
+	var u User
+	err := dao.db.WithContext(ctx).Where("wechat_open_id = ?", openId).First(&u).Error
+	return u, err
+}
+
 func (dao *GORMUserDAO) Insert(ctx context.Context, u User) error {
 	//有的公司存毫秒数
 	now := time.Now().UnixMilli()
 	u.Ctime = now
 	u.Utime = now
 	err := dao.db.WithContext(ctx).Create(&u).Error
+	return dao.translateErr(err)
+}
+
+// UpdateNonZeroFields 只更新非零值的字段，零值会被 GORM 忽略
+func (dao *GORMUserDAO) UpdateNonZeroFields(ctx context.Context, u User) error {
+	u.Utime = time.Now().UnixMilli()
+	err := dao.db.WithContext(ctx).Model(&User{}).
+		Where("id = ?", u.Id).Updates(&u).Error
+	// 换邮箱的时候可能和别人冲突
+	return dao.translateErr(err)
+}
+
+func (dao *GORMUserDAO) SetDeleteAfter(ctx context.Context, id int64, deleteAfter int64) error {
+	return dao.db.WithContext(ctx).Model(&User{}).Where("id = ? AND purged = ?", id, false).
+		Updates(map[string]any{
+			"delete_after": deleteAfter,
+			"utime":        time.Now().UnixMilli(),
+		}).Error
+}
+
+func (dao *GORMUserDAO) FindDueDeletion(ctx context.Context, now int64, limit int) ([]User, error) {
+	var res []User
+	err := dao.db.WithContext(ctx).
+		Where("delete_after > 0 AND delete_after <= ?", now).
+		Limit(limit).Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMUserDAO) UpdateRoles(ctx context.Context, id int64, roles string) error {
+	// 已经清除的用户也当成不存在
+	res := dao.db.WithContext(ctx).Model(&User{}).Where("id = ? AND purged = ?", id, false).
+		Updates(map[string]any{
+			"roles": roles,
+			"utime": time.Now().UnixMilli(),
+		})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+func (dao *GORMUserDAO) translateErr(err error) error {
 	if mysqlError, ok := err.(*mysql.MySQLError); ok {
 		const uniqueConflictsErrNo uint16 = 1062
 		if mysqlError.Number == uniqueConflictsErrNo {
@@ -0,0 +1,212 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"time"
+)
+
+// UserDataDAO 跨表的个人数据操作，导出和注销之后的清除
+type UserDataDAO interface {
+	ArticlesByAuthor(ctx context.Context, uid int64, offset, limit int) ([]Article, error)
+	PublishedByAuthor(ctx context.Context, uid int64, offset, limit int) ([]PublishedArticle, error)
+	LikesByUid(ctx context.Context, uid int64, offset, limit int) ([]UserLikeBiz, error)
+	CollectionsByUid(ctx context.Context, uid int64, offset, limit int) ([]UserCollectionBiz, error)
+	// Purge 删除文章、关注拉黑这些关系、两步验证，匿名化点赞收藏，抹掉用户资料
+	Purge(ctx context.Context, uid int64) (PurgeResult, error)
+}
+
+// PurgeResult 清除之后要顺手清掉的缓存
+type PurgeResult struct {
+	// 被删除的文章
+	Aids []int64
+	// 关注数或者粉丝数被减掉的用户
+	FollowUids []int64
+}
+
+type GORMUserDataDAO struct {
+	db *gorm.DB
+}
+
+func NewGORMUserDataDAO(db *gorm.DB) UserDataDAO {
+	return &GORMUserDataDAO{
+		db: db,
+	}
+}
+
+// 导出的时候按照 id 排序，保证分页稳定
+
+func (dao *GORMUserDataDAO) ArticlesByAuthor(ctx context.Context, uid int64, offset, limit int) ([]Article, error) {
+	var res []Article
+	err := dao.db.WithContext(ctx).Where("author_id = ?", uid).
+		Order("id ASC").Offset(offset).Limit(limit).Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMUserDataDAO) PublishedByAuthor(ctx context.Context, uid int64, offset, limit int) ([]PublishedArticle, error) {
+	var res []PublishedArticle
+	err := dao.db.WithContext(ctx).Where("author_id = ?", uid).
+		Order("id ASC").Offset(offset).Limit(limit).Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMUserDataDAO) LikesByUid(ctx context.Context, uid int64, offset, limit int) ([]UserLikeBiz, error) {
+	var res []UserLikeBiz
+	err := dao.db.WithContext(ctx).Where("uid = ? AND status = ?", uid, 1).
+		Order("id ASC").Offset(offset).Limit(limit).Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMUserDataDAO) CollectionsByUid(ctx context.Context, uid int64, offset, limit int) ([]UserCollectionBiz, error) {
+	var res []UserCollectionBiz
+	err := dao.db.WithContext(ctx).Where("uid = ?", uid).
+		Order("id ASC").Offset(offset).Limit(limit).Find(&res).Error
+	return res, err
+}
+
+func (dao *GORMUserDataDAO) Purge(ctx context.Context, uid int64) (PurgeResult, error) {
+	var res PurgeResult
+	now := time.Now().UnixMilli()
+	err := dao.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&Article{}).Where("author_id = ?", uid).Pluck("id", &res.Aids).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("author_id = ?", uid).Delete(&Article{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("author_id = ?", uid).Delete(&PublishedArticle{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("author_id = ?", uid).Delete(&ArticleRevision{}).Error
+		if err != nil {
+			return err
+		}
+		err = deleteMeta(tx, res.Aids)
+		if err != nil {
+			return err
+		}
+		err = tx.Where("author_id = ?", uid).Delete(&Series{}).Error
+		if err != nil {
+			return err
+		}
+		if len(res.Aids) > 0 {
+			err = tx.Where("aid IN ?", res.Aids).Delete(&SeriesArticle{}).Error
+			if err != nil {
+				return err
+			}
+		}
+		res.FollowUids, err = dao.purgeFollow(tx, uid, now)
+		if err != nil {
+			return err
+		}
+		err = tx.Where("blocker = ? OR blocked = ?", uid, uid).Delete(&BlockRelation{}).Error
+		if err != nil {
+			return err
+		}
+		// 别人收件箱里面这个作者的文章不用管，读的时候 JOIN 不到已经删掉的文章
+		err = tx.Where("uid = ?", uid).Delete(&FeedInbox{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("author_id = ?", uid).Delete(&FeedAuthor{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("uid = ?", uid).Delete(&UserMFA{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("uid = ?", uid).Delete(&MFARecoveryCode{}).Error
+		if err != nil {
+			return err
+		}
+		// 点赞收藏的计数还要保留，所以不删记录，只是把 uid 换掉。
+		// 用记录自己的 -id，既不会撞唯一索引，也关联不回这个用户
+		err = tx.Model(&UserLikeBiz{}).Where("uid = ?", uid).
+			Updates(map[string]any{
+				"uid":   gorm.Expr("-`id`"),
+				"utime": now,
+			}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Model(&UserCollectionBiz{}).Where("uid = ?", uid).
+			Updates(map[string]any{
+				"uid":   gorm.Expr("-`id`"),
+				"utime": now,
+			}).Error
+		if err != nil {
+			return err
+		}
+		// 保留这一行，id 不会被复用，但是个人信息全部抹掉
+		return tx.Model(&User{}).Where("id = ?", uid).
+			Updates(map[string]any{
+				"email":           sql.NullString{},
+				"email_verified":  false,
+				"password":        "",
+				"phone":           sql.NullString{},
+				"wechat_open_id":  sql.NullString{},
+				"wechat_union_id": sql.NullString{},
+				"nickname":        "",
+				"birthday":        0,
+				"about_me":        "",
+				"roles":           "",
+				"delete_after":    0,
+				"purged":          true,
+				"utime":           now,
+			}).Error
+	})
+	return res, err
+}
+
+// purgeFollow 删掉这个用户的关注关系，对方的关注数、粉丝数跟着减，返回计数变了的用户
+func (dao *GORMUserDataDAO) purgeFollow(tx *gorm.DB, uid int64, now int64) ([]int64, error) {
+	// 锁住这些关系，并发取消关注的时候不会多减
+	var followees, followers []int64
+	err := tx.Model(&FollowRelation{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("follower = ? AND status = ?", uid, followStatusActive).
+		Pluck("followee", &followees).Error
+	if err != nil {
+		return nil, err
+	}
+	err = tx.Model(&FollowRelation{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("followee = ? AND status = ?", uid, followStatusActive).
+		Pluck("follower", &followers).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(followees) > 0 {
+		err = tx.Model(&FollowStatics{}).Where("uid IN ?", followees).
+			Updates(map[string]any{
+				"followers": gorm.Expr("`followers` - 1"),
+				"utime":     now,
+			}).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(followers) > 0 {
+		err = tx.Model(&FollowStatics{}).Where("uid IN ?", followers).
+			Updates(map[string]any{
+				"followees": gorm.Expr("`followees` - 1"),
+				"utime":     now,
+			}).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = tx.Where("follower = ? OR followee = ?", uid, uid).Delete(&FollowRelation{}).Error
+	if err != nil {
+		return nil, err
+	}
+	err = tx.Where("uid = ?", uid).Delete(&FollowStatics{}).Error
+	if err != nil {
+		return nil, err
+	}
+	return append(followees, followers...), nil
+}
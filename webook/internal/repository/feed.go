@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository/dao"
+)
+
+// 点赞收藏文章的时候用的 biz
+const feedBiz = "articles"
+
+type FeedRepository interface {
+	CountAudience(ctx context.Context, author int64) (int64, error)
+	// Audience 点赞或者收藏过这个作者文章的读者
+	Audience(ctx context.Context, author int64) ([]int64, error)
+	SetAudience(ctx context.Context, author int64, audience int64) error
+	// Push 把文章推进这些读者的收件箱
+	Push(ctx context.Context, aid int64, t time.Time, uids []int64) error
+	InboxItems(ctx context.Context, uid int64, cursor domain.ArticleCursor, limit int) ([]domain.FeedItem, error)
+	// PullItems 读者喜欢过的作者里面，读者数超过 minAudience 的那些作者的新文章
+	PullItems(ctx context.Context, uid int64, minAudience int64,
+		cursor domain.ArticleCursor, limit int) ([]domain.FeedItem, error)
+}
+
+type GORMFeedRepository struct {
+	dao dao.FeedDAO
+}
+
+func NewFeedRepository(dao dao.FeedDAO) FeedRepository {
+	return &GORMFeedRepository{
+		dao: dao,
+	}
+}
+
+func (r *GORMFeedRepository) CountAudience(ctx context.Context, author int64) (int64, error) {
+	return r.dao.CountAudience(ctx, feedBiz, author)
+}
+
+func (r *GORMFeedRepository) Audience(ctx context.Context, author int64) ([]int64, error) {
+	return r.dao.Audience(ctx, feedBiz, author)
+}
+
+func (r *GORMFeedRepository) SetAudience(ctx context.Context, author int64, audience int64) error {
+	return r.dao.SetAudience(ctx, author, audience)
+}
+
+func (r *GORMFeedRepository) Push(ctx context.Context, aid int64, t time.Time, uids []int64) error {
+	if len(uids) == 0 {
+		return nil
+	}
+	inboxes := make([]dao.FeedInbox, 0, len(uids))
+	for _, uid := range uids {
+		inboxes = append(inboxes, dao.FeedInbox{
+			Uid:   uid,
+			Aid:   aid,
+			Ctime: t.UnixMilli(),
+		})
+	}
+	return r.dao.PushInbox(ctx, inboxes)
+}
+
+func (r *GORMFeedRepository) InboxItems(ctx context.Context, uid int64,
+	cursor domain.ArticleCursor, limit int) ([]domain.FeedItem, error) {
+	cursor = normalizeCursor(cursor)
+	arts, err := r.dao.InboxArticles(ctx, uid, domain.ArticleStatusPublished.ToUint8(),
+		cursor.Time, cursor.Aid, limit)
+	if err != nil {
+		return nil, err
+	}
+	return r.toDomains(arts), nil
+}
+
+func (r *GORMFeedRepository) PullItems(ctx context.Context, uid int64, minAudience int64,
+	cursor domain.ArticleCursor, limit int) ([]domain.FeedItem, error) {
+	cursor = normalizeCursor(cursor)
+	arts, err := r.dao.PullArticles(ctx, feedBiz, uid, minAudience, domain.ArticleStatusPublished.ToUint8(),
+		cursor.Time, cursor.Aid, limit)
+	if err != nil {
+		return nil, err
+	}
+	return r.toDomains(arts), nil
+}
+
+func (r *GORMFeedRepository) toDomains(arts []dao.FeedArticle) []domain.FeedItem {
+	res := make([]domain.FeedItem, 0, len(arts))
+	for _, art := range arts {
+		res = append(res, domain.FeedItem{
+			Article: domain.Article{
+				Id:      art.Id,
+				Title:   art.Title,
+				Content: art.Content,
+				Author: domain.Author{
+					Id: art.AuthorId,
+				},
+				Status: domain.ArticleStatus(art.Status),
+				Ctime:  time.UnixMilli(art.Ctime),
+				Utime:  time.UnixMilli(art.Utime),
+			},
+			Time: time.UnixMilli(art.FeedTime),
+		})
+	}
+	return res
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository/cache"
+	"webook/internal/repository/dao"
+	"webook/pkg/logger"
+)
+
+type FollowRepository interface {
+	AddFollowRelation(ctx context.Context, follower, followee int64) error
+	InactiveFollowRelation(ctx context.Context, follower, followee int64) error
+	// Followed follower 有没有关注 followee
+	Followed(ctx context.Context, follower, followee int64) (bool, error)
+	GetFollowers(ctx context.Context, followee int64, offset, limit int) ([]domain.FollowRelation, error)
+	GetFollowees(ctx context.Context, follower int64, offset, limit int) ([]domain.FollowRelation, error)
+	GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error)
+}
+
+type CachedFollowRepository struct {
+	dao   dao.FollowDAO
+	cache cache.FollowCache
+	log   logger.LoggerV1
+}
+
+func NewFollowRepository(dao dao.FollowDAO, cache cache.FollowCache, l logger.LoggerV1) FollowRepository {
+	return &CachedFollowRepository{
+		dao:   dao,
+		cache: cache,
+		log:   l,
+	}
+}
+
+func (r *CachedFollowRepository) AddFollowRelation(ctx context.Context, follower, followee int64) error {
+	changed, err := r.dao.CreateFollowRelation(ctx, follower, followee)
+	if err != nil || !changed {
+		return err
+	}
+	return r.cache.Follow(ctx, follower, followee)
+}
+
+func (r *CachedFollowRepository) InactiveFollowRelation(ctx context.Context, follower, followee int64) error {
+	changed, err := r.dao.CancelFollowRelation(ctx, follower, followee)
+	if err != nil || !changed {
+		return err
+	}
+	return r.cache.CancelFollow(ctx, follower, followee)
+}
+
+func (r *CachedFollowRepository) Followed(ctx context.Context, follower, followee int64) (bool, error) {
+	_, err := r.dao.FollowRelationDetail(ctx, follower, followee)
+	switch err {
+	case nil:
+		return true, nil
+	case dao.ErrRecordNotFound:
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+func (r *CachedFollowRepository) GetFollowers(ctx context.Context, followee int64, offset, limit int) ([]domain.FollowRelation, error) {
+	rs, err := r.dao.FollowerList(ctx, followee, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return r.toDomains(rs), nil
+}
+
+func (r *CachedFollowRepository) GetFollowees(ctx context.Context, follower int64, offset, limit int) ([]domain.FollowRelation, error) {
+	rs, err := r.dao.FolloweeList(ctx, follower, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return r.toDomains(rs), nil
+}
+
+func (r *CachedFollowRepository) GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error) {
+	res, err := r.cache.GetStatics(ctx, uid)
+	if err == nil {
+		return res, nil
+	}
+	se, err := r.dao.GetStatics(ctx, uid)
+	switch err {
+	case nil:
+		res = domain.FollowStatics{
+			Followers: se.Followers,
+			Followees: se.Followees,
+		}
+	case dao.ErrRecordNotFound:
+		// 没有人关注过，也没有关注过别人，零值也要缓存起来
+	default:
+		return domain.FollowStatics{}, err
+	}
+	err = r.cache.SetStatics(ctx, uid, res)
+	if err != nil {
+		r.log.Error("回写关注数缓存失败",
+			logger.Int64("uid", uid),
+			logger.Error(err))
+	}
+	return res, nil
+}
+
+func (r *CachedFollowRepository) toDomains(rs []dao.FollowRelation) []domain.FollowRelation {
+	res := make([]domain.FollowRelation, 0, len(rs))
+	for _, src := range rs {
+		res = append(res, domain.FollowRelation{
+			Follower: src.Follower,
+			Followee: src.Followee,
+			Ctime:    time.UnixMilli(src.Utime),
+		})
+	}
+	return res
+}
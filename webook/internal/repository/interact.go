@@ -15,6 +15,8 @@ type InteractiveRepository interface {
 	DecrLike(ctx context.Context, biz string, aid int64, uid int64) error
 	AddCollectionItem(ctx context.Context, biz string, aid int64, cid int64, uid int64) error
 	Get(ctx context.Context, biz string, aid int64) (domain.Interactive, error)
+	// GetByIds 列表页批量查计数，没有记录的不在返回的 map 里面
+	GetByIds(ctx context.Context, biz string, ids []int64) (map[int64]domain.Interactive, error)
 	Liked(ctx context.Context, biz string, aid int64, uid int64) (bool, error)
 	Collected(ctx context.Context, biz string, id int64, uid int64) (bool, error)
 }
@@ -69,6 +71,22 @@ func (c *CachedInteractiveRepository) Get(ctx context.Context, biz string, id in
 	return intr, err
 }
 
+func (c *CachedInteractiveRepository) GetByIds(ctx context.Context, biz string, ids []int64) (map[int64]domain.Interactive, error) {
+	if len(ids) == 0 {
+		return map[int64]domain.Interactive{}, nil
+	}
+	// 一条 SQL 查完，比挨个查缓存的网络开销小
+	ies, err := c.dao.GetByIds(ctx, biz, ids)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int64]domain.Interactive, len(ies))
+	for _, ie := range ies {
+		res[ie.BizId] = c.toDomain(ie)
+	}
+	return res, nil
+}
+
 func (c *CachedInteractiveRepository) Liked(ctx context.Context, biz string, id int64, uid int64) (bool, error) {
 
 	_, err := c.dao.GetLikeInfo(ctx, biz, id, uid)
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"webook/internal/domain"
+	"webook/internal/repository/cache"
+)
+
+type LoginAttemptRepository interface {
+	Get(ctx context.Context, email, ip string) (domain.LoginAttempts, error)
+	RecordFailure(ctx context.Context, email, ip string) (domain.LoginAttempts, error)
+	Reset(ctx context.Context, email string) error
+	Unlock(ctx context.Context, email string) error
+}
+
+type CachedLoginAttemptRepository struct {
+	cache cache.LoginAttemptCache
+}
+
+func NewLoginAttemptRepository(c cache.LoginAttemptCache) LoginAttemptRepository {
+	return &CachedLoginAttemptRepository{
+		cache: c,
+	}
+}
+
+func (repo *CachedLoginAttemptRepository) Get(ctx context.Context, email, ip string) (domain.LoginAttempts, error) {
+	return repo.cache.Get(ctx, email, ip)
+}
+
+func (repo *CachedLoginAttemptRepository) RecordFailure(ctx context.Context, email, ip string) (domain.LoginAttempts, error) {
+	return repo.cache.RecordFailure(ctx, email, ip)
+}
+
+func (repo *CachedLoginAttemptRepository) Reset(ctx context.Context, email string) error {
+	return repo.cache.Reset(ctx, email)
+}
+
+func (repo *CachedLoginAttemptRepository) Unlock(ctx context.Context, email string) error {
+	return repo.cache.Unlock(ctx, email)
+}
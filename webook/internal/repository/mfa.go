@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"webook/internal/domain"
+	"webook/internal/repository/cache"
+	"webook/internal/repository/dao"
+)
+
+var (
+	ErrMFANotFound          = dao.ErrRecordNotFound
+	ErrRecoveryCodeNotFound = dao.ErrRecoveryCodeNotFound
+)
+
+type MFARepository interface {
+	FindByUid(ctx context.Context, uid int64) (domain.MFA, error)
+	SavePending(ctx context.Context, uid int64, secret string) error
+	Enable(ctx context.Context, uid int64, codeHashes []string) error
+	UseRecoveryCode(ctx context.Context, uid int64, codeHash string) error
+
+	Failures(ctx context.Context, uid int64) (int, error)
+	RecordFailure(ctx context.Context, uid int64) (int, error)
+	ResetFailures(ctx context.Context, uid int64) error
+	UseStep(ctx context.Context, uid int64, step int64) (bool, error)
+}
+
+type CachedMFARepository struct {
+	dao   dao.MFADAO
+	cache cache.MFACache
+}
+
+func NewMFARepository(dao dao.MFADAO, c cache.MFACache) MFARepository {
+	return &CachedMFARepository{
+		dao:   dao,
+		cache: c,
+	}
+}
+
+func (repo *CachedMFARepository) FindByUid(ctx context.Context, uid int64) (domain.MFA, error) {
+	m, err := repo.dao.FindByUid(ctx, uid)
+	if err != nil {
+		return domain.MFA{}, err
+	}
+	return domain.MFA{
+		Uid:     m.Uid,
+		Secret:  m.Secret,
+		Enabled: m.Enabled,
+	}, nil
+}
+
+func (repo *CachedMFARepository) SavePending(ctx context.Context, uid int64, secret string) error {
+	return repo.dao.Upsert(ctx, dao.UserMFA{
+		Uid:    uid,
+		Secret: secret,
+	})
+}
+
+func (repo *CachedMFARepository) Enable(ctx context.Context, uid int64, codeHashes []string) error {
+	return repo.dao.Enable(ctx, uid, codeHashes)
+}
+
+func (repo *CachedMFARepository) UseRecoveryCode(ctx context.Context, uid int64, codeHash string) error {
+	return repo.dao.UseRecoveryCode(ctx, uid, codeHash)
+}
+
+func (repo *CachedMFARepository) Failures(ctx context.Context, uid int64) (int, error) {
+	return repo.cache.Failures(ctx, uid)
+}
+
+func (repo *CachedMFARepository) RecordFailure(ctx context.Context, uid int64) (int, error) {
+	return repo.cache.RecordFailure(ctx, uid)
+}
+
+func (repo *CachedMFARepository) ResetFailures(ctx context.Context, uid int64) error {
+	return repo.cache.ResetFailures(ctx, uid)
+}
+
+func (repo *CachedMFARepository) UseStep(ctx context.Context, uid int64, step int64) (bool, error) {
+	return repo.cache.UseStep(ctx, uid, step)
+}
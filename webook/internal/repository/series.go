@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository/cache"
+	"webook/internal/repository/dao"
+	"webook/pkg/logger"
+)
+
+var (
+	ErrSeriesNotFound       = dao.ErrRecordNotFound
+	ErrSeriesInvalidArticle = dao.ErrSeriesInvalidArticle
+	ErrArticleInOtherSeries = dao.ErrArticleInOtherSeries
+)
+
+type SeriesRepository interface {
+	Create(ctx context.Context, s domain.Series) (int64, error)
+	Update(ctx context.Context, s domain.Series) error
+	Delete(ctx context.Context, uid int64, sid int64) error
+	// SetArticles 按照 aids 的顺序覆盖系列里面的文章
+	SetArticles(ctx context.Context, uid int64, sid int64, aids []int64) error
+	// GetById 带上按照顺序排好的已发表文章
+	GetById(ctx context.Context, sid int64) (domain.Series, error)
+	// GetByAuthor 不带文章
+	GetByAuthor(ctx context.Context, uid int64, offset int, limit int) ([]domain.Series, error)
+	// GetSid 文章所在的系列，不在任何系列里面返回 0
+	GetSid(ctx context.Context, aid int64) (int64, error)
+	// DelCacheByArticle 文章发表、修改或者撤回之后，删掉它所在系列的缓存
+	DelCacheByArticle(ctx context.Context, aid int64) error
+}
+
+type CachedSeriesRepository struct {
+	dao   dao.SeriesDAO
+	cache cache.SeriesCache
+	log   logger.LoggerV1
+}
+
+func NewSeriesRepository(dao dao.SeriesDAO, cache cache.SeriesCache, l logger.LoggerV1) SeriesRepository {
+	return &CachedSeriesRepository{
+		dao:   dao,
+		cache: cache,
+		log:   l,
+	}
+}
+
+func (r *CachedSeriesRepository) Create(ctx context.Context, s domain.Series) (int64, error) {
+	return r.dao.Insert(ctx, r.toEntity(s))
+}
+
+func (r *CachedSeriesRepository) Update(ctx context.Context, s domain.Series) error {
+	err := r.dao.Update(ctx, r.toEntity(s))
+	if err != nil {
+		return err
+	}
+	return r.cache.Del(ctx, s.Id)
+}
+
+func (r *CachedSeriesRepository) Delete(ctx context.Context, uid int64, sid int64) error {
+	aids, err := r.dao.Delete(ctx, uid, sid)
+	if err != nil {
+		return err
+	}
+	r.delCache(ctx, sid, aids)
+	return nil
+}
+
+func (r *CachedSeriesRepository) SetArticles(ctx context.Context, uid int64, sid int64, aids []int64) error {
+	old, err := r.dao.SetArticles(ctx, uid, sid, aids, domain.ArticleStatusPublished.ToUint8())
+	if err != nil {
+		return err
+	}
+	// 移出去的和加进来的文章，所在的系列都变了
+	r.delCache(ctx, sid, append(old, aids...))
+	return nil
+}
+
+func (r *CachedSeriesRepository) delCache(ctx context.Context, sid int64, aids []int64) {
+	err := r.cache.Del(ctx, sid)
+	if err != nil {
+		r.log.Error("删除系列缓存失败",
+			logger.Int64("sid", sid),
+			logger.Error(err))
+	}
+	err = r.cache.DelSid(ctx, aids...)
+	if err != nil {
+		r.log.Error("删除文章所在系列的缓存失败",
+			logger.Int64("sid", sid),
+			logger.Error(err))
+	}
+}
+
+func (r *CachedSeriesRepository) GetById(ctx context.Context, sid int64) (domain.Series, error) {
+	res, err := r.cache.Get(ctx, sid)
+	if err == nil {
+		return res, nil
+	}
+	s, err := r.dao.GetById(ctx, sid)
+	if err != nil {
+		return domain.Series{}, err
+	}
+	// 撤回了的文章不出现在系列里面
+	arts, err := r.dao.GetArticles(ctx, sid, domain.ArticleStatusPublished.ToUint8())
+	if err != nil {
+		return domain.Series{}, err
+	}
+	res = r.toDomain(s)
+	res.Articles = make([]domain.SeriesArticle, 0, len(arts))
+	for _, art := range arts {
+		res.Articles = append(res.Articles, domain.SeriesArticle{
+			Id:    art.Id,
+			Title: art.Title,
+		})
+	}
+	err = r.cache.Set(ctx, res)
+	if err != nil {
+		r.log.Error("回写系列缓存失败",
+			logger.Int64("sid", sid),
+			logger.Error(err))
+	}
+	return res, nil
+}
+
+func (r *CachedSeriesRepository) GetByAuthor(ctx context.Context, uid int64, offset int, limit int) ([]domain.Series, error) {
+	ss, err := r.dao.GetByAuthor(ctx, uid, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.Series, 0, len(ss))
+	for _, s := range ss {
+		res = append(res, r.toDomain(s))
+	}
+	return res, nil
+}
+
+func (r *CachedSeriesRepository) GetSid(ctx context.Context, aid int64) (int64, error) {
+	sid, err := r.cache.GetSid(ctx, aid)
+	if err == nil {
+		return sid, nil
+	}
+	sid, err = r.dao.GetSid(ctx, aid)
+	switch err {
+	case nil:
+	case dao.ErrRecordNotFound:
+		sid = 0
+	default:
+		return 0, err
+	}
+	err = r.cache.SetSid(ctx, aid, sid)
+	if err != nil {
+		r.log.Error("回写文章所在系列的缓存失败",
+			logger.Int64("aid", aid),
+			logger.Error(err))
+	}
+	return sid, nil
+}
+
+func (r *CachedSeriesRepository) DelCacheByArticle(ctx context.Context, aid int64) error {
+	sid, err := r.GetSid(ctx, aid)
+	if err != nil || sid == 0 {
+		return err
+	}
+	return r.cache.Del(ctx, sid)
+}
+
+func (r *CachedSeriesRepository) toEntity(s domain.Series) dao.Series {
+	return dao.Series{
+		Id:          s.Id,
+		AuthorId:    s.AuthorId,
+		Title:       s.Title,
+		Description: s.Description,
+	}
+}
+
+func (r *CachedSeriesRepository) toDomain(s dao.Series) domain.Series {
+	return domain.Series{
+		Id:          s.Id,
+		AuthorId:    s.AuthorId,
+		Title:       s.Title,
+		Description: s.Description,
+		Ctime:       time.UnixMilli(s.Ctime),
+		Utime:       time.UnixMilli(s.Utime),
+	}
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"webook/internal/repository/cache"
+)
+
+var ErrTokenInvalid = cache.ErrTokenInvalid
+
+// TokenRepository 一次性令牌，biz 区分不同的业务
+type TokenRepository interface {
+	Store(ctx context.Context, biz string, uid int64, tokenId string, expiration time.Duration) error
+	// Check 只校验，不作废，后面还要再 Consume
+	Check(ctx context.Context, biz string, uid int64, tokenId string) error
+	Consume(ctx context.Context, biz string, uid int64, tokenId string) error
+}
+
+type CachedTokenRepository struct {
+	cache cache.TokenCache
+}
+
+func NewTokenRepository(c cache.TokenCache) TokenRepository {
+	return &CachedTokenRepository{
+		cache: c,
+	}
+}
+
+func (repo *CachedTokenRepository) Store(ctx context.Context, biz string, uid int64, tokenId string, expiration time.Duration) error {
+	return repo.cache.Set(ctx, biz, uid, tokenId, expiration)
+}
+
+func (repo *CachedTokenRepository) Check(ctx context.Context, biz string, uid int64, tokenId string) error {
+	return repo.cache.Check(ctx, biz, uid, tokenId)
+}
+
+func (repo *CachedTokenRepository) Consume(ctx context.Context, biz string, uid int64, tokenId string) error {
+	return repo.cache.Consume(ctx, biz, uid, tokenId)
+}
@@ -2,6 +2,10 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"github.com/ecodeclub/ekit/slice"
+	"strings"
+	"time"
 	"webook/internal/domain"
 	"webook/internal/repository/cache"
 	"webook/internal/repository/dao"
@@ -16,6 +20,14 @@ type UserRepository interface {
 	Create(ctx context.Context, u domain.User) error
 	FindByEmail(ctx context.Context, email string) (domain.User, error)
 	FindById(ctx context.Context, id int64) (domain.User, error)
+	FindByPhone(ctx context.Context, phone string) (domain.User, error)
+	FindByWechat(ctx context.Context, openId string) (domain.User, error)
+	UpdateNonZeroFields(ctx context.Context, u domain.User) error
+	// SetDeleteAfter 零值表示撤销注销
+	SetDeleteAfter(ctx context.Context, id int64, t time.Time) error
+	// FindDueDeletion 找出注销冷静期已经过了的用户
+	FindDueDeletion(ctx context.Context, now time.Time, limit int) ([]domain.User, error)
+	UpdateRoles(ctx context.Context, id int64, roles []domain.Role) error
 }
 
 type CacheUserRepository struct {
@@ -31,10 +43,7 @@ func NewUserRepository(dao dao.UserDAO, c cache.UserCache) UserRepository {
 }
 
 func (r *CacheUserRepository) Create(ctx context.Context, u domain.User) error {
-	return r.dao.Insert(ctx, dao.User{
-		Email:    u.Email,
-		Password: u.Password,
-	})
+	return r.dao.Insert(ctx, r.toEntity(u))
 }
 
 func (r *CacheUserRepository) FindByEmail(ctx context.Context, email string) (domain.User, error) {
@@ -44,11 +53,23 @@ func (r *CacheUserRepository) FindByEmail(ctx context.Context, email string) (do
 	if err != nil {
 		return domain.User{}, err
 	}
-	return domain.User{
-		Id:       u.Id,
-		Email:    u.Email,
-		Password: u.Password,
-	}, nil
+	return r.toDomain(u), nil
+}
+
+func (r *CacheUserRepository) FindByPhone(ctx context.Context, phone string) (domain.User, error) {
+	u, err := r.dao.FindByPhone(ctx, phone)
+	if err != nil {
+		return domain.User{}, err
+	}
+	return r.toDomain(u), nil
+}
+
+func (r *CacheUserRepository) FindByWechat(ctx context.Context, openId string) (domain.User, error) {
+	u, err := r.dao.FindByWechat(ctx, openId)
+	if err != nil {
+		return domain.User{}, err
+	}
+	return r.toDomain(u), nil
 }
 
 func (r *CacheUserRepository) FindById(ctx context.Context, id int64) (domain.User, error) {
@@ -67,11 +88,7 @@ func (r *CacheUserRepository) FindById(ctx context.Context, id int64) (domain.Us
 		return domain.User{}, err
 	}
 
-	u = domain.User{
-		Id:       ue.Id,
-		Email:    ue.Email,
-		Password: ue.Password,
-	}
+	u = r.toDomain(ue)
 
 	//go func() {
 	//	err = r.cache.Set(ctx, u)
@@ -88,7 +105,119 @@ func (r *CacheUserRepository) FindById(ctx context.Context, id int64) (domain.Us
 	//缓存出错
 }
 
-//func (repo *CachedUserRepository) UpdateNonZeroFields(ctx context.Context,
-//	user domain.User) error {
-//	return repo.dao.UpdateById(ctx, repo.toEntity(user))
-//}
+func (r *CacheUserRepository) UpdateNonZeroFields(ctx context.Context, u domain.User) error {
+	err := r.dao.UpdateNonZeroFields(ctx, r.toEntity(u))
+	if err != nil {
+		return err
+	}
+	// 先更新数据库再删缓存，下次读的时候重新加载
+	return r.cache.Del(ctx, u.Id)
+}
+
+func (r *CacheUserRepository) SetDeleteAfter(ctx context.Context, id int64, t time.Time) error {
+	var deleteAfter int64
+	if !t.IsZero() {
+		deleteAfter = t.UnixMilli()
+	}
+	err := r.dao.SetDeleteAfter(ctx, id, deleteAfter)
+	if err != nil {
+		return err
+	}
+	return r.cache.Del(ctx, id)
+}
+
+func (r *CacheUserRepository) FindDueDeletion(ctx context.Context, now time.Time, limit int) ([]domain.User, error) {
+	us, err := r.dao.FindDueDeletion(ctx, now.UnixMilli(), limit)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]domain.User, 0, len(us))
+	for _, u := range us {
+		res = append(res, r.toDomain(u))
+	}
+	return res, nil
+}
+
+func (r *CacheUserRepository) UpdateRoles(ctx context.Context, id int64, roles []domain.Role) error {
+	err := r.dao.UpdateRoles(ctx, id, r.joinRoles(roles))
+	if err != nil {
+		return err
+	}
+	return r.cache.Del(ctx, id)
+}
+
+func (r *CacheUserRepository) joinRoles(roles []domain.Role) string {
+	return strings.Join(slice.Map(roles, func(idx int, src domain.Role) string {
+		return src.String()
+	}), ",")
+}
+
+func (r *CacheUserRepository) splitRoles(roles string) []domain.Role {
+	// 老数据没有这一列
+	if roles == "" {
+		return []domain.Role{domain.RoleReader}
+	}
+	return slice.Map(strings.Split(roles, ","), func(idx int, src string) domain.Role {
+		return domain.Role(src)
+	})
+}
+
+func (r *CacheUserRepository) toDomain(u dao.User) domain.User {
+	var birthday, deleteAfter time.Time
+	if u.Birthday > 0 {
+		birthday = time.UnixMilli(u.Birthday)
+	}
+	if u.DeleteAfter > 0 {
+		deleteAfter = time.UnixMilli(u.DeleteAfter)
+	}
+	return domain.User{
+		Id:            u.Id,
+		Email:         u.Email.String,
+		EmailVerified: u.EmailVerified,
+		Password:      u.Password,
+		Phone:         u.Phone.String,
+		WechatInfo: domain.WechatInfo{
+			OpenId:  u.WechatOpenId.String,
+			UnionId: u.WechatUnionId.String,
+		},
+		Roles:       r.splitRoles(u.Roles),
+		Nickname:    u.Nickname,
+		Birthday:    birthday,
+		AboutMe:     u.AboutMe,
+		Ctime:       time.UnixMilli(u.Ctime),
+		DeleteAfter: deleteAfter,
+		Purged:      u.Purged,
+	}
+}
+
+func (r *CacheUserRepository) toEntity(u domain.User) dao.User {
+	var birthday int64
+	if !u.Birthday.IsZero() {
+		birthday = u.Birthday.UnixMilli()
+	}
+	return dao.User{
+		Id: u.Id,
+		Email: sql.NullString{
+			String: u.Email,
+			Valid:  u.Email != "",
+		},
+		EmailVerified: u.EmailVerified,
+		Password:      u.Password,
+		Phone: sql.NullString{
+			String: u.Phone,
+			Valid:  u.Phone != "",
+		},
+		WechatOpenId: sql.NullString{
+			String: u.WechatInfo.OpenId,
+			Valid:  u.WechatInfo.OpenId != "",
+		},
+		WechatUnionId: sql.NullString{
+			String: u.WechatInfo.UnionId,
+			Valid:  u.WechatInfo.UnionId != "",
+		},
+		Roles:    r.joinRoles(u.Roles),
+		Nickname: u.Nickname,
+		Birthday: birthday,
+		AboutMe:  u.AboutMe,
+	}
+}
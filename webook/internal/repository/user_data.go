@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository/cache"
+	"webook/internal/repository/dao"
+)
+
+// 导出的时候每次从数据库捞多少条
+const userDataBatchSize = 100
+
+// UserDataRepository 用户个人数据的导出和清除，跨了用户、文章、互动几块数据
+type UserDataRepository interface {
+	Export(ctx context.Context, uid int64) (domain.UserDataExport, error)
+	Purge(ctx context.Context, uid int64) error
+}
+
+type CachedUserDataRepository struct {
+	dao         dao.UserDataDAO
+	userRepo    UserRepository
+	userCache   cache.UserCache
+	artCache    cache.ArticleCache
+	followCache cache.FollowCache
+	blockCache  cache.BlockCache
+}
+
+func NewUserDataRepository(dao dao.UserDataDAO, userRepo UserRepository,
+	userCache cache.UserCache, artCache cache.ArticleCache,
+	followCache cache.FollowCache, blockCache cache.BlockCache) UserDataRepository {
+	return &CachedUserDataRepository{
+		dao:         dao,
+		userRepo:    userRepo,
+		userCache:   userCache,
+		artCache:    artCache,
+		followCache: followCache,
+		blockCache:  blockCache,
+	}
+}
+
+func (r *CachedUserDataRepository) Export(ctx context.Context, uid int64) (domain.UserDataExport, error) {
+	var res domain.UserDataExport
+	u, err := r.userRepo.FindById(ctx, uid)
+	if err != nil {
+		return res, err
+	}
+	res.Profile = u
+
+	res.Articles, err = exportAll(ctx, uid, r.dao.ArticlesByAuthor, r.articleToDomain)
+	if err != nil {
+		return res, err
+	}
+	res.PublishedArticles, err = exportAll(ctx, uid, r.dao.PublishedByAuthor, func(art dao.PublishedArticle) domain.Article {
+		return r.articleToDomain(dao.Article(art))
+	})
+	if err != nil {
+		return res, err
+	}
+	res.Likes, err = exportAll(ctx, uid, r.dao.LikesByUid, func(l dao.UserLikeBiz) domain.UserBiz {
+		return domain.UserBiz{
+			Biz:   l.Biz,
+			BizId: l.BizId,
+			Ctime: time.UnixMilli(l.Ctime),
+		}
+	})
+	if err != nil {
+		return res, err
+	}
+	res.Collections, err = exportAll(ctx, uid, r.dao.CollectionsByUid, func(c dao.UserCollectionBiz) domain.UserBiz {
+		return domain.UserBiz{
+			Biz:   c.Biz,
+			BizId: c.BizId,
+			Cid:   c.Cid,
+			Ctime: time.UnixMilli(c.Ctime),
+		}
+	})
+	return res, err
+}
+
+func (r *CachedUserDataRepository) Purge(ctx context.Context, uid int64) error {
+	res, err := r.dao.Purge(ctx, uid)
+	if err != nil {
+		return err
+	}
+	// 数据库已经清掉了，缓存删不掉也会自己过期
+	_ = r.userCache.Del(ctx, uid)
+	_ = r.artCache.DelFirstPage(ctx, uid)
+	_ = r.artCache.DelPubFirstPage(ctx, uid)
+	_ = r.artCache.Del(ctx, res.Aids...)
+	_ = r.followCache.DelStatics(ctx, append(res.FollowUids, uid)...)
+	_ = r.blockCache.Del(ctx, uid)
+	return nil
+}
+
+func (r *CachedUserDataRepository) articleToDomain(art dao.Article) domain.Article {
+	return domain.Article{
+		Id:      art.Id,
+		Title:   art.Title,
+		Content: art.Content,
+		Author: domain.Author{
+			Id: art.AuthorId,
+		},
+		Ctime:  time.UnixMilli(art.Ctime),
+		Utime:  time.UnixMilli(art.Utime),
+		Status: domain.ArticleStatus(art.Status),
+	}
+}
+
+// exportAll 分批把某个用户的数据全部捞出来
+func exportAll[T any, D any](ctx context.Context, uid int64,
+	find func(ctx context.Context, uid int64, offset, limit int) ([]T, error),
+	toDomain func(T) D) ([]D, error) {
+	res := make([]D, 0)
+	for offset := 0; ; offset += userDataBatchSize {
+		batch, err := find(ctx, uid, offset, userDataBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, src := range batch {
+			res = append(res, toDomain(src))
+		}
+		if len(batch) < userDataBatchSize {
+			return res, nil
+		}
+	}
+}
@@ -2,19 +2,43 @@ package service
 
 import (
 	"context"
+	"time"
 	"webook/internal/domain"
 	"webook/internal/event"
 	"webook/internal/repository"
 	"webook/pkg/logger"
 )
 
+// ErrArticleConflict 保存的时候带的版本号不是最新的，说明别的地方先改过了
+var ErrArticleConflict = repository.ErrArticleConflict
+
 type ArticleService interface {
 	Save(ctx context.Context, art domain.Article) (int64, error)
 	Publish(ctx context.Context, art domain.Article) (int64, error)
 	Withdraw(ctx context.Context, art domain.Article) error
-	GetByAuthor(ctx context.Context, uid int64, offset int, limit int) ([]domain.Article, error)
+	GetByAuthor(ctx context.Context, uid int64, filter domain.ArticleFilter,
+		cursor domain.ArticleCursor, limit int) ([]domain.Article, error)
 	GetById(ctx context.Context, id int64) (domain.Article, error)
 	GetPubById(ctx context.Context, aid int64, uid int64) (domain.Article, error)
+	// ListPub 最新发表的文章，uid 是读者，会过滤掉他拉黑的作者，没登录传 0
+	ListPub(ctx context.Context, uid int64, cursor domain.ArticleCursor, limit int) ([]domain.Article, error)
+
+	// ListRevisions 只有作者自己能看，不带内容
+	ListRevisions(ctx context.Context, uid int64, aid int64, offset int, limit int) ([]domain.ArticleRevision, error)
+	DiffRevisions(ctx context.Context, uid int64, aid int64, from, to int64) (domain.RevisionDiff, error)
+	// RestoreRevision 用历史版本覆盖草稿，覆盖本身也会产生一个新版本
+	RestoreRevision(ctx context.Context, uid int64, aid int64, version int64) error
+
+	// ListScheduled 作者还没发表出去的定时文章
+	ListScheduled(ctx context.Context, uid int64, offset int, limit int) ([]domain.Article, error)
+	CancelSchedule(ctx context.Context, uid int64, aid int64) error
+	// PublishDue 把 cursor 后面一批到点的定时文章发表出去，返回下一批的 cursor，
+	// 零值表示已经翻到头了
+	PublishDue(ctx context.Context, cursor domain.ArticleCursor, limit int) (domain.ArticleCursor, error)
+
+	// ListPubByTag 带这个标签的已发表文章，还有一共多少篇
+	// uid 是读者，会过滤掉他拉黑的作者，没登录传 0
+	ListPubByTag(ctx context.Context, uid int64, tag string, offset int, limit int) ([]domain.Article, int64, error)
 
 	//PublishV1(ctx context.Context, art domain.Article) (int64, error)
 }
@@ -30,32 +54,70 @@ type articleService struct {
 	//readRepo repository.ArticleReaderRepository
 }
 
-func NewArticleService(repo repository.ArticleRepository) ArticleService {
+func NewArticleService(repo repository.ArticleRepository, producer event.Producer, log logger.LoggerV1) ArticleService {
 	return &articleService{
-		repo: repo,
+		repo:     repo,
+		producer: producer,
+		log:      log,
 	}
 }
 
 func (a *articleService) Save(ctx context.Context, art domain.Article) (int64, error) {
 	art.Status = domain.ArticleStatusUnpublished
+	// 改成草稿就不再定时发表了
+	art.PublishAt = time.Time{}
+	return a.save(ctx, art)
+}
+
+func (a *articleService) save(ctx context.Context, art domain.Article) (int64, error) {
 	if art.Id > 0 {
 		err := a.repo.Update(ctx, art)
+		if err == nil {
+			a.pruneRevisions(ctx, art.Id)
+		}
 		return art.Id, err
 	}
 	return a.repo.Create(ctx, art)
 }
 
+// Publish 带了将来的 PublishAt 就是定时发表，先存成草稿，到点了再发
 func (a *articleService) Publish(ctx context.Context, art domain.Article) (int64, error) {
+	if art.PublishAt.After(time.Now()) {
+		art.Status = domain.ArticleStatusScheduled
+		return a.save(ctx, art)
+	}
 	art.Status = domain.ArticleStatusPublished
-	return a.repo.Sync(ctx, art)
+	art.PublishAt = time.Time{}
+	id, err := a.repo.Sync(ctx, art)
+	if err != nil {
+		return id, err
+	}
+	a.afterPublish(ctx, id, art.Author.Id)
+	return id, nil
+}
+
+func (a *articleService) afterPublish(ctx context.Context, aid int64, uid int64) {
+	a.pruneRevisions(ctx, aid)
+	// 推信息流失败了不影响发表
+	er := a.producer.ProducePublishedEvent(event.PublishedEvent{
+		Aid:   aid,
+		Uid:   uid,
+		Ctime: time.Now().UnixMilli(),
+	})
+	if er != nil {
+		a.log.Error("发送 PublishedEvent 失败",
+			logger.Int64("aid", aid),
+			logger.Error(er))
+	}
 }
 
 func (a *articleService) Withdraw(ctx context.Context, art domain.Article) error {
-	return a.repo.SyncStatus(ctx, art.Id, art.Author.Id, domain.ArticleStatusPrivate)
+	return a.repo.SyncStatus(ctx, art.Author.Id, art.Id, domain.ArticleStatusPrivate)
 }
 
-func (a *articleService) GetByAuthor(ctx context.Context, uid int64, offset int, limit int) ([]domain.Article, error) {
-	return a.repo.GetByAuthor(ctx, uid, offset, limit)
+func (a *articleService) GetByAuthor(ctx context.Context, uid int64, filter domain.ArticleFilter,
+	cursor domain.ArticleCursor, limit int) ([]domain.Article, error) {
+	return a.repo.GetByAuthor(ctx, uid, filter, cursor, limit)
 }
 
 func (a *articleService) GetById(ctx context.Context, id int64) (domain.Article, error) {
@@ -84,6 +146,10 @@ func (a *articleService) GetPubById(ctx context.Context, aid int64, uid int64) (
 
 }
 
+func (a *articleService) ListPub(ctx context.Context, uid int64, cursor domain.ArticleCursor, limit int) ([]domain.Article, error) {
+	return a.repo.ListPub(ctx, uid, cursor, limit)
+}
+
 //func (a *articleService) PublishV1(ctx context.Context, art domain.Article) (int64, error) {
 //	var id = art.Id
 //	var err error
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"github.com/pmezard/go-difflib/difflib"
+	"strings"
+	"webook/internal/domain"
+	"webook/internal/repository"
+	"webook/pkg/logger"
+)
+
+// 每篇文章最多保留这么多个历史版本，更早的保存的时候顺便删掉
+const revisionRetention = 50
+
+var ErrRevisionNotFound = repository.ErrRevisionNotFound
+
+func (a *articleService) ListRevisions(ctx context.Context, uid int64, aid int64,
+	offset int, limit int) ([]domain.ArticleRevision, error) {
+	return a.repo.GetRevisions(ctx, uid, aid, offset, limit)
+}
+
+func (a *articleService) DiffRevisions(ctx context.Context, uid int64, aid int64,
+	from, to int64) (domain.RevisionDiff, error) {
+	fromRev, err := a.repo.GetRevision(ctx, uid, aid, from)
+	if err != nil {
+		return domain.RevisionDiff{}, err
+	}
+	toRev, err := a.repo.GetRevision(ctx, uid, aid, to)
+	if err != nil {
+		return domain.RevisionDiff{}, err
+	}
+	return domain.RevisionDiff{
+		From:  fromRev,
+		To:    toRev,
+		Lines: diffLines(fromRev.Content, toRev.Content),
+	}, nil
+}
+
+func (a *articleService) RestoreRevision(ctx context.Context, uid int64, aid int64, version int64) error {
+	rev, err := a.repo.GetRevision(ctx, uid, aid, version)
+	if err != nil {
+		return err
+	}
+	// 覆盖当前最新的草稿，这中间别的地方又改了的话就是冲突
+	cur, err := a.repo.GetByID(ctx, aid)
+	if err != nil {
+		return err
+	}
+	art := domain.Article{
+		Id:      aid,
+		Title:   rev.Title,
+		Content: rev.Content,
+		Author: domain.Author{
+			Id: uid,
+		},
+		Version: cur.Version,
+		// 历史版本里面没有标签和分类，保持现在的
+		Tags:       cur.Tags,
+		CategoryId: cur.CategoryId,
+	}
+	if cur.Status == domain.ArticleStatusScheduled {
+		// 定时发表的文章恢复了内容还是到点发表，不能悄悄变成草稿
+		art.Status = cur.Status
+		art.PublishAt = cur.PublishAt
+		_, err = a.save(ctx, art)
+		return err
+	}
+	_, err = a.Save(ctx, art)
+	return err
+}
+
+// pruneRevisions 删不掉也没关系，下次保存还会再删
+func (a *articleService) pruneRevisions(ctx context.Context, aid int64) {
+	err := a.repo.PruneRevisions(ctx, aid, revisionRetention)
+	if err != nil {
+		a.log.Error("清理历史版本失败",
+			logger.Int64("aid", aid),
+			logger.Error(err))
+	}
+}
+
+func diffLines(from, to string) []domain.DiffLine {
+	a, b := strings.Split(from, "\n"), strings.Split(to, "\n")
+	// 文章里面空行很多，不能把高频的行当成垃圾跳过
+	m := difflib.NewMatcherWithJunk(a, b, false, nil)
+	res := make([]domain.DiffLine, 0, len(a)+len(b))
+	for _, op := range m.GetOpCodes() {
+		switch op.Tag {
+		case 'e':
+			res = appendLines(res, domain.DiffOpEqual, a[op.I1:op.I2])
+		case 'd':
+			res = appendLines(res, domain.DiffOpDelete, a[op.I1:op.I2])
+		case 'i':
+			res = appendLines(res, domain.DiffOpInsert, b[op.J1:op.J2])
+		case 'r':
+			res = appendLines(res, domain.DiffOpDelete, a[op.I1:op.I2])
+			res = appendLines(res, domain.DiffOpInsert, b[op.J1:op.J2])
+		}
+	}
+	return res
+}
+
+func appendLines(res []domain.DiffLine, op domain.DiffOp, lines []string) []domain.DiffLine {
+	for _, line := range lines {
+		res = append(res, domain.DiffLine{Op: op, Text: line})
+	}
+	return res
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository"
+	repov1mocks "webook/internal/repository/mocks"
+	"webook/pkg/logger"
+)
+
+func Test_articleService_DiffRevisions(t *testing.T) {
+	testCases := []struct {
+		name      string
+		mock      func(ctrl *gomock.Controller) repository.ArticleRepository
+		wantLines []domain.DiffLine
+		wantErr   error
+	}{
+		{
+			name: "diff",
+			mock: func(ctrl *gomock.Controller) repository.ArticleRepository {
+				repo := repov1mocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().GetRevision(gomock.Any(), int64(123), int64(1), int64(2)).
+					Return(domain.ArticleRevision{Aid: 1, Version: 2, Content: "a\nb\nc"}, nil)
+				repo.EXPECT().GetRevision(gomock.Any(), int64(123), int64(1), int64(5)).
+					Return(domain.ArticleRevision{Aid: 1, Version: 5, Content: "a\nB\nc\nd"}, nil)
+				return repo
+			},
+			wantLines: []domain.DiffLine{
+				{Op: domain.DiffOpEqual, Text: "a"},
+				{Op: domain.DiffOpDelete, Text: "b"},
+				{Op: domain.DiffOpInsert, Text: "B"},
+				{Op: domain.DiffOpEqual, Text: "c"},
+				{Op: domain.DiffOpInsert, Text: "d"},
+			},
+		},
+		{
+			name: "revision not found",
+			mock: func(ctrl *gomock.Controller) repository.ArticleRepository {
+				repo := repov1mocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().GetRevision(gomock.Any(), int64(123), int64(1), int64(2)).
+					Return(domain.ArticleRevision{}, repository.ErrRevisionNotFound)
+				return repo
+			},
+			wantErr: ErrRevisionNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewArticleService(tc.mock(ctrl), nil, logger.NewNoOpLogger())
+			diff, err := svc.DiffRevisions(context.Background(), 123, 1, 2, 5)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantLines, diff.Lines)
+		})
+	}
+}
+
+func Test_articleService_RestoreRevision(t *testing.T) {
+	publishAt := time.Now().Add(time.Hour).Truncate(time.Millisecond)
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) repository.ArticleRepository
+		wantErr error
+	}{
+		{
+			name: "恢复成草稿",
+			mock: func(ctrl *gomock.Controller) repository.ArticleRepository {
+				repo := repov1mocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().GetRevision(gomock.Any(), int64(123), int64(1), int64(2)).
+					Return(domain.ArticleRevision{Aid: 1, Version: 2, Title: "旧标题", Content: "旧内容",
+						Status: domain.ArticleStatusPublished}, nil)
+				repo.EXPECT().GetByID(gomock.Any(), int64(1)).
+					Return(domain.Article{Id: 1, Version: 7}, nil)
+				// 恢复成草稿，带上当前的版本号
+				repo.EXPECT().Update(gomock.Any(), domain.Article{
+					Id:      1,
+					Title:   "旧标题",
+					Content: "旧内容",
+					Author:  domain.Author{Id: 123},
+					Status:  domain.ArticleStatusUnpublished,
+					Version: 7,
+				}).Return(nil)
+				repo.EXPECT().PruneRevisions(gomock.Any(), int64(1), revisionRetention).Return(nil)
+				return repo
+			},
+		},
+		{
+			// 定时发表的还是定时发表，时间也不变
+			name: "定时发表的文章",
+			mock: func(ctrl *gomock.Controller) repository.ArticleRepository {
+				repo := repov1mocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().GetRevision(gomock.Any(), int64(123), int64(1), int64(2)).
+					Return(domain.ArticleRevision{Aid: 1, Version: 2, Title: "旧标题", Content: "旧内容",
+						Status: domain.ArticleStatusUnpublished}, nil)
+				repo.EXPECT().GetByID(gomock.Any(), int64(1)).
+					Return(domain.Article{Id: 1, Version: 7,
+						Status: domain.ArticleStatusScheduled, PublishAt: publishAt}, nil)
+				repo.EXPECT().Update(gomock.Any(), domain.Article{
+					Id:        1,
+					Title:     "旧标题",
+					Content:   "旧内容",
+					Author:    domain.Author{Id: 123},
+					Status:    domain.ArticleStatusScheduled,
+					PublishAt: publishAt,
+					Version:   7,
+				}).Return(nil)
+				repo.EXPECT().PruneRevisions(gomock.Any(), int64(1), revisionRetention).Return(nil)
+				return repo
+			},
+		},
+		{
+			name: "版本冲突",
+			mock: func(ctrl *gomock.Controller) repository.ArticleRepository {
+				repo := repov1mocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().GetRevision(gomock.Any(), int64(123), int64(1), int64(2)).
+					Return(domain.ArticleRevision{Aid: 1, Version: 2, Title: "旧标题", Content: "旧内容"}, nil)
+				repo.EXPECT().GetByID(gomock.Any(), int64(1)).
+					Return(domain.Article{Id: 1, Version: 7}, nil)
+				repo.EXPECT().Update(gomock.Any(), gomock.Any()).Return(repository.ErrArticleConflict)
+				return repo
+			},
+			wantErr: ErrArticleConflict,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewArticleService(tc.mock(ctrl), nil, logger.NewNoOpLogger())
+			err := svc.RestoreRevision(context.Background(), 123, 1, 2)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
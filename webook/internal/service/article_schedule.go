@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository"
+	"webook/pkg/logger"
+)
+
+var ErrScheduleNotFound = repository.ErrScheduleNotFound
+
+func (a *articleService) ListScheduled(ctx context.Context, uid int64, offset int, limit int) ([]domain.Article, error) {
+	return a.repo.GetScheduledByAuthor(ctx, uid, offset, limit)
+}
+
+func (a *articleService) CancelSchedule(ctx context.Context, uid int64, aid int64) error {
+	return a.repo.CancelSchedule(ctx, uid, aid)
+}
+
+// PublishDue 多个实例同时跑也不会重复发表：
+// 发表走的是带版本号的 compare-and-set，同一个版本只有一个实例能成功。
+// cursor 按照发表时间正序往后翻，一直发表失败的文章不会挡住后面的
+func (a *articleService) PublishDue(ctx context.Context, cursor domain.ArticleCursor,
+	limit int) (domain.ArticleCursor, error) {
+	arts, err := a.repo.GetDueScheduled(ctx, time.Now(), cursor, limit)
+	if err != nil {
+		return domain.ArticleCursor{}, err
+	}
+	for _, art := range arts {
+		art.Status = domain.ArticleStatusPublished
+		art.PublishAt = time.Time{}
+		id, err := a.repo.Sync(ctx, art)
+		if err == ErrArticleConflict {
+			// 别的实例已经发表了，或者作者刚好改了、取消了
+			continue
+		}
+		if err != nil {
+			// 这一轮翻过去了，下一轮还会再试
+			a.log.Error("定时发表文章失败",
+				logger.Int64("aid", art.Id),
+				logger.Error(err))
+			continue
+		}
+		a.afterPublish(ctx, id, art.Author.Id)
+	}
+	if len(arts) < limit {
+		return domain.ArticleCursor{}, nil
+	}
+	last := arts[len(arts)-1]
+	return domain.ArticleCursor{
+		Time: last.PublishAt.UnixMilli(),
+		Aid:  last.Id,
+	}, nil
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/event"
+	evtmocks "webook/internal/event/mocks"
+	"webook/internal/repository"
+	repov1mocks "webook/internal/repository/mocks"
+	"webook/pkg/logger"
+)
+
+func Test_articleService_PublishScheduled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	publishAt := time.Now().Add(time.Hour)
+	repo := repov1mocks.NewMockArticleRepository(ctrl)
+	// 只是存起来，不同步到线上库，也不推信息流
+	repo.EXPECT().Update(gomock.Any(), domain.Article{
+		Id:        1,
+		Title:     "标题",
+		Author:    domain.Author{Id: 123},
+		Status:    domain.ArticleStatusScheduled,
+		Version:   3,
+		PublishAt: publishAt,
+	}).Return(nil)
+	repo.EXPECT().PruneRevisions(gomock.Any(), int64(1), revisionRetention).Return(nil)
+	svc := NewArticleService(repo, evtmocks.NewMockProducer(ctrl), logger.NewNoOpLogger())
+	id, err := svc.Publish(context.Background(), domain.Article{
+		Id:        1,
+		Title:     "标题",
+		Author:    domain.Author{Id: 123},
+		Version:   3,
+		PublishAt: publishAt,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), id)
+}
+
+func Test_articleService_PublishDue(t *testing.T) {
+	testCases := []struct {
+		name   string
+		mock   func(ctrl *gomock.Controller) (repository.ArticleRepository, event.Producer)
+		cursor domain.ArticleCursor
+		limit  int
+
+		wantCursor domain.ArticleCursor
+		wantErr    error
+	}{
+		{
+			// 不满一批，说明翻到头了
+			name: "发表成功",
+			mock: func(ctrl *gomock.Controller) (repository.ArticleRepository, event.Producer) {
+				repo := repov1mocks.NewMockArticleRepository(ctrl)
+				producer := evtmocks.NewMockProducer(ctrl)
+				repo.EXPECT().GetDueScheduled(gomock.Any(), gomock.Any(), domain.ArticleCursor{}, 10).
+					Return([]domain.Article{
+						{Id: 1, Author: domain.Author{Id: 123}, Status: domain.ArticleStatusScheduled,
+							Version: 2, PublishAt: time.UnixMilli(100)},
+					}, nil)
+				repo.EXPECT().Sync(gomock.Any(), domain.Article{
+					Id:      1,
+					Author:  domain.Author{Id: 123},
+					Status:  domain.ArticleStatusPublished,
+					Version: 2,
+				}).Return(int64(1), nil)
+				repo.EXPECT().PruneRevisions(gomock.Any(), int64(1), revisionRetention).Return(nil)
+				producer.EXPECT().ProducePublishedEvent(gomock.Any()).Return(nil)
+				return repo, producer
+			},
+			limit: 10,
+		},
+		{
+			// 别的实例已经发表了，跳过
+			name: "版本冲突",
+			mock: func(ctrl *gomock.Controller) (repository.ArticleRepository, event.Producer) {
+				repo := repov1mocks.NewMockArticleRepository(ctrl)
+				producer := evtmocks.NewMockProducer(ctrl)
+				repo.EXPECT().GetDueScheduled(gomock.Any(), gomock.Any(), domain.ArticleCursor{}, 10).
+					Return([]domain.Article{
+						{Id: 1, Author: domain.Author{Id: 123}, Status: domain.ArticleStatusScheduled, Version: 2},
+						{Id: 2, Author: domain.Author{Id: 123}, Status: domain.ArticleStatusScheduled, Version: 5},
+					}, nil)
+				repo.EXPECT().Sync(gomock.Any(), domain.Article{
+					Id:      1,
+					Author:  domain.Author{Id: 123},
+					Status:  domain.ArticleStatusPublished,
+					Version: 2,
+				}).Return(int64(0), repository.ErrArticleConflict)
+				repo.EXPECT().Sync(gomock.Any(), domain.Article{
+					Id:      2,
+					Author:  domain.Author{Id: 123},
+					Status:  domain.ArticleStatusPublished,
+					Version: 5,
+				}).Return(int64(2), nil)
+				repo.EXPECT().PruneRevisions(gomock.Any(), int64(2), revisionRetention).Return(nil)
+				producer.EXPECT().ProducePublishedEvent(gomock.Any()).Return(nil)
+				return repo, producer
+			},
+			limit: 10,
+		},
+		{
+			// 满一批的时候翻到最后一条后面，一直失败的文章不会挡住后面的
+			name: "发表失败翻过去",
+			mock: func(ctrl *gomock.Controller) (repository.ArticleRepository, event.Producer) {
+				repo := repov1mocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().GetDueScheduled(gomock.Any(), gomock.Any(),
+					domain.ArticleCursor{Time: 100, Aid: 3}, 2).
+					Return([]domain.Article{
+						{Id: 4, Author: domain.Author{Id: 123}, Status: domain.ArticleStatusScheduled,
+							Version: 2, PublishAt: time.UnixMilli(100)},
+						{Id: 1, Author: domain.Author{Id: 123}, Status: domain.ArticleStatusScheduled,
+							Version: 2, PublishAt: time.UnixMilli(200)},
+					}, nil)
+				repo.EXPECT().Sync(gomock.Any(), gomock.Any()).
+					Return(int64(0), errors.New("db 错误")).Times(2)
+				return repo, evtmocks.NewMockProducer(ctrl)
+			},
+			cursor:     domain.ArticleCursor{Time: 100, Aid: 3},
+			limit:      2,
+			wantCursor: domain.ArticleCursor{Time: 200, Aid: 1},
+		},
+		{
+			name: "查询失败",
+			mock: func(ctrl *gomock.Controller) (repository.ArticleRepository, event.Producer) {
+				repo := repov1mocks.NewMockArticleRepository(ctrl)
+				repo.EXPECT().GetDueScheduled(gomock.Any(), gomock.Any(), domain.ArticleCursor{}, 10).
+					Return(nil, errors.New("db 错误"))
+				return repo, evtmocks.NewMockProducer(ctrl)
+			},
+			limit:   10,
+			wantErr: errors.New("db 错误"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo, producer := tc.mock(ctrl)
+			svc := NewArticleService(repo, producer, logger.NewNoOpLogger())
+			cursor, err := svc.PublishDue(context.Background(), tc.cursor, tc.limit)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantCursor, cursor)
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package service
+
+import (
+	"context"
+	"golang.org/x/sync/errgroup"
+	"webook/internal/domain"
+)
+
+func (a *articleService) ListPubByTag(ctx context.Context, uid int64, tag string,
+	offset int, limit int) ([]domain.Article, int64, error) {
+	var (
+		eg    errgroup.Group
+		arts  []domain.Article
+		total int64
+	)
+	eg.Go(func() error {
+		var er error
+		arts, er = a.repo.GetPubByTag(ctx, uid, tag, offset, limit)
+		return er
+	})
+	eg.Go(func() error {
+		var er error
+		total, er = a.repo.CountPubByTag(ctx, uid, tag)
+		return er
+	})
+	err := eg.Wait()
+	return arts, total, err
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"golang.org/x/sync/errgroup"
+	"webook/internal/domain"
+	"webook/internal/repository"
+)
+
+// AuthorService 作者主页，不需要登录也能看
+type AuthorService interface {
+	Profile(ctx context.Context, uid int64) (domain.AuthorProfile, error)
+	PubArticles(ctx context.Context, uid int64, offset, limit int) ([]domain.Article, error)
+}
+
+type authorService struct {
+	userRepo   repository.UserRepository
+	artRepo    repository.ArticleRepository
+	followRepo repository.FollowRepository
+}
+
+func NewAuthorService(userRepo repository.UserRepository, artRepo repository.ArticleRepository,
+	followRepo repository.FollowRepository) AuthorService {
+	return &authorService{
+		userRepo:   userRepo,
+		artRepo:    artRepo,
+		followRepo: followRepo,
+	}
+}
+
+func (svc *authorService) Profile(ctx context.Context, uid int64) (domain.AuthorProfile, error) {
+	var (
+		eg      errgroup.Group
+		u       domain.User
+		statics domain.FollowStatics
+		cnt     int64
+	)
+	eg.Go(func() error {
+		var er error
+		u, er = svc.userRepo.FindById(ctx, uid)
+		return er
+	})
+	eg.Go(func() error {
+		var er error
+		statics, er = svc.followRepo.GetStatics(ctx, uid)
+		return er
+	})
+	eg.Go(func() error {
+		var er error
+		cnt, er = svc.artRepo.CountPubByAuthor(ctx, uid)
+		return er
+	})
+	if err := eg.Wait(); err != nil {
+		return domain.AuthorProfile{}, err
+	}
+	return domain.AuthorProfile{
+		Id:        u.Id,
+		Nickname:  u.Nickname,
+		AboutMe:   u.AboutMe,
+		Followers: statics.Followers,
+		Followees: statics.Followees,
+		Articles:  cnt,
+	}, nil
+}
+
+func (svc *authorService) PubArticles(ctx context.Context, uid int64, offset, limit int) ([]domain.Article, error) {
+	return svc.artRepo.GetPubByAuthor(ctx, uid, offset, limit)
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"webook/internal/domain"
+	"webook/internal/repository"
+	repov1mocks "webook/internal/repository/mocks"
+)
+
+func Test_authorService_Profile(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (repository.UserRepository,
+			repository.ArticleRepository, repository.FollowRepository)
+		wantProfile domain.AuthorProfile
+		wantErr     error
+	}{
+		{
+			name: "profile",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository,
+				repository.ArticleRepository, repository.FollowRepository) {
+				userRepo := repov1mocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindById(gomock.Any(), int64(123)).Return(domain.User{
+					Id:       123,
+					Email:    "123@qq.com",
+					Nickname: "Tom",
+					AboutMe:  "hello",
+				}, nil)
+				artRepo := repov1mocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().CountPubByAuthor(gomock.Any(), int64(123)).Return(int64(7), nil)
+				followRepo := repov1mocks.NewMockFollowRepository(ctrl)
+				followRepo.EXPECT().GetStatics(gomock.Any(), int64(123)).
+					Return(domain.FollowStatics{Followers: 10, Followees: 2}, nil)
+				return userRepo, artRepo, followRepo
+			},
+			wantProfile: domain.AuthorProfile{
+				Id:        123,
+				Nickname:  "Tom",
+				AboutMe:   "hello",
+				Followers: 10,
+				Followees: 2,
+				Articles:  7,
+			},
+		},
+		{
+			name: "author not found",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository,
+				repository.ArticleRepository, repository.FollowRepository) {
+				userRepo := repov1mocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindById(gomock.Any(), int64(123)).
+					Return(domain.User{}, repository.ErrUserNotFound)
+				artRepo := repov1mocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().CountPubByAuthor(gomock.Any(), int64(123)).Return(int64(0), nil)
+				followRepo := repov1mocks.NewMockFollowRepository(ctrl)
+				followRepo.EXPECT().GetStatics(gomock.Any(), int64(123)).
+					Return(domain.FollowStatics{}, nil)
+				return userRepo, artRepo, followRepo
+			},
+			wantErr: ErrUserNotFound,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewAuthorService(tc.mock(ctrl))
+			p, err := svc.Profile(context.Background(), 123)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantProfile, p)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"webook/internal/domain"
+	"webook/internal/repository"
+)
+
+var (
+	ErrBlockSelf = errors.New("cannot block yourself")
+	// ErrBlocked 被对方拉黑了
+	ErrBlocked = errors.New("blocked by the user")
+)
+
+type BlockService interface {
+	Block(ctx context.Context, blocker, blocked int64) error
+	Unblock(ctx context.Context, blocker, blocked int64) error
+	// Blocked blocker 有没有拉黑 blocked
+	Blocked(ctx context.Context, blocker, blocked int64) (bool, error)
+	GetBlockedList(ctx context.Context, blocker int64, offset, limit int) ([]domain.BlockRelation, error)
+}
+
+type blockService struct {
+	repo     repository.BlockRepository
+	userRepo repository.UserRepository
+}
+
+func NewBlockService(repo repository.BlockRepository, userRepo repository.UserRepository) BlockService {
+	return &blockService{
+		repo:     repo,
+		userRepo: userRepo,
+	}
+}
+
+func (svc *blockService) Block(ctx context.Context, blocker, blocked int64) error {
+	if blocker == blocked {
+		return ErrBlockSelf
+	}
+	_, err := svc.userRepo.FindById(ctx, blocked)
+	if err != nil {
+		return err
+	}
+	return svc.repo.Block(ctx, blocker, blocked)
+}
+
+func (svc *blockService) Unblock(ctx context.Context, blocker, blocked int64) error {
+	return svc.repo.Unblock(ctx, blocker, blocked)
+}
+
+func (svc *blockService) Blocked(ctx context.Context, blocker, blocked int64) (bool, error) {
+	if blocker == blocked {
+		return false, nil
+	}
+	return svc.repo.Blocked(ctx, blocker, blocked)
+}
+
+func (svc *blockService) GetBlockedList(ctx context.Context, blocker int64, offset, limit int) ([]domain.BlockRelation, error) {
+	return svc.repo.GetBlockedList(ctx, blocker, offset, limit)
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"webook/internal/domain"
+	"webook/internal/repository"
+	repov1mocks "webook/internal/repository/mocks"
+)
+
+func Test_blockService_Block(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (repository.BlockRepository, repository.UserRepository)
+		blocked int64
+		wantErr error
+	}{
+		{
+			name: "block",
+			mock: func(ctrl *gomock.Controller) (repository.BlockRepository, repository.UserRepository) {
+				userRepo := repov1mocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindById(gomock.Any(), int64(456)).Return(domain.User{Id: 456}, nil)
+				repo := repov1mocks.NewMockBlockRepository(ctrl)
+				repo.EXPECT().Block(gomock.Any(), int64(123), int64(456)).Return(nil)
+				return repo, userRepo
+			},
+			blocked: 456,
+		},
+		{
+			name: "user not found",
+			mock: func(ctrl *gomock.Controller) (repository.BlockRepository, repository.UserRepository) {
+				userRepo := repov1mocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindById(gomock.Any(), int64(456)).
+					Return(domain.User{}, repository.ErrUserNotFound)
+				return repov1mocks.NewMockBlockRepository(ctrl), userRepo
+			},
+			blocked: 456,
+			wantErr: ErrUserNotFound,
+		},
+		{
+			name: "block self",
+			mock: func(ctrl *gomock.Controller) (repository.BlockRepository, repository.UserRepository) {
+				return repov1mocks.NewMockBlockRepository(ctrl), repov1mocks.NewMockUserRepository(ctrl)
+			},
+			blocked: 123,
+			wantErr: ErrBlockSelf,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewBlockService(tc.mock(ctrl))
+			err := svc.Block(context.Background(), 123, tc.blocked)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
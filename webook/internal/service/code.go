@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"webook/internal/repository"
+	"webook/pkg/sms"
+)
+
+// 短信模板 ID，跟着供应商走
+const codeTplId = "1877556"
+
+var (
+	ErrCodeSendTooMany        = repository.ErrCodeSendTooMany
+	ErrCodeVerifyTooManyTimes = repository.ErrCodeVerifyTooManyTimes
+)
+
+type CodeService interface {
+	Send(ctx context.Context, biz, phone string) error
+	Verify(ctx context.Context, biz, phone, inputCode string) (bool, error)
+}
+
+type codeService struct {
+	repo   repository.CodeRepository
+	smsSvc sms.Service
+}
+
+func NewCodeService(repo repository.CodeRepository, smsSvc sms.Service) CodeService {
+	return &codeService{
+		repo:   repo,
+		smsSvc: smsSvc,
+	}
+}
+
+func (svc *codeService) Send(ctx context.Context, biz, phone string) error {
+	code, err := svc.generateCode()
+	if err != nil {
+		return err
+	}
+	// 先存起来，这里会做发送频率的控制
+	err = svc.repo.Store(ctx, biz, phone, code)
+	if err != nil {
+		return err
+	}
+	// 发送失败的话，用户过了发送间隔可以重发
+	return svc.smsSvc.Send(ctx, codeTplId, []string{code}, phone)
+}
+
+func (svc *codeService) Verify(ctx context.Context, biz, phone, inputCode string) (bool, error) {
+	return svc.repo.Verify(ctx, biz, phone, inputCode)
+}
+
+func (svc *codeService) generateCode() (string, error) {
+	// 验证码要不可预测，用 crypto/rand。六位数，不足补 0
+	num, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", num.Int64()), nil
+}
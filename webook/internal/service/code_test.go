@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"webook/internal/repository"
+	repov1mocks "webook/internal/repository/mocks"
+	"webook/pkg/sms/localsms"
+)
+
+func Test_codeService_Send(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) repository.CodeRepository
+		wantErr error
+		wantCnt int
+	}{
+		{
+			name: "send success",
+			mock: func(ctrl *gomock.Controller) repository.CodeRepository {
+				repo := repov1mocks.NewMockCodeRepository(ctrl)
+				repo.EXPECT().Store(gomock.Any(), "login", "15212345678", gomock.Any()).Return(nil)
+				return repo
+			},
+			wantCnt: 1,
+		},
+		{
+			name: "send too many",
+			mock: func(ctrl *gomock.Controller) repository.CodeRepository {
+				repo := repov1mocks.NewMockCodeRepository(ctrl)
+				repo.EXPECT().Store(gomock.Any(), "login", "15212345678", gomock.Any()).
+					Return(ErrCodeSendTooMany)
+				return repo
+			},
+			wantErr: ErrCodeSendTooMany,
+		},
+		{
+			name: "redis error",
+			mock: func(ctrl *gomock.Controller) repository.CodeRepository {
+				repo := repov1mocks.NewMockCodeRepository(ctrl)
+				repo.EXPECT().Store(gomock.Any(), "login", "15212345678", gomock.Any()).
+					Return(errors.New("mock redis error"))
+				return repo
+			},
+			wantErr: errors.New("mock redis error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			smsSvc := localsms.NewService()
+			svc := NewCodeService(tc.mock(ctrl), smsSvc)
+			err := svc.Send(context.Background(), "login", "15212345678")
+			assert.Equal(t, tc.wantErr, err)
+			msgs := smsSvc.Messages()
+			require.Len(t, msgs, tc.wantCnt)
+			for _, msg := range msgs {
+				assert.Equal(t, []string{"15212345678"}, msg.Numbers)
+				require.Len(t, msg.Args, 1)
+				assert.Len(t, msg.Args[0], 6)
+			}
+		})
+	}
+}
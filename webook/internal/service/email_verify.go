@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository"
+	"webook/pkg/email"
+)
+
+var ErrInvalidVerifyToken = errors.New("invalid or expired verification token")
+
+const (
+	tokenBizVerifyEmail = "verify_email"
+	tokenBizChangeEmail = "change_email"
+)
+
+type EmailVerifyService interface {
+	// SendVerifyEmail 给用户当前绑定的邮箱发验证链接，已经验证过的就不发了
+	SendVerifyEmail(ctx context.Context, email string) error
+	// SendChangeEmail 给新邮箱发确认链接，确认之前用户还是用老的邮箱
+	SendChangeEmail(ctx context.Context, uid int64, newEmail string) error
+	// Verify 处理邮件里面的链接，验证邮箱或者完成换绑
+	Verify(ctx context.Context, token string) error
+}
+
+// EmailClaims 验证邮箱的令牌，Biz 区分是验证还是换绑
+type EmailClaims struct {
+	Uid   int64
+	Email string
+	Biz   string
+	jwt.RegisteredClaims
+}
+
+type emailVerifyService struct {
+	repo     repository.TokenRepository
+	userRepo repository.UserRepository
+	emailSvc email.Service
+	key      []byte
+	// 前端验证邮箱的页面
+	verifyURL  string
+	expiration time.Duration
+}
+
+func NewEmailVerifyService(repo repository.TokenRepository,
+	userRepo repository.UserRepository, emailSvc email.Service) EmailVerifyService {
+	return &emailVerifyService{
+		repo:       repo,
+		userRepo:   userRepo,
+		emailSvc:   emailSvc,
+		key:        []byte("Hs4Tk9Wq2Zx7Lm5Nb8Vc1Rd6Pf3Gj0Ye"),
+		verifyURL:  "http://localhost:3000/users/verify_email",
+		expiration: time.Hour * 24,
+	}
+}
+
+func (svc *emailVerifyService) SendVerifyEmail(ctx context.Context, email string) error {
+	u, err := svc.userRepo.FindByEmail(ctx, email)
+	if err == repository.ErrUserNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if u.EmailVerified {
+		return nil
+	}
+	return svc.send(ctx, tokenBizVerifyEmail, u.Id, u.Email, "验证你的 webook 邮箱")
+}
+
+func (svc *emailVerifyService) SendChangeEmail(ctx context.Context, uid int64, newEmail string) error {
+	// 先挡一下，确认的时候还有唯一索引兜底
+	_, err := svc.userRepo.FindByEmail(ctx, newEmail)
+	if err == nil {
+		return ErrUserDuplicatedEmail
+	}
+	if err != repository.ErrUserNotFound {
+		return err
+	}
+	return svc.send(ctx, tokenBizChangeEmail, uid, newEmail, "确认你的 webook 新邮箱")
+}
+
+func (svc *emailVerifyService) Verify(ctx context.Context, token string) error {
+	var ec EmailClaims
+	t, err := jwt.ParseWithClaims(token, &ec, func(token *jwt.Token) (interface{}, error) {
+		return svc.key, nil
+	})
+	if err != nil || !t.Valid || ec.Uid == 0 || ec.Email == "" {
+		return ErrInvalidVerifyToken
+	}
+	if ec.Biz != tokenBizVerifyEmail && ec.Biz != tokenBizChangeEmail {
+		return ErrInvalidVerifyToken
+	}
+	err = svc.repo.Consume(ctx, ec.Biz, ec.Uid, ec.ID)
+	if err == repository.ErrTokenInvalid {
+		return ErrInvalidVerifyToken
+	}
+	if err != nil {
+		return err
+	}
+
+	if ec.Biz == tokenBizChangeEmail {
+		// 新邮箱确认了才真正换过去
+		return svc.userRepo.UpdateNonZeroFields(ctx, domain.User{
+			Id:            ec.Uid,
+			Email:         ec.Email,
+			EmailVerified: true,
+		})
+	}
+	u, err := svc.userRepo.FindById(ctx, ec.Uid)
+	if err != nil {
+		return err
+	}
+	// 发邮件之后邮箱又换了，这个链接就没用了
+	if u.Email != ec.Email {
+		return ErrInvalidVerifyToken
+	}
+	return svc.userRepo.UpdateNonZeroFields(ctx, domain.User{
+		Id:            ec.Uid,
+		EmailVerified: true,
+	})
+}
+
+func (svc *emailVerifyService) send(ctx context.Context, biz string, uid int64, to, subject string) error {
+	tokenId := uuid.New().String()
+	err := svc.repo.Store(ctx, biz, uid, tokenId, svc.expiration)
+	if err != nil {
+		return err
+	}
+	claims := EmailClaims{
+		Uid:   uid,
+		Email: to,
+		Biz:   biz,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenId,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(svc.expiration)),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS512, claims).SignedString(svc.key)
+	if err != nil {
+		return err
+	}
+	body := fmt.Sprintf("点击下面的链接确认你的邮箱，%d 小时内有效：\n%s?token=%s",
+		int(svc.expiration.Hours()), svc.verifyURL, token)
+	return svc.emailSvc.Send(ctx, to, subject, body)
+}
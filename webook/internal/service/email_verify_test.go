@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"strings"
+	"testing"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository"
+	repov1mocks "webook/internal/repository/mocks"
+	"webook/pkg/email/localemail"
+)
+
+// tokenFromMail 从邮件正文里面把链接上的令牌抠出来
+func tokenFromMail(t *testing.T, body string) string {
+	idx := strings.Index(body, "token=")
+	require.True(t, idx > 0)
+	return body[idx+len("token="):]
+}
+
+func Test_emailVerifyService_VerifyEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := repov1mocks.NewMockTokenRepository(ctrl)
+	userRepo := repov1mocks.NewMockUserRepository(ctrl)
+	emailSvc := localemail.NewService()
+	svc := NewEmailVerifyService(repo, userRepo, emailSvc)
+
+	var tokenId string
+	userRepo.EXPECT().FindByEmail(gomock.Any(), "123@qq.com").
+		Return(domain.User{Id: 1, Email: "123@qq.com"}, nil)
+	repo.EXPECT().Store(gomock.Any(), "verify_email", int64(1), gomock.Any(), time.Hour*24).
+		DoAndReturn(func(ctx context.Context, biz string, uid int64, id string, exp time.Duration) error {
+			tokenId = id
+			return nil
+		})
+	err := svc.SendVerifyEmail(context.Background(), "123@qq.com")
+	require.NoError(t, err)
+	mails := emailSvc.Mails()
+	require.Len(t, mails, 1)
+	assert.Equal(t, "123@qq.com", mails[0].To)
+
+	repo.EXPECT().Consume(gomock.Any(), "verify_email", int64(1), tokenId).Return(nil)
+	userRepo.EXPECT().FindById(gomock.Any(), int64(1)).
+		Return(domain.User{Id: 1, Email: "123@qq.com"}, nil)
+	userRepo.EXPECT().UpdateNonZeroFields(gomock.Any(), domain.User{Id: 1, EmailVerified: true}).
+		Return(nil)
+	err = svc.Verify(context.Background(), tokenFromMail(t, mails[0].Body))
+	require.NoError(t, err)
+}
+
+func Test_emailVerifyService_ChangeEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := repov1mocks.NewMockTokenRepository(ctrl)
+	userRepo := repov1mocks.NewMockUserRepository(ctrl)
+	emailSvc := localemail.NewService()
+	svc := NewEmailVerifyService(repo, userRepo, emailSvc)
+
+	var tokenId string
+	userRepo.EXPECT().FindByEmail(gomock.Any(), "new@qq.com").
+		Return(domain.User{}, repository.ErrUserNotFound)
+	repo.EXPECT().Store(gomock.Any(), "change_email", int64(1), gomock.Any(), time.Hour*24).
+		DoAndReturn(func(ctx context.Context, biz string, uid int64, id string, exp time.Duration) error {
+			tokenId = id
+			return nil
+		})
+	err := svc.SendChangeEmail(context.Background(), 1, "new@qq.com")
+	require.NoError(t, err)
+	mails := emailSvc.Mails()
+	require.Len(t, mails, 1)
+	// 确认邮件是发给新邮箱的
+	assert.Equal(t, "new@qq.com", mails[0].To)
+	token := tokenFromMail(t, mails[0].Body)
+
+	repo.EXPECT().Consume(gomock.Any(), "change_email", int64(1), tokenId).Return(nil)
+	userRepo.EXPECT().UpdateNonZeroFields(gomock.Any(), domain.User{
+		Id:            1,
+		Email:         "new@qq.com",
+		EmailVerified: true,
+	}).Return(nil)
+	err = svc.Verify(context.Background(), token)
+	require.NoError(t, err)
+
+	// 链接只能用一次
+	repo.EXPECT().Consume(gomock.Any(), "change_email", int64(1), tokenId).
+		Return(repository.ErrTokenInvalid)
+	err = svc.Verify(context.Background(), token)
+	assert.Equal(t, ErrInvalidVerifyToken, err)
+}
+
+func Test_emailVerifyService_ChangeEmail_Duplicated(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userRepo := repov1mocks.NewMockUserRepository(ctrl)
+	userRepo.EXPECT().FindByEmail(gomock.Any(), "used@qq.com").
+		Return(domain.User{Id: 2, Email: "used@qq.com"}, nil)
+	emailSvc := localemail.NewService()
+	svc := NewEmailVerifyService(repov1mocks.NewMockTokenRepository(ctrl), userRepo, emailSvc)
+	err := svc.SendChangeEmail(context.Background(), 1, "used@qq.com")
+	assert.Equal(t, ErrUserDuplicatedEmail, err)
+	assert.Empty(t, emailSvc.Mails())
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"golang.org/x/sync/errgroup"
+	"sort"
+	"webook/internal/domain"
+	"webook/internal/repository"
+)
+
+// 读者数不超过这个的作者发表文章的时候直接推到读者的收件箱，
+// 超过了就在读者刷信息流的时候再去拉
+const feedPushThreshold = 1000
+
+type FeedService interface {
+	// FanOut 新发表的文章进入读者的信息流
+	FanOut(ctx context.Context, art domain.Article) error
+	// Feed 按照时间倒序，cursor 是上一页的最后一条
+	Feed(ctx context.Context, uid int64, cursor domain.ArticleCursor, limit int) ([]domain.FeedItem, error)
+}
+
+type feedService struct {
+	repo repository.FeedRepository
+}
+
+func NewFeedService(repo repository.FeedRepository) FeedService {
+	return &feedService{
+		repo: repo,
+	}
+}
+
+func (svc *feedService) FanOut(ctx context.Context, art domain.Article) error {
+	audience, err := svc.repo.CountAudience(ctx, art.Author.Id)
+	if err != nil {
+		return err
+	}
+	// 先记下来，拉模式靠这个判断要不要拉这个作者
+	err = svc.repo.SetAudience(ctx, art.Author.Id, audience)
+	if err != nil {
+		return err
+	}
+	if audience > feedPushThreshold {
+		return nil
+	}
+	uids, err := svc.repo.Audience(ctx, art.Author.Id)
+	if err != nil {
+		return err
+	}
+	return svc.repo.Push(ctx, art.Id, art.Utime, uids)
+}
+
+func (svc *feedService) Feed(ctx context.Context, uid int64, cursor domain.ArticleCursor, limit int) ([]domain.FeedItem, error) {
+	var (
+		eg     errgroup.Group
+		pushed []domain.FeedItem
+		pulled []domain.FeedItem
+	)
+	// 两边各取一页，合并之后再截断
+	eg.Go(func() error {
+		var er error
+		pushed, er = svc.repo.InboxItems(ctx, uid, cursor, limit)
+		return er
+	})
+	eg.Go(func() error {
+		var er error
+		pulled, er = svc.repo.PullItems(ctx, uid, feedPushThreshold, cursor, limit)
+		return er
+	})
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+	items := append(pushed, pulled...)
+	sort.Slice(items, func(i, j int) bool {
+		ti, tj := items[i].Time.UnixMilli(), items[j].Time.UnixMilli()
+		if ti != tj {
+			return ti > tj
+		}
+		return items[i].Article.Id > items[j].Article.Id
+	})
+	// 作者从小变大的时候，同一篇文章两边都可能有
+	res := make([]domain.FeedItem, 0, limit)
+	seen := make(map[int64]struct{}, len(items))
+	for _, item := range items {
+		if _, ok := seen[item.Article.Id]; ok {
+			continue
+		}
+		seen[item.Article.Id] = struct{}{}
+		res = append(res, item)
+		if len(res) == limit {
+			break
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository"
+	repov1mocks "webook/internal/repository/mocks"
+)
+
+func Test_feedService_FanOut(t *testing.T) {
+	now := time.Now()
+	art := domain.Article{Id: 1, Author: domain.Author{Id: 123}, Utime: now}
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) repository.FeedRepository
+	}{
+		{
+			name: "small author, push",
+			mock: func(ctrl *gomock.Controller) repository.FeedRepository {
+				repo := repov1mocks.NewMockFeedRepository(ctrl)
+				repo.EXPECT().CountAudience(gomock.Any(), int64(123)).Return(int64(2), nil)
+				repo.EXPECT().SetAudience(gomock.Any(), int64(123), int64(2)).Return(nil)
+				repo.EXPECT().Audience(gomock.Any(), int64(123)).Return([]int64{4, 5}, nil)
+				repo.EXPECT().Push(gomock.Any(), int64(1), now, []int64{4, 5}).Return(nil)
+				return repo
+			},
+		},
+		{
+			name: "big author, pull later",
+			mock: func(ctrl *gomock.Controller) repository.FeedRepository {
+				repo := repov1mocks.NewMockFeedRepository(ctrl)
+				repo.EXPECT().CountAudience(gomock.Any(), int64(123)).Return(int64(feedPushThreshold+1), nil)
+				repo.EXPECT().SetAudience(gomock.Any(), int64(123), int64(feedPushThreshold+1)).Return(nil)
+				return repo
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewFeedService(tc.mock(ctrl))
+			err := svc.FanOut(context.Background(), art)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_feedService_Feed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	item := func(aid int64, ms int64) domain.FeedItem {
+		return domain.FeedItem{Article: domain.Article{Id: aid}, Time: time.UnixMilli(ms)}
+	}
+	cursor := domain.ArticleCursor{Time: 1000, Aid: 9}
+	repo := repov1mocks.NewMockFeedRepository(ctrl)
+	repo.EXPECT().InboxItems(gomock.Any(), int64(1), cursor, 3).
+		Return([]domain.FeedItem{item(5, 900), item(3, 700), item(2, 600)}, nil)
+	// 4 和 5 时间一样，id 大的在前；5 两边都有
+	repo.EXPECT().PullItems(gomock.Any(), int64(1), int64(feedPushThreshold), cursor, 3).
+		Return([]domain.FeedItem{item(6, 950), item(5, 900), item(4, 900)}, nil)
+	svc := NewFeedService(repo)
+	items, err := svc.Feed(context.Background(), 1, cursor, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []domain.FeedItem{item(6, 950), item(5, 900), item(4, 900)}, items)
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"webook/internal/domain"
+	"webook/internal/repository"
+)
+
+var ErrFollowSelf = errors.New("cannot follow yourself")
+
+type FollowService interface {
+	Follow(ctx context.Context, follower, followee int64) error
+	CancelFollow(ctx context.Context, follower, followee int64) error
+	// Followed follower 有没有关注 followee
+	Followed(ctx context.Context, follower, followee int64) (bool, error)
+	GetFollowers(ctx context.Context, uid int64, offset, limit int) ([]domain.FollowRelation, error)
+	GetFollowees(ctx context.Context, uid int64, offset, limit int) ([]domain.FollowRelation, error)
+	GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error)
+}
+
+type followService struct {
+	repo     repository.FollowRepository
+	userRepo repository.UserRepository
+}
+
+func NewFollowService(repo repository.FollowRepository, userRepo repository.UserRepository) FollowService {
+	return &followService{
+		repo:     repo,
+		userRepo: userRepo,
+	}
+}
+
+func (svc *followService) Follow(ctx context.Context, follower, followee int64) error {
+	if follower == followee {
+		return ErrFollowSelf
+	}
+	// 不能关注一个不存在的用户
+	_, err := svc.userRepo.FindById(ctx, followee)
+	if err != nil {
+		return err
+	}
+	return svc.repo.AddFollowRelation(ctx, follower, followee)
+}
+
+func (svc *followService) CancelFollow(ctx context.Context, follower, followee int64) error {
+	return svc.repo.InactiveFollowRelation(ctx, follower, followee)
+}
+
+func (svc *followService) Followed(ctx context.Context, follower, followee int64) (bool, error) {
+	// 自己看自己的文章
+	if follower == followee {
+		return false, nil
+	}
+	return svc.repo.Followed(ctx, follower, followee)
+}
+
+func (svc *followService) GetFollowers(ctx context.Context, uid int64, offset, limit int) ([]domain.FollowRelation, error) {
+	return svc.repo.GetFollowers(ctx, uid, offset, limit)
+}
+
+func (svc *followService) GetFollowees(ctx context.Context, uid int64, offset, limit int) ([]domain.FollowRelation, error) {
+	return svc.repo.GetFollowees(ctx, uid, offset, limit)
+}
+
+func (svc *followService) GetStatics(ctx context.Context, uid int64) (domain.FollowStatics, error) {
+	return svc.repo.GetStatics(ctx, uid)
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"webook/internal/domain"
+	"webook/internal/repository"
+	repov1mocks "webook/internal/repository/mocks"
+)
+
+func Test_followService_Follow(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) (repository.FollowRepository, repository.UserRepository)
+		followee int64
+		wantErr  error
+	}{
+		{
+			name: "follow",
+			mock: func(ctrl *gomock.Controller) (repository.FollowRepository, repository.UserRepository) {
+				userRepo := repov1mocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindById(gomock.Any(), int64(456)).Return(domain.User{Id: 456}, nil)
+				repo := repov1mocks.NewMockFollowRepository(ctrl)
+				repo.EXPECT().AddFollowRelation(gomock.Any(), int64(123), int64(456)).Return(nil)
+				return repo, userRepo
+			},
+			followee: 456,
+		},
+		{
+			name: "followee not found",
+			mock: func(ctrl *gomock.Controller) (repository.FollowRepository, repository.UserRepository) {
+				userRepo := repov1mocks.NewMockUserRepository(ctrl)
+				userRepo.EXPECT().FindById(gomock.Any(), int64(456)).
+					Return(domain.User{}, repository.ErrUserNotFound)
+				return repov1mocks.NewMockFollowRepository(ctrl), userRepo
+			},
+			followee: 456,
+			wantErr:  ErrUserNotFound,
+		},
+		{
+			name: "follow self",
+			mock: func(ctrl *gomock.Controller) (repository.FollowRepository, repository.UserRepository) {
+				return repov1mocks.NewMockFollowRepository(ctrl), repov1mocks.NewMockUserRepository(ctrl)
+			},
+			followee: 123,
+			wantErr:  ErrFollowSelf,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewFollowService(tc.mock(ctrl))
+			err := svc.Follow(context.Background(), 123, tc.followee)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
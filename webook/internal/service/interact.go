@@ -13,14 +13,23 @@ type InteractiveService interface {
 	CancelLike(ctx context.Context, biz string, id int64, uid int64) error
 	Collect(ctx context.Context, biz string, bizId, cid, uid int64) error
 	Get(ctx context.Context, biz string, id int64, uid int64) (domain.Interactive, error)
+	// GetByIds 只有计数，没有当前用户有没有点赞收藏
+	GetByIds(ctx context.Context, biz string, ids []int64) (map[int64]domain.Interactive, error)
 }
 
 type CashedInteractiveService struct {
-	repo repository.InteractiveRepository
+	repo      repository.InteractiveRepository
+	artRepo   repository.ArticleRepository
+	blockRepo repository.BlockRepository
 }
 
-func NewInteractiveService(repo repository.InteractiveRepository) InteractiveService {
-	return &CashedInteractiveService{repo: repo}
+func NewInteractiveService(repo repository.InteractiveRepository,
+	artRepo repository.ArticleRepository, blockRepo repository.BlockRepository) InteractiveService {
+	return &CashedInteractiveService{
+		repo:      repo,
+		artRepo:   artRepo,
+		blockRepo: blockRepo,
+	}
 }
 
 func (i *CashedInteractiveService) Get(ctx context.Context, biz string, id int64, uid int64) (domain.Interactive, error) {
@@ -43,11 +52,23 @@ func (i *CashedInteractiveService) Get(ctx context.Context, biz string, id int64
 	return intr, eg.Wait()
 }
 
+func (i *CashedInteractiveService) GetByIds(ctx context.Context, biz string, ids []int64) (map[int64]domain.Interactive, error) {
+	return i.repo.GetByIds(ctx, biz, ids)
+}
+
 func (i *CashedInteractiveService) Collect(ctx context.Context, biz string, bizId, cid, uid int64) error {
+	err := i.checkBlocked(ctx, bizId, uid)
+	if err != nil {
+		return err
+	}
 	return i.repo.AddCollectionItem(ctx, biz, bizId, cid, uid)
 }
 
 func (i *CashedInteractiveService) Like(ctx context.Context, biz string, id int64, uid int64) error {
+	err := i.checkBlocked(ctx, id, uid)
+	if err != nil {
+		return err
+	}
 	return i.repo.IncrLike(ctx, biz, id, uid)
 }
 
@@ -58,3 +79,20 @@ func (i *CashedInteractiveService) CancelLike(ctx context.Context, biz string, i
 func (i *CashedInteractiveService) IncrReadCnt(ctx context.Context, biz string, bizId int64) error {
 	return i.repo.IncrReadCnt(ctx, biz, bizId)
 }
+
+// checkBlocked 作者拉黑了 uid 的话就不能点赞收藏，取消点赞不拦
+// 目前只有文章有点赞收藏，所以直接按照文章去找作者
+func (i *CashedInteractiveService) checkBlocked(ctx context.Context, aid int64, uid int64) error {
+	art, err := i.artRepo.GetPubById(ctx, aid)
+	if err != nil {
+		return err
+	}
+	blocked, err := i.blockRepo.Blocked(ctx, art.Author.Id, uid)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return ErrBlocked
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"webook/internal/domain"
+	"webook/internal/repository"
+	repov1mocks "webook/internal/repository/mocks"
+)
+
+func TestCashedInteractiveService_Like(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (repository.InteractiveRepository,
+			repository.ArticleRepository, repository.BlockRepository)
+		wantErr error
+	}{
+		{
+			name: "like",
+			mock: func(ctrl *gomock.Controller) (repository.InteractiveRepository,
+				repository.ArticleRepository, repository.BlockRepository) {
+				artRepo := repov1mocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetPubById(gomock.Any(), int64(1)).
+					Return(domain.Article{Id: 1, Author: domain.Author{Id: 456}}, nil)
+				blockRepo := repov1mocks.NewMockBlockRepository(ctrl)
+				blockRepo.EXPECT().Blocked(gomock.Any(), int64(456), int64(123)).Return(false, nil)
+				repo := repov1mocks.NewMockInteractiveRepository(ctrl)
+				repo.EXPECT().IncrLike(gomock.Any(), "articles", int64(1), int64(123)).Return(nil)
+				return repo, artRepo, blockRepo
+			},
+		},
+		{
+			name: "blocked by author",
+			mock: func(ctrl *gomock.Controller) (repository.InteractiveRepository,
+				repository.ArticleRepository, repository.BlockRepository) {
+				artRepo := repov1mocks.NewMockArticleRepository(ctrl)
+				artRepo.EXPECT().GetPubById(gomock.Any(), int64(1)).
+					Return(domain.Article{Id: 1, Author: domain.Author{Id: 456}}, nil)
+				blockRepo := repov1mocks.NewMockBlockRepository(ctrl)
+				blockRepo.EXPECT().Blocked(gomock.Any(), int64(456), int64(123)).Return(true, nil)
+				return repov1mocks.NewMockInteractiveRepository(ctrl), artRepo, blockRepo
+			},
+			wantErr: ErrBlocked,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewInteractiveService(tc.mock(ctrl))
+			err := svc.Like(context.Background(), "articles", 1, 123)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func TestCashedInteractiveService_Collect(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	artRepo := repov1mocks.NewMockArticleRepository(ctrl)
+	artRepo.EXPECT().GetPubById(gomock.Any(), int64(1)).
+		Return(domain.Article{Id: 1, Author: domain.Author{Id: 456}}, nil)
+	blockRepo := repov1mocks.NewMockBlockRepository(ctrl)
+	blockRepo.EXPECT().Blocked(gomock.Any(), int64(456), int64(123)).Return(true, nil)
+	svc := NewInteractiveService(repov1mocks.NewMockInteractiveRepository(ctrl), artRepo, blockRepo)
+	err := svc.Collect(context.Background(), "articles", 1, 2, 123)
+	assert.Equal(t, ErrBlocked, err)
+}
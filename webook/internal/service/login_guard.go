@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"time"
+	"webook/internal/repository"
+	"webook/pkg/email"
+)
+
+var (
+	ErrAccountLocked      = errors.New("account temporarily locked")
+	ErrLoginTooFrequent   = errors.New("too many failed attempts, try again later")
+	ErrInvalidUnlockToken = errors.New("invalid or expired unlock token")
+)
+
+const tokenBizUnlockAccount = "unlock_account"
+
+// LoginGuard 防暴力破解：按账号和按 IP 统计失败次数，逐步加大等待时间，最后临时锁定账号
+type LoginGuard interface {
+	// Check 校验密码之前调用，不允许登录的时候返回错误
+	Check(ctx context.Context, email, ip string) error
+	OnFailure(ctx context.Context, email, ip string) error
+	OnSuccess(ctx context.Context, email string) error
+	// Unlock 处理解锁邮件里面的链接
+	Unlock(ctx context.Context, token string) error
+}
+
+// UnlockClaims 解锁账号的令牌
+type UnlockClaims struct {
+	Uid   int64
+	Email string
+	jwt.RegisteredClaims
+}
+
+type loginGuard struct {
+	repo      repository.LoginAttemptRepository
+	tokenRepo repository.TokenRepository
+	userRepo  repository.UserRepository
+	emailSvc  email.Service
+	lockouts  *prometheus.CounterVec
+
+	// 同一个 IP 在统计窗口内最多失败多少次，防止撞库
+	ipThreshold int
+	// 连续失败几次之后开始要求等待
+	delayAfter int
+	maxDelay   time.Duration
+
+	key        []byte
+	unlockURL  string
+	expiration time.Duration
+}
+
+func NewLoginGuard(repo repository.LoginAttemptRepository, tokenRepo repository.TokenRepository,
+	userRepo repository.UserRepository, emailSvc email.Service) LoginGuard {
+	return &loginGuard{
+		repo:        repo,
+		tokenRepo:   tokenRepo,
+		userRepo:    userRepo,
+		emailSvc:    emailSvc,
+		lockouts:    newLockoutCounter(),
+		ipThreshold: 100,
+		delayAfter:  3,
+		maxDelay:    time.Minute,
+		key:         []byte("Wn5Jd8Ks2Pq7Lx4Vb9Mc1Tz6Hf3Ry0Ga"),
+		unlockURL:   "http://localhost:3000/users/unlock",
+		expiration:  time.Minute * 30,
+	}
+}
+
+// newLockoutCounter 多次创建的时候复用已经注册过的指标
+func newLockoutCounter() *prometheus.CounterVec {
+	c := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "webook",
+		Subsystem: "user",
+		Name:      "login_lockout_total",
+		Help:      "登录失败次数过多触发的锁定",
+	}, []string{"type"})
+	err := prometheus.Register(c)
+	if err != nil {
+		var are prometheus.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			return are.ExistingCollector.(*prometheus.CounterVec)
+		}
+		panic(err)
+	}
+	return c
+}
+
+func (g *loginGuard) Check(ctx context.Context, email, ip string) error {
+	a, err := g.repo.Get(ctx, email, ip)
+	if err != nil {
+		// Redis 出问题的时候保守一点，不让登录
+		return err
+	}
+	if a.Locked {
+		return ErrAccountLocked
+	}
+	if a.IPFails >= g.ipThreshold {
+		return ErrLoginTooFrequent
+	}
+	if a.AccountFails >= g.delayAfter && time.Now().Before(a.LastFail.Add(g.delay(a.AccountFails))) {
+		return ErrLoginTooFrequent
+	}
+	return nil
+}
+
+func (g *loginGuard) OnFailure(ctx context.Context, email, ip string) error {
+	a, err := g.repo.RecordFailure(ctx, email, ip)
+	if err != nil {
+		return err
+	}
+	if a.IPFails == g.ipThreshold {
+		g.lockouts.WithLabelValues("ip").Inc()
+	}
+	if !a.Locked {
+		return nil
+	}
+	g.lockouts.WithLabelValues("account").Inc()
+	return g.sendUnlockEmail(ctx, email)
+}
+
+func (g *loginGuard) OnSuccess(ctx context.Context, email string) error {
+	return g.repo.Reset(ctx, email)
+}
+
+func (g *loginGuard) Unlock(ctx context.Context, token string) error {
+	var uc UnlockClaims
+	t, err := jwt.ParseWithClaims(token, &uc, func(token *jwt.Token) (interface{}, error) {
+		return g.key, nil
+	})
+	if err != nil || !t.Valid || uc.Uid == 0 {
+		return ErrInvalidUnlockToken
+	}
+	err = g.tokenRepo.Consume(ctx, tokenBizUnlockAccount, uc.Uid, uc.ID)
+	if err == repository.ErrTokenInvalid {
+		return ErrInvalidUnlockToken
+	}
+	if err != nil {
+		return err
+	}
+	return g.repo.Unlock(ctx, uc.Email)
+}
+
+// delay 第 delayAfter 次失败之后等 1 秒，之后每次翻倍
+func (g *loginGuard) delay(fails int) time.Duration {
+	n := fails - g.delayAfter
+	if n > 6 {
+		return g.maxDelay
+	}
+	d := time.Second << n
+	if d > g.maxDelay {
+		return g.maxDelay
+	}
+	return d
+}
+
+func (g *loginGuard) sendUnlockEmail(ctx context.Context, email string) error {
+	u, err := g.userRepo.FindByEmail(ctx, email)
+	if err == repository.ErrUserNotFound {
+		// 别人在拿不存在的邮箱试，锁了也没关系
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	tokenId := uuid.New().String()
+	err = g.tokenRepo.Store(ctx, tokenBizUnlockAccount, u.Id, tokenId, g.expiration)
+	if err != nil {
+		return err
+	}
+	claims := UnlockClaims{
+		Uid:   u.Id,
+		Email: email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenId,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(g.expiration)),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS512, claims).SignedString(g.key)
+	if err != nil {
+		return err
+	}
+	body := fmt.Sprintf("你的账号登录失败次数过多，已经被临时锁定。如果是你本人，点击下面的链接解锁：\n%s?token=%s",
+		g.unlockURL, token)
+	return g.emailSvc.Send(ctx, u.Email, "webook 账号已被临时锁定", body)
+}
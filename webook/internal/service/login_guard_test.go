@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository"
+	repov1mocks "webook/internal/repository/mocks"
+	"webook/pkg/email/localemail"
+)
+
+func Test_loginGuard_Check(t *testing.T) {
+	const (
+		email = "123@qq.com"
+		ip    = "127.0.0.1"
+	)
+	testCases := []struct {
+		name     string
+		attempts domain.LoginAttempts
+		wantErr  error
+	}{
+		{
+			name: "no failures",
+		},
+		{
+			name:     "below delay threshold",
+			attempts: domain.LoginAttempts{AccountFails: 2, LastFail: time.Now()},
+		},
+		{
+			name:     "must wait",
+			attempts: domain.LoginAttempts{AccountFails: 5, LastFail: time.Now()},
+			wantErr:  ErrLoginTooFrequent,
+		},
+		{
+			name: "waited long enough",
+			// 第 5 次失败要等 4 秒
+			attempts: domain.LoginAttempts{AccountFails: 5, LastFail: time.Now().Add(-time.Second * 5)},
+		},
+		{
+			name:     "locked",
+			attempts: domain.LoginAttempts{Locked: true},
+			wantErr:  ErrAccountLocked,
+		},
+		{
+			name:     "ip blocked",
+			attempts: domain.LoginAttempts{IPFails: 100},
+			wantErr:  ErrLoginTooFrequent,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := repov1mocks.NewMockLoginAttemptRepository(ctrl)
+			repo.EXPECT().Get(gomock.Any(), email, ip).Return(tc.attempts, nil)
+			g := NewLoginGuard(repo, repov1mocks.NewMockTokenRepository(ctrl),
+				repov1mocks.NewMockUserRepository(ctrl), localemail.NewService())
+			err := g.Check(context.Background(), email, ip)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_loginGuard_LockAndUnlock(t *testing.T) {
+	const (
+		email = "123@qq.com"
+		ip    = "127.0.0.1"
+	)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := repov1mocks.NewMockLoginAttemptRepository(ctrl)
+	tokenRepo := repov1mocks.NewMockTokenRepository(ctrl)
+	userRepo := repov1mocks.NewMockUserRepository(ctrl)
+	emailSvc := localemail.NewService()
+	g := NewLoginGuard(repo, tokenRepo, userRepo, emailSvc)
+
+	// 这一次失败触发了锁定，要发解锁邮件
+	repo.EXPECT().RecordFailure(gomock.Any(), email, ip).
+		Return(domain.LoginAttempts{AccountFails: 10, IPFails: 10, Locked: true}, nil)
+	userRepo.EXPECT().FindByEmail(gomock.Any(), email).
+		Return(domain.User{Id: 1, Email: email}, nil)
+	var tokenId string
+	tokenRepo.EXPECT().Store(gomock.Any(), "unlock_account", int64(1), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, biz string, uid int64, id string, exp time.Duration) error {
+			tokenId = id
+			return nil
+		})
+	err := g.OnFailure(context.Background(), email, ip)
+	require.NoError(t, err)
+	mails := emailSvc.Mails()
+	require.Len(t, mails, 1)
+	assert.Equal(t, email, mails[0].To)
+
+	tokenRepo.EXPECT().Consume(gomock.Any(), "unlock_account", int64(1), tokenId).Return(nil)
+	repo.EXPECT().Unlock(gomock.Any(), email).Return(nil)
+	err = g.Unlock(context.Background(), tokenFromMail(t, mails[0].Body))
+	require.NoError(t, err)
+
+	tokenRepo.EXPECT().Consume(gomock.Any(), "unlock_account", int64(1), tokenId).
+		Return(repository.ErrTokenInvalid)
+	err = g.Unlock(context.Background(), tokenFromMail(t, mails[0].Body))
+	assert.Equal(t, ErrInvalidUnlockToken, err)
+}
@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+	"webook/internal/repository"
+	"webook/pkg/totp"
+)
+
+var (
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication already enabled")
+	ErrMFANotEnrolled    = errors.New("two-factor authentication not enrolled")
+	ErrInvalidMFACode    = errors.New("invalid two-factor code")
+	// ErrMFATooManyAttempts 第二步输错太多次，一段时间内不让再试
+	ErrMFATooManyAttempts = errors.New("too many two-factor attempts")
+	// ErrMFATokenInvalid 第二步的令牌过期了、用过了，或者因为输错太多被作废了
+	ErrMFATokenInvalid = errors.New("invalid or used two-factor login token")
+)
+
+const (
+	mfaIssuer = "webook"
+	// 一次生成多少个恢复码
+	recoveryCodeCnt = 10
+	// 去掉了容易看错的 0 O 1 I
+	recoveryCodeAlphabet = "23456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+	recoveryCodeLen      = 10
+
+	tokenBizMFALogin = "mfa_login"
+	// 第二步输错这么多次，当前的令牌作废，统计窗口内也不能再试
+	mfaMaxFailures = 5
+)
+
+type MFAService interface {
+	// Enroll 生成新的密钥，返回密钥和给验证器扫码的 otpauth URI
+	Enroll(ctx context.Context, uid int64, account string) (string, string, error)
+	// Confirm 用验证器上的验证码确认绑定，成功之后返回恢复码明文，只给用户看这一次
+	Confirm(ctx context.Context, uid int64, code string) ([]string, error)
+	Enabled(ctx context.Context, uid int64) (bool, error)
+	// StartLogin 密码验证通过之后调用，返回第二步令牌的 id，令牌只能用一次
+	StartLogin(ctx context.Context, uid int64, expiration time.Duration) (string, error)
+	// Verify 登录的第二步，code 可以是验证码，也可以是恢复码，通过之后 tokenId 就作废了
+	Verify(ctx context.Context, uid int64, tokenId string, code string) error
+}
+
+type mfaService struct {
+	repo      repository.MFARepository
+	tokenRepo repository.TokenRepository
+}
+
+func NewMFAService(repo repository.MFARepository, tokenRepo repository.TokenRepository) MFAService {
+	return &mfaService{
+		repo:      repo,
+		tokenRepo: tokenRepo,
+	}
+}
+
+func (svc *mfaService) Enroll(ctx context.Context, uid int64, account string) (string, string, error) {
+	m, err := svc.repo.FindByUid(ctx, uid)
+	if err != nil && err != repository.ErrMFANotFound {
+		return "", "", err
+	}
+	if m.Enabled {
+		return "", "", ErrMFAAlreadyEnabled
+	}
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return "", "", err
+	}
+	err = svc.repo.SavePending(ctx, uid, secret)
+	if err != nil {
+		return "", "", err
+	}
+	return secret, totp.URI(mfaIssuer, account, secret), nil
+}
+
+func (svc *mfaService) Confirm(ctx context.Context, uid int64, code string) ([]string, error) {
+	m, err := svc.repo.FindByUid(ctx, uid)
+	if err == repository.ErrMFANotFound {
+		return nil, ErrMFANotEnrolled
+	}
+	if err != nil {
+		return nil, err
+	}
+	if m.Enabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	if !totp.Validate(code, m.Secret, time.Now()) {
+		return nil, ErrInvalidMFACode
+	}
+	codes := make([]string, 0, recoveryCodeCnt)
+	hashes := make([]string, 0, recoveryCodeCnt)
+	for i := 0; i < recoveryCodeCnt; i++ {
+		c, err := svc.generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, c)
+		hashes = append(hashes, svc.hashRecoveryCode(c))
+	}
+	err = svc.repo.Enable(ctx, uid, hashes)
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (svc *mfaService) Enabled(ctx context.Context, uid int64) (bool, error) {
+	m, err := svc.repo.FindByUid(ctx, uid)
+	if err == repository.ErrMFANotFound {
+		return false, nil
+	}
+	return m.Enabled, err
+}
+
+func (svc *mfaService) StartLogin(ctx context.Context, uid int64, expiration time.Duration) (string, error) {
+	tokenId := uuid.New().String()
+	err := svc.tokenRepo.Store(ctx, tokenBizMFALogin, uid, tokenId, expiration)
+	if err != nil {
+		return "", err
+	}
+	return tokenId, nil
+}
+
+func (svc *mfaService) Verify(ctx context.Context, uid int64, tokenId string, code string) error {
+	// 按用户计数，重新输一遍密码拿新令牌也绕不过去
+	fails, err := svc.repo.Failures(ctx, uid)
+	if err != nil {
+		return err
+	}
+	if fails >= mfaMaxFailures {
+		return ErrMFATooManyAttempts
+	}
+	// 先看令牌，令牌不对就不能用掉验证码或者恢复码
+	err = svc.tokenRepo.Check(ctx, tokenBizMFALogin, uid, tokenId)
+	if err == repository.ErrTokenInvalid {
+		return ErrMFATokenInvalid
+	}
+	if err != nil {
+		return err
+	}
+	err = svc.verifyCode(ctx, uid, code)
+	if err == ErrInvalidMFACode {
+		return svc.recordFailure(ctx, uid, tokenId)
+	}
+	if err != nil {
+		return err
+	}
+	err = svc.tokenRepo.Consume(ctx, tokenBizMFALogin, uid, tokenId)
+	if err == repository.ErrTokenInvalid {
+		return ErrMFATokenInvalid
+	}
+	if err != nil {
+		return err
+	}
+	// 清不掉也就是多占着几次失败，不影响这次登录
+	_ = svc.repo.ResetFailures(ctx, uid)
+	return nil
+}
+
+func (svc *mfaService) verifyCode(ctx context.Context, uid int64, code string) error {
+	m, err := svc.repo.FindByUid(ctx, uid)
+	if err == repository.ErrMFANotFound {
+		return ErrMFANotEnrolled
+	}
+	if err != nil {
+		return err
+	}
+	if !m.Enabled {
+		return ErrMFANotEnrolled
+	}
+	if step, ok := totp.ValidateStep(code, m.Secret, time.Now()); ok {
+		// 同一个验证码只能用一次，比上次用过的更早的也不行
+		ok, err = svc.repo.UseStep(ctx, uid, step)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrInvalidMFACode
+		}
+		return nil
+	}
+	// 不是验证码，那就当恢复码试一下
+	err = svc.repo.UseRecoveryCode(ctx, uid, svc.hashRecoveryCode(code))
+	if err == repository.ErrRecoveryCodeNotFound {
+		return ErrInvalidMFACode
+	}
+	return err
+}
+
+func (svc *mfaService) recordFailure(ctx context.Context, uid int64, tokenId string) error {
+	fails, err := svc.repo.RecordFailure(ctx, uid)
+	if err != nil {
+		return err
+	}
+	if fails >= mfaMaxFailures {
+		// 当前的令牌作废，只能重新输密码
+		_ = svc.tokenRepo.Consume(ctx, tokenBizMFALogin, uid, tokenId)
+		return ErrMFATooManyAttempts
+	}
+	return ErrInvalidMFACode
+}
+
+func (svc *mfaService) generateRecoveryCode() (string, error) {
+	buf := make([]byte, recoveryCodeLen)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", err
+	}
+	for i, b := range buf {
+		buf[i] = recoveryCodeAlphabet[int(b)%len(recoveryCodeAlphabet)]
+	}
+	return string(buf), nil
+}
+
+// hashRecoveryCode 恢复码本身是高熵的随机串，用 sha256 就够了，还能直接按哈希查
+func (svc *mfaService) hashRecoveryCode(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository"
+	repov1mocks "webook/internal/repository/mocks"
+	"webook/pkg/totp"
+)
+
+func Test_mfaService_EnrollAndConfirm(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := repov1mocks.NewMockMFARepository(ctrl)
+	svc := NewMFAService(repo, repov1mocks.NewMockTokenRepository(ctrl))
+
+	var secret string
+	repo.EXPECT().FindByUid(gomock.Any(), int64(1)).Return(domain.MFA{}, repository.ErrMFANotFound)
+	repo.EXPECT().SavePending(gomock.Any(), int64(1), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, uid int64, s string) error {
+			secret = s
+			return nil
+		})
+	gotSecret, uri, err := svc.Enroll(context.Background(), 1, "123@qq.com")
+	require.NoError(t, err)
+	assert.Equal(t, secret, gotSecret)
+	assert.Contains(t, uri, "otpauth://totp/webook:123@qq.com?")
+
+	code, err := totp.GenerateCode(secret, time.Now())
+	require.NoError(t, err)
+	repo.EXPECT().FindByUid(gomock.Any(), int64(1)).Return(domain.MFA{Uid: 1, Secret: secret}, nil)
+	var hashes []string
+	repo.EXPECT().Enable(gomock.Any(), int64(1), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, uid int64, h []string) error {
+			hashes = h
+			return nil
+		})
+	codes, err := svc.Confirm(context.Background(), 1, code)
+	require.NoError(t, err)
+	assert.Len(t, codes, 10)
+	require.Len(t, hashes, 10)
+	// 数据库里面不能是明文
+	assert.NotEqual(t, codes[0], hashes[0])
+}
+
+func Test_mfaService_Verify(t *testing.T) {
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	code, err := totp.GenerateCode(secret, time.Now())
+	require.NoError(t, err)
+	step := time.Now().Unix() / 30
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (repository.MFARepository, repository.TokenRepository)
+		code    string
+		wantErr error
+	}{
+		{
+			name: "totp code",
+			mock: func(ctrl *gomock.Controller) (repository.MFARepository, repository.TokenRepository) {
+				repo := repov1mocks.NewMockMFARepository(ctrl)
+				repo.EXPECT().Failures(gomock.Any(), int64(1)).Return(0, nil)
+				repo.EXPECT().FindByUid(gomock.Any(), int64(1)).
+					Return(domain.MFA{Uid: 1, Secret: secret, Enabled: true}, nil)
+				repo.EXPECT().UseStep(gomock.Any(), int64(1), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, uid int64, s int64) (bool, error) {
+						// 只认前后一个周期
+						assert.InDelta(t, step, s, 1)
+						return true, nil
+					})
+				repo.EXPECT().ResetFailures(gomock.Any(), int64(1)).Return(nil)
+				tokenRepo := repov1mocks.NewMockTokenRepository(ctrl)
+				tokenRepo.EXPECT().Check(gomock.Any(), "mfa_login", int64(1), "token_id").Return(nil)
+				tokenRepo.EXPECT().Consume(gomock.Any(), "mfa_login", int64(1), "token_id").Return(nil)
+				return repo, tokenRepo
+			},
+			code: code,
+		},
+		{
+			name: "totp code replayed",
+			mock: func(ctrl *gomock.Controller) (repository.MFARepository, repository.TokenRepository) {
+				repo := repov1mocks.NewMockMFARepository(ctrl)
+				repo.EXPECT().Failures(gomock.Any(), int64(1)).Return(0, nil)
+				repo.EXPECT().FindByUid(gomock.Any(), int64(1)).
+					Return(domain.MFA{Uid: 1, Secret: secret, Enabled: true}, nil)
+				repo.EXPECT().UseStep(gomock.Any(), int64(1), gomock.Any()).Return(false, nil)
+				repo.EXPECT().RecordFailure(gomock.Any(), int64(1)).Return(1, nil)
+				tokenRepo := repov1mocks.NewMockTokenRepository(ctrl)
+				tokenRepo.EXPECT().Check(gomock.Any(), "mfa_login", int64(1), "token_id").Return(nil)
+				return repo, tokenRepo
+			},
+			code:    code,
+			wantErr: ErrInvalidMFACode,
+		},
+		{
+			name: "recovery code",
+			mock: func(ctrl *gomock.Controller) (repository.MFARepository, repository.TokenRepository) {
+				repo := repov1mocks.NewMockMFARepository(ctrl)
+				repo.EXPECT().Failures(gomock.Any(), int64(1)).Return(0, nil)
+				repo.EXPECT().FindByUid(gomock.Any(), int64(1)).
+					Return(domain.MFA{Uid: 1, Secret: secret, Enabled: true}, nil)
+				repo.EXPECT().UseRecoveryCode(gomock.Any(), int64(1), gomock.Any()).Return(nil)
+				repo.EXPECT().ResetFailures(gomock.Any(), int64(1)).Return(nil)
+				tokenRepo := repov1mocks.NewMockTokenRepository(ctrl)
+				tokenRepo.EXPECT().Check(gomock.Any(), "mfa_login", int64(1), "token_id").Return(nil)
+				tokenRepo.EXPECT().Consume(gomock.Any(), "mfa_login", int64(1), "token_id").Return(nil)
+				return repo, tokenRepo
+			},
+			code: "ABCDEFGHJK",
+		},
+		{
+			name: "used recovery code",
+			mock: func(ctrl *gomock.Controller) (repository.MFARepository, repository.TokenRepository) {
+				repo := repov1mocks.NewMockMFARepository(ctrl)
+				repo.EXPECT().Failures(gomock.Any(), int64(1)).Return(0, nil)
+				repo.EXPECT().FindByUid(gomock.Any(), int64(1)).
+					Return(domain.MFA{Uid: 1, Secret: secret, Enabled: true}, nil)
+				repo.EXPECT().UseRecoveryCode(gomock.Any(), int64(1), gomock.Any()).
+					Return(repository.ErrRecoveryCodeNotFound)
+				repo.EXPECT().RecordFailure(gomock.Any(), int64(1)).Return(1, nil)
+				tokenRepo := repov1mocks.NewMockTokenRepository(ctrl)
+				tokenRepo.EXPECT().Check(gomock.Any(), "mfa_login", int64(1), "token_id").Return(nil)
+				return repo, tokenRepo
+			},
+			code:    "ABCDEFGHJK",
+			wantErr: ErrInvalidMFACode,
+		},
+		{
+			// 令牌不对就不能用掉恢复码
+			name: "token already used",
+			mock: func(ctrl *gomock.Controller) (repository.MFARepository, repository.TokenRepository) {
+				repo := repov1mocks.NewMockMFARepository(ctrl)
+				repo.EXPECT().Failures(gomock.Any(), int64(1)).Return(0, nil)
+				tokenRepo := repov1mocks.NewMockTokenRepository(ctrl)
+				tokenRepo.EXPECT().Check(gomock.Any(), "mfa_login", int64(1), "token_id").
+					Return(repository.ErrTokenInvalid)
+				return repo, tokenRepo
+			},
+			code:    "ABCDEFGHJK",
+			wantErr: ErrMFATokenInvalid,
+		},
+		{
+			name: "last failure invalidates token",
+			mock: func(ctrl *gomock.Controller) (repository.MFARepository, repository.TokenRepository) {
+				repo := repov1mocks.NewMockMFARepository(ctrl)
+				repo.EXPECT().Failures(gomock.Any(), int64(1)).Return(4, nil)
+				repo.EXPECT().FindByUid(gomock.Any(), int64(1)).
+					Return(domain.MFA{Uid: 1, Secret: secret, Enabled: true}, nil)
+				repo.EXPECT().UseRecoveryCode(gomock.Any(), int64(1), gomock.Any()).
+					Return(repository.ErrRecoveryCodeNotFound)
+				repo.EXPECT().RecordFailure(gomock.Any(), int64(1)).Return(5, nil)
+				tokenRepo := repov1mocks.NewMockTokenRepository(ctrl)
+				tokenRepo.EXPECT().Check(gomock.Any(), "mfa_login", int64(1), "token_id").Return(nil)
+				tokenRepo.EXPECT().Consume(gomock.Any(), "mfa_login", int64(1), "token_id").Return(nil)
+				return repo, tokenRepo
+			},
+			code:    "000000",
+			wantErr: ErrMFATooManyAttempts,
+		},
+		{
+			name: "locked",
+			mock: func(ctrl *gomock.Controller) (repository.MFARepository, repository.TokenRepository) {
+				repo := repov1mocks.NewMockMFARepository(ctrl)
+				repo.EXPECT().Failures(gomock.Any(), int64(1)).Return(5, nil)
+				return repo, repov1mocks.NewMockTokenRepository(ctrl)
+			},
+			// 就算验证码是对的也不行
+			code:    code,
+			wantErr: ErrMFATooManyAttempts,
+		},
+		{
+			name: "not enabled",
+			mock: func(ctrl *gomock.Controller) (repository.MFARepository, repository.TokenRepository) {
+				repo := repov1mocks.NewMockMFARepository(ctrl)
+				repo.EXPECT().Failures(gomock.Any(), int64(1)).Return(0, nil)
+				repo.EXPECT().FindByUid(gomock.Any(), int64(1)).
+					Return(domain.MFA{Uid: 1, Secret: secret}, nil)
+				tokenRepo := repov1mocks.NewMockTokenRepository(ctrl)
+				tokenRepo.EXPECT().Check(gomock.Any(), "mfa_login", int64(1), "token_id").Return(nil)
+				return repo, tokenRepo
+			},
+			code:    code,
+			wantErr: ErrMFANotEnrolled,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewMFAService(tc.mock(ctrl))
+			err := svc.Verify(context.Background(), 1, "token_id", tc.code)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package wechat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"webook/internal/domain"
+)
+
+// 扫码登录的页面，是前端跳转过去的，不需要替换
+const authURLPattern = "https://open.weixin.qq.com/connect/qrconnect?appid=%s&redirect_uri=%s&response_type=code&scope=snsapi_login&state=%s#wechat_redirect"
+
+type Service interface {
+	AuthURL(ctx context.Context, state string) (string, error)
+	// VerifyCode 用回调带回来的 code 换 access token，顺便拿到 openid 和 unionid
+	VerifyCode(ctx context.Context, code string) (domain.WechatInfo, error)
+}
+
+type service struct {
+	appID     string
+	appSecret string
+	// 微信回调我们的地址
+	redirectURL string
+	// 微信 API 的地址，测试的时候换成本地的服务器
+	baseURL string
+	client  *http.Client
+}
+
+func NewService(appID, appSecret, redirectURL, baseURL string, client *http.Client) Service {
+	return &service{
+		appID:       appID,
+		appSecret:   appSecret,
+		redirectURL: redirectURL,
+		baseURL:     baseURL,
+		client:      client,
+	}
+}
+
+func (s *service) AuthURL(ctx context.Context, state string) (string, error) {
+	return fmt.Sprintf(authURLPattern, s.appID, url.QueryEscape(s.redirectURL), state), nil
+}
+
+func (s *service) VerifyCode(ctx context.Context, code string) (domain.WechatInfo, error) {
+	query := url.Values{}
+	query.Set("appid", s.appID)
+	query.Set("secret", s.appSecret)
+	query.Set("code", code)
+	query.Set("grant_type", "authorization_code")
+	target := fmt.Sprintf("%s/sns/oauth2/access_token?%s", s.baseURL, query.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return domain.WechatInfo{}, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return domain.WechatInfo{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return domain.WechatInfo{}, fmt.Errorf("调用微信接口失败，HTTP 状态码 %d", resp.StatusCode)
+	}
+
+	var res Result
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return domain.WechatInfo{}, err
+	}
+	// 微信出错的时候 HTTP 状态码也是 200
+	if res.ErrCode != 0 {
+		return domain.WechatInfo{}, fmt.Errorf("调用微信接口失败 errcode %d, errmsg %s", res.ErrCode, res.ErrMsg)
+	}
+	return domain.WechatInfo{
+		OpenId:  res.OpenId,
+		UnionId: res.UnionId,
+	}, nil
+}
+
+type Result struct {
+	AccessToken  string `json:"access_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+	OpenId       string `json:"openid"`
+	Scope        string `json:"scope"`
+	UnionId      string `json:"unionid"`
+
+	ErrCode int64  `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+}
@@ -0,0 +1,74 @@
+package wechat
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"webook/internal/domain"
+)
+
+func TestService_AuthURL(t *testing.T) {
+	svc := NewService("appid-1", "secret", "https://webook.com/oauth2/wechat/callback",
+		"", http.DefaultClient)
+	u, err := svc.AuthURL(context.Background(), "state-1")
+	require.NoError(t, err)
+	assert.Equal(t, "https://open.weixin.qq.com/connect/qrconnect?appid=appid-1&redirect_uri=https%3A%2F%2Fwebook.com%2Foauth2%2Fwechat%2Fcallback&response_type=code&scope=snsapi_login&state=state-1#wechat_redirect", u)
+}
+
+func TestService_VerifyCode(t *testing.T) {
+	testCases := []struct {
+		name     string
+		handler  http.HandlerFunc
+		wantInfo domain.WechatInfo
+		wantErr  error
+	}{
+		{
+			name: "success",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/sns/oauth2/access_token", r.URL.Path)
+				assert.Equal(t, url.Values{
+					"appid":      []string{"appid-1"},
+					"secret":     []string{"secret"},
+					"code":       []string{"code-1"},
+					"grant_type": []string{"authorization_code"},
+				}, r.URL.Query())
+				_, _ = w.Write([]byte(`{"access_token":"at","expires_in":7200,"refresh_token":"rt","openid":"openid-1","scope":"snsapi_login","unionid":"unionid-1"}`))
+			},
+			wantInfo: domain.WechatInfo{
+				OpenId:  "openid-1",
+				UnionId: "unionid-1",
+			},
+		},
+		{
+			name: "invalid code",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(`{"errcode":40029,"errmsg":"invalid code"}`))
+			},
+			wantErr: errors.New("调用微信接口失败 errcode 40029, errmsg invalid code"),
+		},
+		{
+			name: "http error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+			},
+			wantErr: errors.New("调用微信接口失败，HTTP 状态码 502"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(tc.handler)
+			defer server.Close()
+			svc := NewService("appid-1", "secret", "https://webook.com/oauth2/wechat/callback",
+				server.URL, server.Client())
+			info, err := svc.VerifyCode(context.Background(), "code-1")
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantInfo, info)
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository"
+	"webook/pkg/email"
+)
+
+var ErrInvalidResetToken = errors.New("invalid or expired reset token")
+
+const tokenBizResetPassword = "reset_password"
+
+type PasswordResetService interface {
+	// SendResetEmail 给邮箱发重置密码的链接，邮箱没注册也不报错，避免被人用来探测账号
+	SendResetEmail(ctx context.Context, email string) error
+	// Reset 校验令牌并且修改密码，返回被重置的用户 id
+	Reset(ctx context.Context, token, password string) (int64, error)
+}
+
+// ResetClaims 重置密码令牌里面的内容，ID 用来保证只能用一次
+type ResetClaims struct {
+	Uid int64
+	jwt.RegisteredClaims
+}
+
+type passwordResetService struct {
+	repo     repository.TokenRepository
+	userRepo repository.UserRepository
+	emailSvc email.Service
+	key      []byte
+	// 前端重置密码的页面
+	resetURL   string
+	expiration time.Duration
+	// 只给验证过的邮箱发重置链接
+	requireVerifiedEmail bool
+}
+
+func NewPasswordResetService(repo repository.TokenRepository,
+	userRepo repository.UserRepository, emailSvc email.Service) PasswordResetService {
+	return &passwordResetService{
+		repo:       repo,
+		userRepo:   userRepo,
+		emailSvc:   emailSvc,
+		key:        []byte("Qm3Vx8zL2wPn6RtY9cJ4hK7sD1fG5aB0"),
+		resetURL:   "http://localhost:3000/users/reset_password",
+		expiration: time.Minute * 30,
+		// 老用户注册的时候还没有邮箱验证，全部验证完之前先不打开
+		requireVerifiedEmail: false,
+	}
+}
+
+func (svc *passwordResetService) SendResetEmail(ctx context.Context, email string) error {
+	u, err := svc.userRepo.FindByEmail(ctx, email)
+	if err == repository.ErrUserNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if svc.requireVerifiedEmail && !u.HasVerifiedEmail() {
+		return nil
+	}
+	tokenId := uuid.New().String()
+	err = svc.repo.Store(ctx, tokenBizResetPassword, u.Id, tokenId, svc.expiration)
+	if err != nil {
+		return err
+	}
+	token, err := svc.signToken(u.Id, tokenId)
+	if err != nil {
+		return err
+	}
+	body := fmt.Sprintf("点击下面的链接重置密码，%d 分钟内有效：\n%s?token=%s",
+		int(svc.expiration.Minutes()), svc.resetURL, token)
+	return svc.emailSvc.Send(ctx, u.Email, "重置 webook 密码", body)
+}
+
+func (svc *passwordResetService) Reset(ctx context.Context, token, password string) (int64, error) {
+	var rc ResetClaims
+	t, err := jwt.ParseWithClaims(token, &rc, func(token *jwt.Token) (interface{}, error) {
+		return svc.key, nil
+	})
+	if err != nil || !t.Valid || rc.Uid == 0 {
+		return 0, ErrInvalidResetToken
+	}
+	err = svc.repo.Consume(ctx, tokenBizResetPassword, rc.Uid, rc.ID)
+	if err == repository.ErrTokenInvalid {
+		return 0, ErrInvalidResetToken
+	}
+	if err != nil {
+		return 0, err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return 0, err
+	}
+	err = svc.userRepo.UpdateNonZeroFields(ctx, domain.User{
+		Id:       rc.Uid,
+		Password: string(hash),
+	})
+	return rc.Uid, err
+}
+
+func (svc *passwordResetService) signToken(uid int64, tokenId string) (string, error) {
+	claims := ResetClaims{
+		Uid: uid,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenId,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(svc.expiration)),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS512, claims).SignedString(svc.key)
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/crypto/bcrypt"
+	"testing"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository"
+	repov1mocks "webook/internal/repository/mocks"
+	"webook/pkg/email/localemail"
+)
+
+func Test_passwordResetService_SendAndReset(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := repov1mocks.NewMockTokenRepository(ctrl)
+	userRepo := repov1mocks.NewMockUserRepository(ctrl)
+	emailSvc := localemail.NewService()
+	svc := NewPasswordResetService(repo, userRepo, emailSvc)
+
+	var tokenId string
+	userRepo.EXPECT().FindByEmail(gomock.Any(), "123@qq.com").
+		Return(domain.User{Id: 1, Email: "123@qq.com"}, nil)
+	repo.EXPECT().Store(gomock.Any(), "reset_password", int64(1), gomock.Any(), time.Minute*30).
+		DoAndReturn(func(ctx context.Context, biz string, uid int64, id string, exp time.Duration) error {
+			tokenId = id
+			return nil
+		})
+	err := svc.SendResetEmail(context.Background(), "123@qq.com")
+	require.NoError(t, err)
+	mails := emailSvc.Mails()
+	require.Len(t, mails, 1)
+	assert.Equal(t, "123@qq.com", mails[0].To)
+	token := tokenFromMail(t, mails[0].Body)
+
+	repo.EXPECT().Consume(gomock.Any(), "reset_password", int64(1), tokenId).Return(nil)
+	userRepo.EXPECT().UpdateNonZeroFields(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, u domain.User) error {
+			assert.Equal(t, int64(1), u.Id)
+			assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(u.Password), []byte("hello#world123")))
+			return nil
+		})
+	uid, err := svc.Reset(context.Background(), token, "hello#world123")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), uid)
+
+	// 第二次用同一个令牌
+	repo.EXPECT().Consume(gomock.Any(), "reset_password", int64(1), tokenId).Return(repository.ErrTokenInvalid)
+	_, err = svc.Reset(context.Background(), token, "hello#world123")
+	assert.Equal(t, ErrInvalidResetToken, err)
+}
+
+func Test_passwordResetService_SendResetEmail_UnknownEmail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userRepo := repov1mocks.NewMockUserRepository(ctrl)
+	userRepo.EXPECT().FindByEmail(gomock.Any(), "nobody@qq.com").
+		Return(domain.User{}, repository.ErrUserNotFound)
+	emailSvc := localemail.NewService()
+	svc := NewPasswordResetService(repov1mocks.NewMockTokenRepository(ctrl), userRepo, emailSvc)
+	err := svc.SendResetEmail(context.Background(), "nobody@qq.com")
+	assert.NoError(t, err)
+	assert.Empty(t, emailSvc.Mails())
+}
+
+func Test_passwordResetService_Reset_BadToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	svc := NewPasswordResetService(repov1mocks.NewMockTokenRepository(ctrl),
+		repov1mocks.NewMockUserRepository(ctrl), localemail.NewService())
+	_, err := svc.Reset(context.Background(), "not-a-token", "hello#world123")
+	assert.Equal(t, ErrInvalidResetToken, err)
+}
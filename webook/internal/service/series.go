@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"webook/internal/domain"
+	"webook/internal/repository"
+)
+
+var (
+	ErrSeriesNotFound = repository.ErrSeriesNotFound
+	// ErrSeriesInvalidArticle 只能把自己已经发表的文章加到系列里面
+	ErrSeriesInvalidArticle = repository.ErrSeriesInvalidArticle
+	ErrArticleInOtherSeries = repository.ErrArticleInOtherSeries
+)
+
+// SeriesService 作者把发表过的文章按顺序组织成系列，和读者的收藏夹没有关系
+type SeriesService interface {
+	Create(ctx context.Context, s domain.Series) (int64, error)
+	// Update 只改标题和简介
+	Update(ctx context.Context, s domain.Series) error
+	Delete(ctx context.Context, uid int64, sid int64) error
+	// SetArticles 按照 aids 的顺序覆盖系列里面的文章
+	SetArticles(ctx context.Context, uid int64, sid int64, aids []int64) error
+	// Detail 不需要登录也能看
+	Detail(ctx context.Context, sid int64) (domain.Series, error)
+	ListByAuthor(ctx context.Context, uid int64, offset int, limit int) ([]domain.Series, error)
+	// Nav 文章在系列里面的上一篇下一篇，不在任何系列里面返回 false
+	Nav(ctx context.Context, aid int64) (domain.SeriesNav, bool, error)
+}
+
+type seriesService struct {
+	repo repository.SeriesRepository
+}
+
+func NewSeriesService(repo repository.SeriesRepository) SeriesService {
+	return &seriesService{
+		repo: repo,
+	}
+}
+
+func (svc *seriesService) Create(ctx context.Context, s domain.Series) (int64, error) {
+	return svc.repo.Create(ctx, s)
+}
+
+func (svc *seriesService) Update(ctx context.Context, s domain.Series) error {
+	return svc.repo.Update(ctx, s)
+}
+
+func (svc *seriesService) Delete(ctx context.Context, uid int64, sid int64) error {
+	return svc.repo.Delete(ctx, uid, sid)
+}
+
+func (svc *seriesService) SetArticles(ctx context.Context, uid int64, sid int64, aids []int64) error {
+	return svc.repo.SetArticles(ctx, uid, sid, aids)
+}
+
+func (svc *seriesService) Detail(ctx context.Context, sid int64) (domain.Series, error) {
+	return svc.repo.GetById(ctx, sid)
+}
+
+func (svc *seriesService) ListByAuthor(ctx context.Context, uid int64, offset int, limit int) ([]domain.Series, error) {
+	return svc.repo.GetByAuthor(ctx, uid, offset, limit)
+}
+
+func (svc *seriesService) Nav(ctx context.Context, aid int64) (domain.SeriesNav, bool, error) {
+	sid, err := svc.repo.GetSid(ctx, aid)
+	if err != nil || sid == 0 {
+		return domain.SeriesNav{}, false, err
+	}
+	s, err := svc.repo.GetById(ctx, sid)
+	switch err {
+	case nil:
+		nav, ok := s.Nav(aid)
+		return nav, ok, nil
+	case ErrSeriesNotFound:
+		// 系列刚刚被删掉
+		return domain.SeriesNav{}, false, nil
+	default:
+		return domain.SeriesNav{}, false, err
+	}
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"webook/internal/domain"
+	"webook/internal/repository"
+	repov1mocks "webook/internal/repository/mocks"
+)
+
+func Test_seriesService_Nav(t *testing.T) {
+	series := domain.Series{
+		Id:    1,
+		Title: "Go 入门",
+		Articles: []domain.SeriesArticle{
+			{Id: 11, Title: "第一篇"},
+			{Id: 12, Title: "第二篇"},
+			{Id: 13, Title: "第三篇"},
+		},
+	}
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) repository.SeriesRepository
+		aid     int64
+		wantNav domain.SeriesNav
+		wantOk  bool
+		wantErr error
+	}{
+		{
+			name: "中间的文章",
+			mock: func(ctrl *gomock.Controller) repository.SeriesRepository {
+				repo := repov1mocks.NewMockSeriesRepository(ctrl)
+				repo.EXPECT().GetSid(gomock.Any(), int64(12)).Return(int64(1), nil)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(series, nil)
+				return repo
+			},
+			aid: 12,
+			wantNav: domain.SeriesNav{Sid: 1, Title: "Go 入门", Index: 2, Total: 3,
+				Prev: domain.SeriesArticle{Id: 11, Title: "第一篇"},
+				Next: domain.SeriesArticle{Id: 13, Title: "第三篇"}},
+			wantOk: true,
+		},
+		{
+			name: "第一篇没有上一篇",
+			mock: func(ctrl *gomock.Controller) repository.SeriesRepository {
+				repo := repov1mocks.NewMockSeriesRepository(ctrl)
+				repo.EXPECT().GetSid(gomock.Any(), int64(11)).Return(int64(1), nil)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(series, nil)
+				return repo
+			},
+			aid: 11,
+			wantNav: domain.SeriesNav{Sid: 1, Title: "Go 入门", Index: 1, Total: 3,
+				Next: domain.SeriesArticle{Id: 12, Title: "第二篇"}},
+			wantOk: true,
+		},
+		{
+			name: "不在系列里面",
+			mock: func(ctrl *gomock.Controller) repository.SeriesRepository {
+				repo := repov1mocks.NewMockSeriesRepository(ctrl)
+				repo.EXPECT().GetSid(gomock.Any(), int64(20)).Return(int64(0), nil)
+				return repo
+			},
+			aid: 20,
+		},
+		{
+			// 系列缓存里面还没有这篇文章，比如刚刚撤回
+			name: "系列里面没有这篇文章",
+			mock: func(ctrl *gomock.Controller) repository.SeriesRepository {
+				repo := repov1mocks.NewMockSeriesRepository(ctrl)
+				repo.EXPECT().GetSid(gomock.Any(), int64(14)).Return(int64(1), nil)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).Return(series, nil)
+				return repo
+			},
+			aid: 14,
+		},
+		{
+			name: "系列刚刚被删掉",
+			mock: func(ctrl *gomock.Controller) repository.SeriesRepository {
+				repo := repov1mocks.NewMockSeriesRepository(ctrl)
+				repo.EXPECT().GetSid(gomock.Any(), int64(12)).Return(int64(1), nil)
+				repo.EXPECT().GetById(gomock.Any(), int64(1)).
+					Return(domain.Series{}, repository.ErrSeriesNotFound)
+				return repo
+			},
+			aid: 12,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewSeriesService(tc.mock(ctrl))
+			nav, ok, err := svc.Nav(context.Background(), tc.aid)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantOk, ok)
+			assert.Equal(t, tc.wantNav, nav)
+		})
+	}
+}
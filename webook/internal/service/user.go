@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"github.com/ecodeclub/ekit/slice"
 	"golang.org/x/crypto/bcrypt"
 	"webook/internal/domain"
 	"webook/internal/repository"
@@ -10,21 +11,33 @@ import (
 
 var (
 	ErrUserDuplicatedEmail   = repository.ErrUserDuplicated
+	ErrUserNotFound          = repository.ErrUserNotFound
 	ErrInvalidUserOrPassword = errors.New("invalid email or password")
+	ErrInvalidRole           = errors.New("invalid role")
 )
 
 type UserService interface {
 	SignUp(ctx context.Context, u domain.User) error
-	LogIn(ctx context.Context, u domain.User) (domain.User, error)
-	//Profile(ctx context.Context, id int64) (domain.User, error)
+	// LogIn 邮箱密码登录，ip 用来做防暴力破解
+	LogIn(ctx context.Context, u domain.User, ip string) (domain.User, error)
+	// UnlockAccount 用邮件里面的链接解锁因为登录失败太多被锁定的账号
+	UnlockAccount(ctx context.Context, token string) error
+	FindOrCreate(ctx context.Context, phone string) (domain.User, error)
+	FindOrCreateByWechat(ctx context.Context, info domain.WechatInfo) (domain.User, error)
+	Profile(ctx context.Context, id int64) (domain.User, error)
+	UpdateNonSensitiveInfo(ctx context.Context, u domain.User) error
+	// UpdateRoles 覆盖用户的全部角色，RoleReader 总是会带上
+	UpdateRoles(ctx context.Context, uid int64, roles []domain.Role) error
 }
 type userService struct {
-	repo repository.UserRepository
+	repo  repository.UserRepository
+	guard LoginGuard
 }
 
-func NewUserService(repo repository.UserRepository) UserService {
+func NewUserService(repo repository.UserRepository, guard LoginGuard) UserService {
 	return &userService{
-		repo: repo,
+		repo:  repo,
+		guard: guard,
 	}
 }
 
@@ -54,12 +67,20 @@ func (svc *userService) SignUp(ctx context.Context, u domain.User) error {
 
 }
 
-func (svc *userService) LogIn(ctx context.Context, u domain.User) (domain.User, error) {
+func (svc *userService) LogIn(ctx context.Context, u domain.User, ip string) (domain.User, error) {
+	// 被锁定或者失败太多次了，连密码都不校验
+	err := svc.guard.Check(ctx, u.Email, ip)
+	if err != nil {
+		return domain.User{}, err
+	}
+
 	//先找用户
 
 	foundUser, err := svc.repo.FindByEmail(ctx, u.Email)
 
 	if err == repository.ErrUserNotFound {
+		// 不存在的账号也要计数，不然可以用来探测账号
+		_ = svc.guard.OnFailure(ctx, u.Email, ip)
 		return domain.User{}, ErrInvalidUserOrPassword
 	}
 
@@ -70,10 +91,50 @@ func (svc *userService) LogIn(ctx context.Context, u domain.User) (domain.User,
 	// 比较密码
 	err = bcrypt.CompareHashAndPassword([]byte(foundUser.Password), []byte(u.Password))
 	if err != nil {
-		//debug
+		// 记录失败的时候出错了也只能算了，下次 Check 的时候 Redis 有问题会拦住
+		_ = svc.guard.OnFailure(ctx, u.Email, ip)
 		return domain.User{}, ErrInvalidUserOrPassword
 	}
-	return foundUser, err
+	_ = svc.guard.OnSuccess(ctx, u.Email)
+	return foundUser, nil
+}
+
+func (svc *userService) UnlockAccount(ctx context.Context, token string) error {
+	return svc.guard.Unlock(ctx, token)
+}
+
+func (svc *userService) FindOrCreate(ctx context.Context, phone string) (domain.User, error) {
+	// 快路径，绝大部分请求都是老用户
+	u, err := svc.repo.FindByPhone(ctx, phone)
+	if err != repository.ErrUserNotFound {
+		// 找到了，或者系统错误
+		return u, err
+	}
+	// 慢路径，手机号第一次登录，直接注册
+	err = svc.repo.Create(ctx, domain.User{
+		Phone: phone,
+	})
+	// 唯一索引冲突说明别的请求刚刚注册了，再查一次就好
+	if err != nil && err != repository.ErrUserDuplicated {
+		return domain.User{}, err
+	}
+	// 这里可能有主从延迟的问题
+	return svc.repo.FindByPhone(ctx, phone)
+}
+
+func (svc *userService) FindOrCreateByWechat(ctx context.Context, info domain.WechatInfo) (domain.User, error) {
+	u, err := svc.repo.FindByWechat(ctx, info.OpenId)
+	if err != repository.ErrUserNotFound {
+		return u, err
+	}
+	// 第一次扫码，直接注册
+	err = svc.repo.Create(ctx, domain.User{
+		WechatInfo: info,
+	})
+	if err != nil && err != repository.ErrUserDuplicated {
+		return domain.User{}, err
+	}
+	return svc.repo.FindByWechat(ctx, info.OpenId)
 }
 
 func (svc *userService) Profile(ctx context.Context, id int64) (domain.User, error) {
@@ -88,11 +149,28 @@ func (svc *userService) Profile(ctx context.Context, id int64) (domain.User, err
 	return u, err
 }
 
-//func (svc *userService) UpdateNonSensitiveInfo(ctx context.Context,
-//	user domain.User) error {
-//	// UpdateNicknameAndXXAnd
-//	return svc.repo.UpdateNonZeroFields(ctx, user)
-//}
+func (svc *userService) UpdateNonSensitiveInfo(ctx context.Context, u domain.User) error {
+	// 只挑出允许用户自己改的字段，邮箱、手机号、密码不走这里
+	return svc.repo.UpdateNonZeroFields(ctx, domain.User{
+		Id:       u.Id,
+		Nickname: u.Nickname,
+		Birthday: u.Birthday,
+		AboutMe:  u.AboutMe,
+	})
+}
+
+func (svc *userService) UpdateRoles(ctx context.Context, uid int64, roles []domain.Role) error {
+	res := []domain.Role{domain.RoleReader}
+	for _, r := range roles {
+		if !r.Valid() {
+			return ErrInvalidRole
+		}
+		if r != domain.RoleReader && !slice.Contains(res, r) {
+			res = append(res, r)
+		}
+	}
+	return svc.repo.UpdateRoles(ctx, uid, res)
+}
 
 func (svc *userService) FindById(ctx context.Context, uid int64) (domain.User, error) {
 	return svc.repo.FindById(ctx, uid)
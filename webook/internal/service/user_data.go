@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository"
+	ijwt "webook/pkg/ginx/jwt"
+	"webook/pkg/logger"
+)
+
+var ErrDeletionNotRequested = errors.New("account deletion not requested")
+
+const (
+	// 注销冷静期，这段时间里面还可以撤销
+	deletionGracePeriod = 14 * 24 * time.Hour
+	// 后台每一轮最多清除多少个用户
+	purgeBatchSize = 20
+	// 没有要清除的用户的时候隔多久再看
+	purgeIdleInterval = time.Hour
+)
+
+type UserDataService interface {
+	// Export 导出我们保存的这个用户的全部数据
+	Export(ctx context.Context, uid int64) (domain.UserDataExport, error)
+	// RequestDeletion 申请注销，返回真正清除数据的时间
+	RequestDeletion(ctx context.Context, uid int64) (time.Time, error)
+	CancelDeletion(ctx context.Context, uid int64) error
+	// StartPurgeCycle 后台清除冷静期已经过了的用户，直到 ctx 被取消
+	StartPurgeCycle(ctx context.Context)
+}
+
+type userDataService struct {
+	userRepo repository.UserRepository
+	dataRepo repository.UserDataRepository
+	// 清除之前先把这个用户的登录全部作废
+	jwtHdl ijwt.Handler
+	l      logger.LoggerV1
+}
+
+func NewUserDataService(userRepo repository.UserRepository,
+	dataRepo repository.UserDataRepository, jwtHdl ijwt.Handler, l logger.LoggerV1) UserDataService {
+	return &userDataService{
+		userRepo: userRepo,
+		dataRepo: dataRepo,
+		jwtHdl:   jwtHdl,
+		l:        l,
+	}
+}
+
+func (svc *userDataService) Export(ctx context.Context, uid int64) (domain.UserDataExport, error) {
+	return svc.dataRepo.Export(ctx, uid)
+}
+
+func (svc *userDataService) RequestDeletion(ctx context.Context, uid int64) (time.Time, error) {
+	u, err := svc.userRepo.FindById(ctx, uid)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// 重复申请不会把冷静期往后推
+	if !u.DeleteAfter.IsZero() {
+		return u.DeleteAfter, nil
+	}
+	deleteAfter := time.Now().Add(deletionGracePeriod)
+	err = svc.userRepo.SetDeleteAfter(ctx, uid, deleteAfter)
+	return deleteAfter, err
+}
+
+func (svc *userDataService) CancelDeletion(ctx context.Context, uid int64) error {
+	u, err := svc.userRepo.FindById(ctx, uid)
+	if err != nil {
+		return err
+	}
+	if u.DeleteAfter.IsZero() {
+		return ErrDeletionNotRequested
+	}
+	return svc.userRepo.SetDeleteAfter(ctx, uid, time.Time{})
+}
+
+func (svc *userDataService) StartPurgeCycle(ctx context.Context) {
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+		if svc.purge(ctx) {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(purgeIdleInterval):
+		}
+	}
+}
+
+// purge 清除一批用户，返回是不是捞满了一批，满了说明可能还有
+func (svc *userDataService) purge(ctx context.Context) bool {
+	us, err := svc.userRepo.FindDueDeletion(ctx, time.Now(), purgeBatchSize)
+	if err != nil {
+		svc.l.Error("查找待清除的用户失败", logger.Error(err))
+		return false
+	}
+	for _, u := range us {
+		// 先踢下线再清数据，清完之后就捞不到这个用户了，没有机会再踢
+		err = svc.jwtHdl.RevokeUser(ctx, u.Id)
+		if err != nil {
+			svc.l.Error("作废待清除用户的登录失败",
+				logger.Int64("uid", u.Id),
+				logger.Error(err))
+			return false
+		}
+		err = svc.dataRepo.Purge(ctx, u.Id)
+		if err != nil {
+			// 下一轮还会捞到它，再试
+			svc.l.Error("清除用户数据失败",
+				logger.Int64("uid", u.Id),
+				logger.Error(err))
+			return false
+		}
+	}
+	return len(us) == purgeBatchSize
+}
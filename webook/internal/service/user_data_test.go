@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/repository"
+	repov1mocks "webook/internal/repository/mocks"
+	jwtmocks "webook/pkg/ginx/jwt/mocks"
+	"webook/pkg/logger"
+)
+
+func Test_userDataService_RequestDeletion(t *testing.T) {
+	pending := time.UnixMilli(time.Now().Add(time.Hour * 24).UnixMilli())
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) repository.UserRepository
+		wantErr error
+		// 期望的清除时间，零值表示新算出来的
+		wantTime time.Time
+	}{
+		{
+			name: "first request",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repov1mocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(123)).Return(domain.User{Id: 123}, nil)
+				repo.EXPECT().SetDeleteAfter(gomock.Any(), int64(123), gomock.Any()).Return(nil)
+				return repo
+			},
+		},
+		{
+			name: "already requested",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repov1mocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(123)).
+					Return(domain.User{Id: 123, DeleteAfter: pending}, nil)
+				return repo
+			},
+			wantTime: pending,
+		},
+		{
+			name: "db error",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repov1mocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindById(gomock.Any(), int64(123)).Return(domain.User{}, errors.New("db error"))
+				return repo
+			},
+			wantErr: errors.New("db error"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewUserDataService(tc.mock(ctrl), repov1mocks.NewMockUserDataRepository(ctrl),
+				jwtmocks.NewMockHandler(ctrl), logger.NewNoOpLogger())
+			deleteAfter, err := svc.RequestDeletion(context.Background(), 123)
+			assert.Equal(t, tc.wantErr, err)
+			if err != nil {
+				return
+			}
+			if !tc.wantTime.IsZero() {
+				assert.Equal(t, tc.wantTime, deleteAfter)
+				return
+			}
+			assert.WithinDuration(t, time.Now().Add(deletionGracePeriod), deleteAfter, time.Minute)
+		})
+	}
+}
+
+func Test_userDataService_CancelDeletion(t *testing.T) {
+	testCases := []struct {
+		name    string
+		user    domain.User
+		wantErr error
+	}{
+		{
+			name: "cancel",
+			user: domain.User{Id: 123, DeleteAfter: time.Now().Add(time.Hour)},
+		},
+		{
+			name:    "not requested",
+			user:    domain.User{Id: 123},
+			wantErr: ErrDeletionNotRequested,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := repov1mocks.NewMockUserRepository(ctrl)
+			repo.EXPECT().FindById(gomock.Any(), int64(123)).Return(tc.user, nil)
+			if tc.wantErr == nil {
+				repo.EXPECT().SetDeleteAfter(gomock.Any(), int64(123), time.Time{}).Return(nil)
+			}
+			svc := NewUserDataService(repo, repov1mocks.NewMockUserDataRepository(ctrl),
+				jwtmocks.NewMockHandler(ctrl), logger.NewNoOpLogger())
+			err := svc.CancelDeletion(context.Background(), 123)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
+
+func Test_userDataService_purge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userRepo := repov1mocks.NewMockUserRepository(ctrl)
+	userRepo.EXPECT().FindDueDeletion(gomock.Any(), gomock.Any(), purgeBatchSize).
+		Return([]domain.User{{Id: 1}, {Id: 2}}, nil)
+	dataRepo := repov1mocks.NewMockUserDataRepository(ctrl)
+	jwtHdl := jwtmocks.NewMockHandler(ctrl)
+	// 先踢下线再清数据
+	gomock.InOrder(
+		jwtHdl.EXPECT().RevokeUser(gomock.Any(), int64(1)).Return(nil),
+		dataRepo.EXPECT().Purge(gomock.Any(), int64(1)).Return(nil),
+		jwtHdl.EXPECT().RevokeUser(gomock.Any(), int64(2)).Return(nil),
+		dataRepo.EXPECT().Purge(gomock.Any(), int64(2)).Return(nil),
+	)
+	svc := NewUserDataService(userRepo, dataRepo, jwtHdl, logger.NewNoOpLogger()).(*userDataService)
+	// 没有捞满一批，说明已经清完了
+	assert.False(t, svc.purge(context.Background()))
+}
+
+func Test_userDataService_purge_RevokeFailed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	userRepo := repov1mocks.NewMockUserRepository(ctrl)
+	userRepo.EXPECT().FindDueDeletion(gomock.Any(), gomock.Any(), purgeBatchSize).
+		Return([]domain.User{{Id: 1}}, nil)
+	jwtHdl := jwtmocks.NewMockHandler(ctrl)
+	jwtHdl.EXPECT().RevokeUser(gomock.Any(), int64(1)).Return(errors.New("redis error"))
+	// 踢不下线就先不清，下一轮还能捞到
+	svc := NewUserDataService(userRepo, repov1mocks.NewMockUserDataRepository(ctrl),
+		jwtHdl, logger.NewNoOpLogger()).(*userDataService)
+	assert.False(t, svc.purge(context.Background()))
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/crypto/bcrypt"
+	"testing"
+	"webook/internal/domain"
+	"webook/internal/repository"
+	repov1mocks "webook/internal/repository/mocks"
+	svcmock "webook/internal/service/mocks"
+)
+
+func Test_userService_FindOrCreate(t *testing.T) {
+	const phone = "15212345678"
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) repository.UserRepository
+		wantUser domain.User
+		wantErr  error
+	}{
+		{
+			name: "existing user",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repov1mocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindByPhone(gomock.Any(), phone).
+					Return(domain.User{Id: 1, Phone: phone}, nil)
+				return repo
+			},
+			wantUser: domain.User{Id: 1, Phone: phone},
+		},
+		{
+			name: "new user",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repov1mocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindByPhone(gomock.Any(), phone).
+					Return(domain.User{}, repository.ErrUserNotFound)
+				repo.EXPECT().Create(gomock.Any(), domain.User{Phone: phone}).Return(nil)
+				repo.EXPECT().FindByPhone(gomock.Any(), phone).
+					Return(domain.User{Id: 2, Phone: phone}, nil)
+				return repo
+			},
+			wantUser: domain.User{Id: 2, Phone: phone},
+		},
+		{
+			name: "created concurrently",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repov1mocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindByPhone(gomock.Any(), phone).
+					Return(domain.User{}, repository.ErrUserNotFound)
+				repo.EXPECT().Create(gomock.Any(), domain.User{Phone: phone}).
+					Return(repository.ErrUserDuplicated)
+				repo.EXPECT().FindByPhone(gomock.Any(), phone).
+					Return(domain.User{Id: 3, Phone: phone}, nil)
+				return repo
+			},
+			wantUser: domain.User{Id: 3, Phone: phone},
+		},
+		{
+			name: "db error",
+			mock: func(ctrl *gomock.Controller) repository.UserRepository {
+				repo := repov1mocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindByPhone(gomock.Any(), phone).
+					Return(domain.User{}, errors.New("mock db error"))
+				return repo
+			},
+			wantErr: errors.New("mock db error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewUserService(tc.mock(ctrl), svcmock.NewMockLoginGuard(ctrl))
+			u, err := svc.FindOrCreate(context.Background(), phone)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantUser, u)
+		})
+	}
+}
+
+func Test_userService_UpdateNonSensitiveInfo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	repo := repov1mocks.NewMockUserRepository(ctrl)
+	// 敏感字段就算传进来了也不能更新
+	repo.EXPECT().UpdateNonZeroFields(gomock.Any(), domain.User{
+		Id:       1,
+		Nickname: "Tom",
+		AboutMe:  "hello",
+	}).Return(nil)
+	svc := NewUserService(repo, svcmock.NewMockLoginGuard(ctrl))
+	err := svc.UpdateNonSensitiveInfo(context.Background(), domain.User{
+		Id:       1,
+		Email:    "123@qq.com",
+		Password: "hello#world123",
+		Phone:    "15212345678",
+		Nickname: "Tom",
+		AboutMe:  "hello",
+	})
+	assert.NoError(t, err)
+}
+
+func Test_userService_LogIn(t *testing.T) {
+	const (
+		email = "123@qq.com"
+		ip    = "127.0.0.1"
+	)
+	hash, err := bcrypt.GenerateFromPassword([]byte("hello#world123"), bcrypt.DefaultCost)
+	require.NoError(t, err)
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) (repository.UserRepository, LoginGuard)
+		password string
+		wantUser domain.User
+		wantErr  error
+	}{
+		{
+			name: "log in success",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, LoginGuard) {
+				guard := svcmock.NewMockLoginGuard(ctrl)
+				guard.EXPECT().Check(gomock.Any(), email, ip).Return(nil)
+				guard.EXPECT().OnSuccess(gomock.Any(), email).Return(nil)
+				repo := repov1mocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindByEmail(gomock.Any(), email).
+					Return(domain.User{Id: 1, Email: email, Password: string(hash)}, nil)
+				return repo, guard
+			},
+			password: "hello#world123",
+			wantUser: domain.User{Id: 1, Email: email, Password: string(hash)},
+		},
+		{
+			name: "wrong password",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, LoginGuard) {
+				guard := svcmock.NewMockLoginGuard(ctrl)
+				guard.EXPECT().Check(gomock.Any(), email, ip).Return(nil)
+				guard.EXPECT().OnFailure(gomock.Any(), email, ip).Return(nil)
+				repo := repov1mocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindByEmail(gomock.Any(), email).
+					Return(domain.User{Id: 1, Email: email, Password: string(hash)}, nil)
+				return repo, guard
+			},
+			password: "wrong#password1",
+			wantErr:  ErrInvalidUserOrPassword,
+		},
+		{
+			name: "unknown email",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, LoginGuard) {
+				guard := svcmock.NewMockLoginGuard(ctrl)
+				guard.EXPECT().Check(gomock.Any(), email, ip).Return(nil)
+				guard.EXPECT().OnFailure(gomock.Any(), email, ip).Return(nil)
+				repo := repov1mocks.NewMockUserRepository(ctrl)
+				repo.EXPECT().FindByEmail(gomock.Any(), email).
+					Return(domain.User{}, repository.ErrUserNotFound)
+				return repo, guard
+			},
+			password: "hello#world123",
+			wantErr:  ErrInvalidUserOrPassword,
+		},
+		{
+			name: "account locked",
+			mock: func(ctrl *gomock.Controller) (repository.UserRepository, LoginGuard) {
+				guard := svcmock.NewMockLoginGuard(ctrl)
+				guard.EXPECT().Check(gomock.Any(), email, ip).Return(ErrAccountLocked)
+				// 锁定了就不去查密码了
+				return repov1mocks.NewMockUserRepository(ctrl), guard
+			},
+			password: "hello#world123",
+			wantErr:  ErrAccountLocked,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := NewUserService(tc.mock(ctrl))
+			u, err := svc.LogIn(context.Background(), domain.User{
+				Email:    email,
+				Password: tc.password,
+			}, ip)
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantUser, u)
+		})
+	}
+}
+
+func Test_userService_UpdateRoles(t *testing.T) {
+	testCases := []struct {
+		name  string
+		roles []domain.Role
+		// nil 表示不会更新
+		wantRoles []domain.Role
+		wantErr   error
+	}{
+		{
+			name:      "reader always kept",
+			roles:     []domain.Role{domain.RoleAdmin},
+			wantRoles: []domain.Role{domain.RoleReader, domain.RoleAdmin},
+		},
+		{
+			name:      "duplicated",
+			roles:     []domain.Role{domain.RoleModerator, domain.RoleReader, domain.RoleModerator},
+			wantRoles: []domain.Role{domain.RoleReader, domain.RoleModerator},
+		},
+		{
+			name:      "empty",
+			wantRoles: []domain.Role{domain.RoleReader},
+		},
+		{
+			name:    "invalid role",
+			roles:   []domain.Role{domain.RoleAuthor, "root"},
+			wantErr: ErrInvalidRole,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			repo := repov1mocks.NewMockUserRepository(ctrl)
+			if tc.wantRoles != nil {
+				repo.EXPECT().UpdateRoles(gomock.Any(), int64(123), tc.wantRoles).Return(nil)
+			}
+			svc := NewUserService(repo, svcmock.NewMockLoginGuard(ctrl))
+			err := svc.UpdateRoles(context.Background(), 123, tc.roles)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
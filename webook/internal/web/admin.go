@@ -0,0 +1,146 @@
+package web
+
+import (
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/service"
+	"webook/internal/web/middleware"
+	ijwt "webook/pkg/ginx/jwt"
+	"webook/pkg/logger"
+)
+
+// AdminHandler 运营和管理员用的接口
+type AdminHandler struct {
+	userSvc service.UserService
+	ijwt.Handler
+	log logger.LoggerV1
+}
+
+func NewAdminHandler(userSvc service.UserService, jwtHdl ijwt.Handler, log logger.LoggerV1) *AdminHandler {
+	return &AdminHandler{
+		userSvc: userSvc,
+		Handler: jwtHdl,
+		log:     log,
+	}
+}
+
+func (h *AdminHandler) RegisterRoutes(server *gin.Engine) {
+	// 整个分组至少要是版主
+	ag := server.Group("/admin",
+		middleware.NewRBACMiddlewareBuilder(domain.RoleModerator, domain.RoleAdmin).Build())
+	ag.GET("/users/:id", h.UserDetail)
+	// 改角色只有管理员可以
+	ag.POST("/users/roles",
+		middleware.NewRBACMiddlewareBuilder(domain.RoleAdmin).Build(), h.UpdateRoles)
+}
+
+func (h *AdminHandler) UserDetail(ctx *gin.Context) {
+	idstr := ctx.Param("id")
+	id, err := strconv.ParseInt(idstr, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Invalid id",
+		})
+		return
+	}
+	u, err := h.userSvc.Profile(ctx, id)
+	switch err {
+	case nil:
+	case service.ErrUserNotFound:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "User not found",
+		})
+		return
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		h.log.Error("查询用户失败",
+			logger.Int64("uid", id),
+			logger.Error(err))
+		return
+	}
+	res := AdminUserVO{
+		Id:       u.Id,
+		Email:    u.Email,
+		Phone:    u.Phone,
+		Nickname: u.Nickname,
+		Roles:    roleNames(u),
+		Ctime:    u.Ctime.Format(time.DateTime),
+	}
+	if !u.DeleteAfter.IsZero() {
+		res.DeleteAfter = u.DeleteAfter.Format(time.DateTime)
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: res,
+	})
+}
+
+func (h *AdminHandler) UpdateRoles(ctx *gin.Context) {
+	type Req struct {
+		Uid   int64    `json:"uid"`
+		Roles []string `json:"roles"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	uc := ctx.MustGet("users").(*ijwt.UserClaims)
+	if req.Uid == uc.Id {
+		// 不能改自己的角色，免得管理员手滑把自己降级了
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Cannot change your own roles",
+		})
+		return
+	}
+	err := h.userSvc.UpdateRoles(ctx, req.Uid, slice.Map(req.Roles, func(idx int, src string) domain.Role {
+		return domain.Role(src)
+	}))
+	if err == service.ErrInvalidRole {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Invalid role",
+		})
+		return
+	}
+	if err == service.ErrUserNotFound {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "User not found",
+		})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		h.log.Error("修改用户角色失败",
+			logger.Int64("uid", req.Uid),
+			logger.Error(err))
+		return
+	}
+	// 旧 token 里面还是原来的角色，直接踢下线让他重新登录
+	err = h.RevokeOtherSessions(ctx, req.Uid, "")
+	if err != nil {
+		h.log.Error("修改角色之后踢下线失败",
+			logger.Int64("uid", req.Uid),
+			logger.Error(err))
+	}
+	h.log.Info("修改用户角色",
+		logger.Int64("operator", uc.Id),
+		logger.Int64("uid", req.Uid),
+		logger.String("roles", strings.Join(req.Roles, ",")))
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "OK",
+	})
+}
@@ -0,0 +1,119 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"webook/internal/domain"
+	"webook/internal/service"
+	svcmock "webook/internal/service/mocks"
+	ijwt "webook/pkg/ginx/jwt"
+	jwtmocks "webook/pkg/ginx/jwt/mocks"
+	"webook/pkg/logger"
+)
+
+func TestAdminHandler_UpdateRoles(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) (service.UserService, ijwt.Handler)
+		roles    []string
+		reqBody  string
+		wantCode int
+		wantBody Result
+	}{
+		{
+			name: "success",
+			mock: func(ctrl *gomock.Controller) (service.UserService, ijwt.Handler) {
+				usersvc := svcmock.NewMockUserService(ctrl)
+				usersvc.EXPECT().UpdateRoles(gomock.Any(), int64(456),
+					[]domain.Role{domain.RoleModerator}).Return(nil)
+				// 改完角色要踢下线
+				jwtHdl := jwtmocks.NewMockHandler(ctrl)
+				jwtHdl.EXPECT().RevokeOtherSessions(gomock.Any(), int64(456), "").Return(nil)
+				return usersvc, jwtHdl
+			},
+			roles:    []string{"reader", "admin"},
+			reqBody:  `{"uid":456,"roles":["moderator"]}`,
+			wantCode: http.StatusOK,
+			wantBody: Result{Msg: "OK"},
+		},
+		{
+			name: "invalid role",
+			mock: func(ctrl *gomock.Controller) (service.UserService, ijwt.Handler) {
+				usersvc := svcmock.NewMockUserService(ctrl)
+				usersvc.EXPECT().UpdateRoles(gomock.Any(), int64(456),
+					[]domain.Role{"root"}).Return(service.ErrInvalidRole)
+				return usersvc, jwtmocks.NewMockHandler(ctrl)
+			},
+			roles:    []string{"reader", "admin"},
+			reqBody:  `{"uid":456,"roles":["root"]}`,
+			wantCode: http.StatusOK,
+			wantBody: Result{Code: 4, Msg: "Invalid role"},
+		},
+		{
+			name: "user not found",
+			mock: func(ctrl *gomock.Controller) (service.UserService, ijwt.Handler) {
+				usersvc := svcmock.NewMockUserService(ctrl)
+				usersvc.EXPECT().UpdateRoles(gomock.Any(), int64(456),
+					[]domain.Role{domain.RoleModerator}).Return(service.ErrUserNotFound)
+				return usersvc, jwtmocks.NewMockHandler(ctrl)
+			},
+			roles:    []string{"reader", "admin"},
+			reqBody:  `{"uid":456,"roles":["moderator"]}`,
+			wantCode: http.StatusOK,
+			wantBody: Result{Code: 4, Msg: "User not found"},
+		},
+		{
+			name: "change own roles",
+			mock: func(ctrl *gomock.Controller) (service.UserService, ijwt.Handler) {
+				return svcmock.NewMockUserService(ctrl), jwtmocks.NewMockHandler(ctrl)
+			},
+			roles:    []string{"reader", "admin"},
+			reqBody:  `{"uid":123,"roles":["reader"]}`,
+			wantCode: http.StatusOK,
+			wantBody: Result{Code: 4, Msg: "Cannot change your own roles"},
+		},
+		{
+			name: "moderator is not enough",
+			mock: func(ctrl *gomock.Controller) (service.UserService, ijwt.Handler) {
+				return svcmock.NewMockUserService(ctrl), jwtmocks.NewMockHandler(ctrl)
+			},
+			roles:    []string{"reader", "moderator"},
+			reqBody:  `{"uid":456,"roles":["admin"]}`,
+			wantCode: http.StatusForbidden,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			usersvc, jwtHdl := tc.mock(ctrl)
+			server := gin.Default()
+			server.Use(func(ctx *gin.Context) {
+				ctx.Set("users", &ijwt.UserClaims{Id: 123, Roles: tc.roles})
+			})
+			NewAdminHandler(usersvc, jwtHdl, logger.NewNoOpLogger()).RegisterRoutes(server)
+			req, err := http.NewRequest(http.MethodPost, "/admin/users/roles",
+				bytes.NewBuffer([]byte(tc.reqBody)))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+
+			server.ServeHTTP(resp, req)
+			assert.Equal(t, tc.wantCode, resp.Code)
+			if tc.wantCode != http.StatusOK {
+				return
+			}
+			var res Result
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantBody, res)
+		})
+	}
+}
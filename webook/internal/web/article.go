@@ -7,28 +7,43 @@ import (
 	"golang.org/x/sync/errgroup"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 	"webook/internal/domain"
 	"webook/internal/service"
+	ijwt "webook/pkg/ginx/jwt"
 	"webook/pkg/logger"
 )
 
-//
+const (
+	// 创作者文章列表一页最多多少条，第一页都能走缓存
+	listMaxLimit = 100
+	// 最新文章列表一页最多多少条
+	latestMaxLimit = 50
+)
 
 type ArticleHandler struct {
-	svc      service.ArticleService
-	interSvc service.InteractiveService
-	biz      string
+	svc       service.ArticleService
+	interSvc  service.InteractiveService
+	followSvc service.FollowService
+	seriesSvc service.SeriesService
+	blockSvc  service.BlockService
+	biz       string
 
 	log logger.LoggerV1
 }
 
-func NewArticleHandler(svc service.ArticleService, interSvc service.InteractiveService, log logger.LoggerV1) *ArticleHandler {
+func NewArticleHandler(svc service.ArticleService, interSvc service.InteractiveService,
+	followSvc service.FollowService, seriesSvc service.SeriesService,
+	blockSvc service.BlockService, log logger.LoggerV1) *ArticleHandler {
 	return &ArticleHandler{
-		svc:      svc,
-		log:      log,
-		interSvc: interSvc,
-		biz:      "articles",
+		svc:       svc,
+		log:       log,
+		interSvc:  interSvc,
+		followSvc: followSvc,
+		seriesSvc: seriesSvc,
+		blockSvc:  blockSvc,
+		biz:       "articles",
 	}
 }
 
@@ -40,12 +55,22 @@ func (handler *ArticleHandler) RegisterRoutes(server *gin.Engine) {
 
 	// 创作者接口
 	group.GET("/detail/:id", handler.Detail)
-	// 按照道理来说，这边就是 GET 方法
-	// /list?offset=?&limit=?
-	group.POST("/list", handler.List)
+	// /list?cursor=?&limit=?&status=?&keyword=?，第一页不带 cursor
+	group.GET("/list", handler.List)
+	handler.registerRevisionRoutes(group)
+	handler.registerScheduleRoutes(group)
+
+	// 不需要登录，登录了会过滤掉自己拉黑的作者，最新发表的文章
+	// /latest?cursor=?&limit=?，第一页不带 cursor
+	group.GET("/latest", handler.Latest)
+
+	// 不需要登录，分类树
+	group.GET("/categories", handler.Categories)
 
 	pub := group.Group("/pub")
 	pub.GET("/:id", handler.PubDetail)
+	// 不需要登录，/tags/:tag?offset=?&limit=?
+	pub.GET("/tags/:tag", handler.PubByTag)
 
 	// 传入一个参数，true 就是点赞, false 就是不点赞
 	pub.POST("/like", handler.Like)
@@ -60,8 +85,8 @@ func (handler *ArticleHandler) Withdraw(ctx *gin.Context) {
 	if err := ctx.Bind(&req); err != nil {
 		return
 	}
-	uc := ctx.MustGet("claims")
-	claims, ok := uc.(*UserClaims)
+	uc := ctx.MustGet("users")
+	claims, ok := uc.(*ijwt.UserClaims)
 	if !ok {
 		ctx.JSON(http.StatusOK, Result{
 			Code: 5,
@@ -74,7 +99,7 @@ func (handler *ArticleHandler) Withdraw(ctx *gin.Context) {
 	err := handler.svc.Withdraw(ctx, domain.Article{
 		Id: req.Id,
 		Author: domain.Author{
-			Id: claims.Uid,
+			Id: claims.Id,
 		},
 	})
 
@@ -85,7 +110,7 @@ func (handler *ArticleHandler) Withdraw(ctx *gin.Context) {
 		})
 		//log
 		handler.log.Error("Publish fail",
-			//logger.Int64("uid", uc.Uid),
+			//logger.Int64("uid", uc.Id),
 			logger.Error(err))
 		return
 	}
@@ -102,9 +127,9 @@ func (handler *ArticleHandler) Edit(ctx *gin.Context) {
 		return
 	}
 
-	//uc := ctx.MustGet("claims").(*UserClaims)
-	uc := ctx.MustGet("claims")
-	claims, ok := uc.(*UserClaims)
+	//uc := ctx.MustGet("users").(*ijwt.UserClaims)
+	uc := ctx.MustGet("users")
+	claims, ok := uc.(*ijwt.UserClaims)
 	if !ok {
 		ctx.JSON(http.StatusOK, Result{
 			Code: 5,
@@ -114,8 +139,15 @@ func (handler *ArticleHandler) Edit(ctx *gin.Context) {
 		return
 	}
 
-	id, err := handler.svc.Save(ctx, req.toDomain(claims.Uid))
-
+	art := req.toDomain(claims.Id)
+	if !handler.validMeta(ctx, art) {
+		return
+	}
+	id, err := handler.svc.Save(ctx, art)
+	if err == service.ErrArticleConflict {
+		handler.conflict(ctx, req.Id)
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusOK, Result{
 			Code: 5,
@@ -123,13 +155,17 @@ func (handler *ArticleHandler) Edit(ctx *gin.Context) {
 		})
 		//log
 		handler.log.Error("Save fail",
-			//logger.Int64("uid", uc.Uid),
+			//logger.Int64("uid", uc.Id),
 			logger.Error(err))
 		return
 	}
 
+	// 保存成功说明版本号刚好加了一，新建的是 1
 	ctx.JSON(http.StatusOK, Result{
-		Data: id,
+		Data: ArticleVersionVO{
+			Id:      id,
+			Version: req.Version + 1,
+		},
 	})
 }
 
@@ -138,8 +174,8 @@ func (handler *ArticleHandler) Publish(ctx *gin.Context) {
 	if err := ctx.Bind(&req); err != nil {
 		return
 	}
-	uc := ctx.MustGet("claims")
-	claims, ok := uc.(*UserClaims)
+	uc := ctx.MustGet("users")
+	claims, ok := uc.(*ijwt.UserClaims)
 	if !ok {
 		ctx.JSON(http.StatusOK, Result{
 			Code: 5,
@@ -149,8 +185,23 @@ func (handler *ArticleHandler) Publish(ctx *gin.Context) {
 		return
 	}
 
-	id, err := handler.svc.Publish(ctx, req.toDomain(claims.Uid))
+	if req.PublishAt < 0 {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Invalid publish time",
+		})
+		return
+	}
 
+	art := req.toDomain(claims.Id)
+	if !handler.validMeta(ctx, art) {
+		return
+	}
+	id, err := handler.svc.Publish(ctx, art)
+	if err == service.ErrArticleConflict {
+		handler.conflict(ctx, req.Id)
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusOK, Result{
 			Code: 5,
@@ -158,24 +209,83 @@ func (handler *ArticleHandler) Publish(ctx *gin.Context) {
 		})
 		//log
 		handler.log.Error("Publish fail",
-			//logger.Int64("uid", uc.Uid),
+			//logger.Int64("uid", uc.Id),
 			logger.Error(err))
 		return
 	}
 
+	// 保存成功说明版本号刚好加了一，新建的是 1
+	ctx.JSON(http.StatusOK, Result{
+		Data: ArticleVersionVO{
+			Id:      id,
+			Version: req.Version + 1,
+		},
+	})
+}
+
+// conflict 把最新的版本号告诉前端
+func (handler *ArticleHandler) conflict(ctx *gin.Context, id int64) {
+	art, err := handler.svc.GetById(ctx, id)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		handler.log.Error("查询冲突文章的最新版本失败",
+			logger.Int64("aid", id),
+			logger.Error(err))
+		return
+	}
 	ctx.JSON(http.StatusOK, Result{
-		Data: id,
+		Code: codeConflict,
+		Msg:  "Article has been modified elsewhere",
+		Data: ArticleVersionVO{
+			Id:      art.Id,
+			Version: art.Version,
+		},
 	})
 }
 
 func (handler *ArticleHandler) List(ctx *gin.Context) {
-	var page Page
-	if err := ctx.Bind(&page); err != nil {
+	type Req struct {
+		Cursor string `form:"cursor"`
+		Limit  int    `form:"limit"`
+		// 不传就是全部状态
+		Status  uint8  `form:"status"`
+		Keyword string `form:"keyword"`
+	}
+	var req Req
+	if err := ctx.BindQuery(&req); err != nil {
+		return
+	}
+	if req.Limit <= 0 || req.Limit > listMaxLimit {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Invalid limit",
+		})
+		return
+	}
+	status := domain.ArticleStatus(req.Status)
+	if status != domain.ArticleStatusUnknown && !status.Valid() {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Invalid status",
+		})
+		return
+	}
+	cursor, err := parseArticleCursor(req.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Invalid cursor",
+		})
 		return
 	}
-	// 我要不要检测一下？
-	uc := ctx.MustGet("user").(UserClaims)
-	arts, err := handler.svc.GetByAuthor(ctx, uc.Uid, page.Offset, page.Limit)
+	uc := ctx.MustGet("users").(*ijwt.UserClaims)
+	arts, err := handler.svc.GetByAuthor(ctx, uc.Id, domain.ArticleFilter{
+		Status:  status,
+		Keyword: strings.TrimSpace(req.Keyword),
+	}, cursor, req.Limit)
 	if err != nil {
 		ctx.JSON(http.StatusOK, Result{
 			Code: 5,
@@ -183,13 +293,13 @@ func (handler *ArticleHandler) List(ctx *gin.Context) {
 		})
 		handler.log.Error("查找文章列表失败",
 			logger.Error(err),
-			logger.Int("offset", page.Offset),
-			logger.Int("limit", page.Limit),
-			logger.Int64("uid", uc.Uid))
+			logger.String("cursor", req.Cursor),
+			logger.Int("limit", req.Limit),
+			logger.Int64("uid", uc.Id))
 		return
 	}
-	ctx.JSON(http.StatusOK, Result{
-		Data: slice.Map[domain.Article, ArticleVO](arts, func(idx int, src domain.Article) ArticleVO {
+	res := ArticlePageVO{
+		Items: slice.Map[domain.Article, ArticleVO](arts, func(idx int, src domain.Article) ArticleVO {
 			return ArticleVO{
 				Id:       src.Id,
 				Title:    src.Title,
@@ -198,11 +308,19 @@ func (handler *ArticleHandler) List(ctx *gin.Context) {
 				//Content:  src.Content,
 				AuthorId: src.Author.Id,
 				// 列表，你不需要
-				Status: src.Status.ToUint8(),
-				Ctime:  src.Ctime.Format(time.DateTime),
-				Utime:  src.Utime.Format(time.DateTime),
+				Status:    src.Status.ToUint8(),
+				Ctime:     src.Ctime.Format(time.DateTime),
+				Utime:     src.Utime.Format(time.DateTime),
+				PublishAt: formatPublishAt(src.PublishAt),
 			}
 		}),
+	}
+	if len(arts) == req.Limit {
+		last := arts[len(arts)-1]
+		res.NextCursor = formatArticleCursor(last.Utime, last.Id)
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: res,
 	})
 }
 
@@ -230,8 +348,8 @@ func (handler *ArticleHandler) Detail(ctx *gin.Context) {
 			logger.Error(err))
 		return
 	}
-	uc := ctx.MustGet("user").(UserClaims)
-	if art.Author.Id != uc.Uid {
+	uc := ctx.MustGet("users").(*ijwt.UserClaims)
+	if art.Author.Id != uc.Id {
 		// 有人在搞鬼
 		ctx.JSON(http.StatusOK, Result{
 			Msg:  "系统错误",
@@ -239,7 +357,7 @@ func (handler *ArticleHandler) Detail(ctx *gin.Context) {
 		})
 		handler.log.Error("非法查询文章",
 			logger.Int64("id", id),
-			logger.Int64("uid", uc.Uid))
+			logger.Int64("uid", uc.Id))
 		return
 	}
 
@@ -251,9 +369,14 @@ func (handler *ArticleHandler) Detail(ctx *gin.Context) {
 		Content:  art.Content,
 		AuthorId: art.Author.Id,
 		// 列表，你不需要
-		Status: art.Status.ToUint8(),
-		Ctime:  art.Ctime.Format(time.DateTime),
-		Utime:  art.Utime.Format(time.DateTime),
+		Status:       art.Status.ToUint8(),
+		Ctime:        art.Ctime.Format(time.DateTime),
+		Utime:        art.Utime.Format(time.DateTime),
+		Version:      art.Version,
+		PublishAt:    formatPublishAt(art.PublishAt),
+		Tags:         art.Tags,
+		CategoryId:   art.CategoryId,
+		CategoryName: categoryName(art.CategoryId),
 	}
 	ctx.JSON(http.StatusOK, Result{Data: vo})
 }
@@ -278,18 +401,18 @@ func (handler *ArticleHandler) PubDetail(ctx *gin.Context) {
 		art  domain.Article
 		intr domain.Interactive
 	)
-	uc := ctx.MustGet("user").(UserClaims)
+	uc := ctx.MustGet("users").(*ijwt.UserClaims)
 	eg.Go(func() error {
 
 		var er error
-		art, er = handler.svc.GetPubById(ctx, id, uc.Uid)
+		art, er = handler.svc.GetPubById(ctx, id, uc.Id)
 		return er
 	})
 
-	//uc := ctx.MustGet("user").(UserClaims)
+	//uc := ctx.MustGet("users").(*ijwt.UserClaims)
 	//eg.Go(func() error {
 	//	var er error
-	//	intr, er = handler.interSvc.Get(ctx, handler.biz, id, uc.Uid)
+	//	intr, er = handler.interSvc.Get(ctx, handler.biz, id, uc.Id)
 	//	return er
 	//})
 
@@ -302,10 +425,52 @@ func (handler *ArticleHandler) PubDetail(ctx *gin.Context) {
 		})
 		handler.log.Error("查询文章失败，系统错误",
 			logger.Int64("aid", id),
-			logger.Int64("uid", uc.Uid),
+			logger.Int64("uid", uc.Id),
+			logger.Error(err))
+		return
+	}
+
+	// 自己拉黑了的作者，文章也不给看
+	blocked, err := handler.blockSvc.Blocked(ctx, uc.Id, art.Author.Id)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Msg:  "系统错误",
+			Code: 5,
+		})
+		handler.log.Error("查询拉黑关系失败",
+			logger.Int64("uid", uc.Id),
+			logger.Int64("author", art.Author.Id),
 			logger.Error(err))
 		return
 	}
+	if blocked {
+		ctx.JSON(http.StatusOK, Result{
+			Msg:  "You have blocked the author",
+			Code: 4,
+		})
+		return
+	}
+
+	// 查不到关注关系不影响看文章
+	followed, err := handler.followSvc.Followed(ctx, uc.Id, art.Author.Id)
+	if err != nil {
+		handler.log.Error("查询关注关系失败",
+			logger.Int64("uid", uc.Id),
+			logger.Int64("author", art.Author.Id),
+			logger.Error(err))
+	}
+
+	// 系列导航查不到也不影响看文章
+	var series *SeriesNavVO
+	nav, ok, err := handler.seriesSvc.Nav(ctx, art.Id)
+	if err != nil {
+		handler.log.Error("查询文章所在系列失败",
+			logger.Int64("aid", art.Id),
+			logger.Error(err))
+	}
+	if ok {
+		series = newSeriesNavVO(nav)
+	}
 
 	go func() {
 		// 1. 如果你想摆脱原本主链路的超时控制，你就创建一个新的
@@ -333,14 +498,88 @@ func (handler *ArticleHandler) PubDetail(ctx *gin.Context) {
 			LikeCnt:    intr.LikeCnt,
 			Liked:      intr.Liked,
 			Collected:  intr.Collected,
-
-			Status: art.Status.ToUint8(),
-			Ctime:  art.Ctime.Format(time.DateTime),
-			Utime:  art.Utime.Format(time.DateTime),
+			Followed:   followed,
+
+			Status:       art.Status.ToUint8(),
+			Ctime:        art.Ctime.Format(time.DateTime),
+			Utime:        art.Utime.Format(time.DateTime),
+			Tags:         art.Tags,
+			CategoryId:   art.CategoryId,
+			CategoryName: categoryName(art.CategoryId),
+			Series:       series,
 		},
 	})
 }
 
+func (handler *ArticleHandler) Latest(ctx *gin.Context) {
+	type Req struct {
+		Cursor string `form:"cursor"`
+		Limit  int    `form:"limit"`
+	}
+	var req Req
+	if err := ctx.BindQuery(&req); err != nil {
+		return
+	}
+	if req.Limit <= 0 || req.Limit > latestMaxLimit {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Invalid limit",
+		})
+		return
+	}
+	cursor, err := parseArticleCursor(req.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Invalid cursor",
+		})
+		return
+	}
+	arts, err := handler.svc.ListPub(ctx, readerId(ctx), cursor, req.Limit)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		handler.log.Error("查询最新文章失败",
+			logger.String("cursor", req.Cursor),
+			logger.Error(err))
+		return
+	}
+	ids := slice.Map(arts, func(idx int, src domain.Article) int64 {
+		return src.Id
+	})
+	// 查不到计数不影响看列表
+	intrs, err := handler.interSvc.GetByIds(ctx, handler.biz, ids)
+	if err != nil {
+		handler.log.Error("批量查询文章计数失败",
+			logger.Error(err))
+	}
+	res := ArticlePageVO{
+		Items: slice.Map(arts, func(idx int, src domain.Article) ArticleVO {
+			intr := intrs[src.Id]
+			return ArticleVO{
+				Id:         src.Id,
+				Title:      src.Title,
+				Abstract:   src.Abstract(),
+				AuthorId:   src.Author.Id,
+				ReadCnt:    intr.ReadCnt,
+				LikeCnt:    intr.LikeCnt,
+				CollectCnt: intr.CollectCnt,
+				Ctime:      src.Ctime.Format(time.DateTime),
+				Utime:      src.Utime.Format(time.DateTime),
+			}
+		}),
+	}
+	if len(arts) == req.Limit {
+		last := arts[len(arts)-1]
+		res.NextCursor = formatArticleCursor(last.Ctime, last.Id)
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: res,
+	})
+}
+
 func (handler *ArticleHandler) Like(c *gin.Context) {
 	type Req struct {
 		Id int64 `json:"id"`
@@ -351,14 +590,20 @@ func (handler *ArticleHandler) Like(c *gin.Context) {
 	if err := c.Bind(&req); err != nil {
 		return
 	}
-	uc := c.MustGet("user").(UserClaims)
+	uc := c.MustGet("users").(*ijwt.UserClaims)
 	var err error
 	if req.Like {
 		// 点赞
-		err = handler.interSvc.Like(c, handler.biz, req.Id, uc.Uid)
+		err = handler.interSvc.Like(c, handler.biz, req.Id, uc.Id)
 	} else {
 		// 取消点赞
-		err = handler.interSvc.CancelLike(c, handler.biz, req.Id, uc.Uid)
+		err = handler.interSvc.CancelLike(c, handler.biz, req.Id, uc.Id)
+	}
+	if err == service.ErrBlocked {
+		c.JSON(http.StatusOK, Result{
+			Code: 4, Msg: "Blocked by the author",
+		})
+		return
 	}
 	if err != nil {
 		c.JSON(http.StatusOK, Result{
@@ -366,7 +611,7 @@ func (handler *ArticleHandler) Like(c *gin.Context) {
 		})
 		handler.log.Error("点赞/取消点赞失败",
 			logger.Error(err),
-			logger.Int64("uid", uc.Uid),
+			logger.Int64("uid", uc.Id),
 			logger.Int64("aid", req.Id))
 		return
 	}
@@ -384,16 +629,22 @@ func (handler *ArticleHandler) Collect(ctx *gin.Context) {
 	if err := ctx.Bind(&req); err != nil {
 		return
 	}
-	uc := ctx.MustGet("user").(UserClaims)
+	uc := ctx.MustGet("users").(*ijwt.UserClaims)
 
-	err := handler.interSvc.Collect(ctx, handler.biz, req.Id, req.Cid, uc.Uid)
+	err := handler.interSvc.Collect(ctx, handler.biz, req.Id, req.Cid, uc.Id)
+	if err == service.ErrBlocked {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4, Msg: "Blocked by the author",
+		})
+		return
+	}
 	if err != nil {
 		ctx.JSON(http.StatusOK, Result{
 			Code: 5, Msg: "系统错误",
 		})
 		handler.log.Error("收藏失败",
 			logger.Error(err),
-			logger.Int64("uid", uc.Uid),
+			logger.Int64("uid", uc.Id),
 			logger.Int64("aid", req.Id))
 		return
 	}
@@ -401,8 +652,3 @@ func (handler *ArticleHandler) Collect(ctx *gin.Context) {
 		Msg: "OK",
 	})
 }
-
-type Page struct {
-	Limit  int
-	Offset int
-}
@@ -0,0 +1,159 @@
+package web
+
+import (
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"webook/internal/domain"
+	"webook/internal/service"
+	ijwt "webook/pkg/ginx/jwt"
+	"webook/pkg/logger"
+)
+
+// 历史版本列表一页最多多少条
+const revisionListMaxLimit = 50
+
+func (handler *ArticleHandler) registerRevisionRoutes(group *gin.RouterGroup) {
+	rg := group.Group("/revisions")
+	// /:id?offset=?&limit=?
+	rg.GET("/:id", handler.Revisions)
+	// /:id/diff?from=?&to=?
+	rg.GET("/:id/diff", handler.DiffRevisions)
+	rg.POST("/restore", handler.RestoreRevision)
+}
+
+func (handler *ArticleHandler) Revisions(ctx *gin.Context) {
+	aid, ok := handler.articleId(ctx)
+	if !ok {
+		return
+	}
+	type Req struct {
+		Offset int `form:"offset"`
+		Limit  int `form:"limit"`
+	}
+	var req Req
+	if err := ctx.BindQuery(&req); err != nil {
+		return
+	}
+	if req.Offset < 0 || req.Limit <= 0 || req.Limit > revisionListMaxLimit {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Invalid offset or limit",
+		})
+		return
+	}
+	uc := ctx.MustGet("users").(*ijwt.UserClaims)
+	revs, err := handler.svc.ListRevisions(ctx, uc.Id, aid, req.Offset, req.Limit)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		handler.log.Error("查询历史版本失败",
+			logger.Int64("aid", aid),
+			logger.Int64("uid", uc.Id),
+			logger.Error(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: slice.Map(revs, func(idx int, src domain.ArticleRevision) RevisionVO {
+			return newRevisionVO(src)
+		}),
+	})
+}
+
+func (handler *ArticleHandler) DiffRevisions(ctx *gin.Context) {
+	aid, ok := handler.articleId(ctx)
+	if !ok {
+		return
+	}
+	type Req struct {
+		From int64 `form:"from"`
+		To   int64 `form:"to"`
+	}
+	var req Req
+	if err := ctx.BindQuery(&req); err != nil {
+		return
+	}
+	uc := ctx.MustGet("users").(*ijwt.UserClaims)
+	diff, err := handler.svc.DiffRevisions(ctx, uc.Id, aid, req.From, req.To)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Data: RevisionDiffVO{
+				From: newRevisionVO(diff.From),
+				To:   newRevisionVO(diff.To),
+				Lines: slice.Map(diff.Lines, func(idx int, src domain.DiffLine) DiffLineVO {
+					return DiffLineVO{
+						Op:   src.Op.String(),
+						Text: src.Text,
+					}
+				}),
+			},
+		})
+	case service.ErrRevisionNotFound:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Revision not found",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		handler.log.Error("比较历史版本失败",
+			logger.Int64("aid", aid),
+			logger.Int64("uid", uc.Id),
+			logger.Error(err))
+	}
+}
+
+func (handler *ArticleHandler) RestoreRevision(ctx *gin.Context) {
+	type Req struct {
+		Id      int64 `json:"id"`
+		Version int64 `json:"version"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	uc := ctx.MustGet("users").(*ijwt.UserClaims)
+	err := handler.svc.RestoreRevision(ctx, uc.Id, req.Id, req.Version)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "OK",
+		})
+	case service.ErrRevisionNotFound:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Revision not found",
+		})
+	case service.ErrArticleConflict:
+		handler.conflict(ctx, req.Id)
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		handler.log.Error("恢复历史版本失败",
+			logger.Int64("aid", req.Id),
+			logger.Int64("version", req.Version),
+			logger.Int64("uid", uc.Id),
+			logger.Error(err))
+	}
+}
+
+func (handler *ArticleHandler) articleId(ctx *gin.Context) (int64, bool) {
+	idstr := ctx.Param("id")
+	id, err := strconv.ParseInt(idstr, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Msg:  "id 参数错误",
+			Code: 4,
+		})
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,100 @@
+package web
+
+import (
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/service"
+	ijwt "webook/pkg/ginx/jwt"
+	"webook/pkg/logger"
+)
+
+// 定时文章列表一页最多多少条
+const scheduledListMaxLimit = 50
+
+func (handler *ArticleHandler) registerScheduleRoutes(group *gin.RouterGroup) {
+	// 定时发表是 /publish 带上 publishAt
+	// /scheduled?offset=?&limit=?
+	group.GET("/scheduled", handler.Scheduled)
+	group.POST("/scheduled/cancel", handler.CancelSchedule)
+}
+
+func (handler *ArticleHandler) Scheduled(ctx *gin.Context) {
+	type Req struct {
+		Offset int `form:"offset"`
+		Limit  int `form:"limit"`
+	}
+	var req Req
+	if err := ctx.BindQuery(&req); err != nil {
+		return
+	}
+	if req.Offset < 0 || req.Limit <= 0 || req.Limit > scheduledListMaxLimit {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Invalid offset or limit",
+		})
+		return
+	}
+	uc := ctx.MustGet("users").(*ijwt.UserClaims)
+	arts, err := handler.svc.ListScheduled(ctx, uc.Id, req.Offset, req.Limit)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		handler.log.Error("查询定时发表的文章失败",
+			logger.Int64("uid", uc.Id),
+			logger.Error(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: slice.Map(arts, func(idx int, src domain.Article) ArticleVO {
+			return ArticleVO{
+				Id:        src.Id,
+				Title:     src.Title,
+				Abstract:  src.Abstract(),
+				AuthorId:  src.Author.Id,
+				Status:    src.Status.ToUint8(),
+				Ctime:     src.Ctime.Format(time.DateTime),
+				Utime:     src.Utime.Format(time.DateTime),
+				Version:   src.Version,
+				PublishAt: formatPublishAt(src.PublishAt),
+			}
+		}),
+	})
+}
+
+func (handler *ArticleHandler) CancelSchedule(ctx *gin.Context) {
+	type Req struct {
+		Id int64 `json:"id"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	uc := ctx.MustGet("users").(*ijwt.UserClaims)
+	err := handler.svc.CancelSchedule(ctx, uc.Id, req.Id)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "OK",
+		})
+	case service.ErrScheduleNotFound:
+		// 不是自己的文章，或者已经发表了
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Article is not scheduled",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		handler.log.Error("取消定时发表失败",
+			logger.Int64("aid", req.Id),
+			logger.Int64("uid", uc.Id),
+			logger.Error(err))
+	}
+}
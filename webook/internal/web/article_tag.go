@@ -0,0 +1,125 @@
+package web
+
+import (
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"slices"
+	"time"
+	"unicode/utf8"
+	"webook/internal/domain"
+	"webook/pkg/logger"
+)
+
+// 标签页一页最多多少条
+const tagPageMaxLimit = 50
+
+// validMeta 检查归一化之后的标签和分类，不合法就直接返回给前端
+func (handler *ArticleHandler) validMeta(ctx *gin.Context, art domain.Article) bool {
+	var msg string
+	switch {
+	case len(art.Tags) > domain.MaxArticleTags:
+		msg = "Too many tags"
+	case slices.ContainsFunc(art.Tags, func(tag string) bool {
+		return utf8.RuneCountInString(tag) > domain.MaxTagLength
+	}):
+		msg = "Tag too long"
+	case art.CategoryId != 0 && categoryName(art.CategoryId) == "":
+		msg = "Invalid category"
+	default:
+		return true
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 4,
+		Msg:  msg,
+	})
+	return false
+}
+
+func (handler *ArticleHandler) PubByTag(ctx *gin.Context) {
+	type Req struct {
+		Offset int `form:"offset"`
+		Limit  int `form:"limit"`
+	}
+	var req Req
+	if err := ctx.BindQuery(&req); err != nil {
+		return
+	}
+	if req.Offset < 0 || req.Limit <= 0 || req.Limit > tagPageMaxLimit {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Invalid offset or limit",
+		})
+		return
+	}
+	tag := domain.NormalizeTag(ctx.Param("tag"))
+	if tag == "" || utf8.RuneCountInString(tag) > domain.MaxTagLength {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Invalid tag",
+		})
+		return
+	}
+	arts, total, err := handler.svc.ListPubByTag(ctx, readerId(ctx), tag, req.Offset, req.Limit)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		handler.log.Error("按照标签查询文章失败",
+			logger.String("tag", tag),
+			logger.Error(err))
+		return
+	}
+	ids := slice.Map(arts, func(idx int, src domain.Article) int64 {
+		return src.Id
+	})
+	// 查不到计数不影响看列表
+	intrs, err := handler.interSvc.GetByIds(ctx, handler.biz, ids)
+	if err != nil {
+		handler.log.Error("批量查询文章计数失败",
+			logger.Error(err))
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: TagPageVO{
+			Tag:   tag,
+			Total: total,
+			Items: slice.Map(arts, func(idx int, src domain.Article) ArticleVO {
+				intr := intrs[src.Id]
+				return ArticleVO{
+					Id:         src.Id,
+					Title:      src.Title,
+					Abstract:   src.Abstract(),
+					AuthorId:   src.Author.Id,
+					ReadCnt:    intr.ReadCnt,
+					LikeCnt:    intr.LikeCnt,
+					CollectCnt: intr.CollectCnt,
+					Ctime:      src.Ctime.Format(time.DateTime),
+					Utime:      src.Utime.Format(time.DateTime),
+				}
+			}),
+		},
+	})
+}
+
+func (handler *ArticleHandler) Categories(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, Result{
+		Data: newCategoryVOs(domain.Categories(), 0),
+	})
+}
+
+// newCategoryVOs 把 parent 下面的分类组装成树
+func newCategoryVOs(cs []domain.Category, parent int64) []CategoryVO {
+	var res []CategoryVO
+	for _, c := range cs {
+		if c.ParentId != parent {
+			continue
+		}
+		res = append(res, CategoryVO{
+			Id:       c.Id,
+			Name:     c.Name,
+			Children: newCategoryVOs(cs, c.Id),
+		})
+	}
+	return res
+}
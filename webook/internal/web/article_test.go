@@ -0,0 +1,676 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/service"
+	svcmock "webook/internal/service/mocks"
+	ijwt "webook/pkg/ginx/jwt"
+	"webook/pkg/logger"
+)
+
+func TestArticleHandler_Latest(t *testing.T) {
+	// 翻页按照第一次发表的时间，不是最后修改的时间
+	ctime := time.UnixMilli(1700000000123)
+	utime := time.UnixMilli(1800000000000)
+	testCases := []struct {
+		name           string
+		mock           func(ctrl *gomock.Controller) (service.ArticleService, service.InteractiveService)
+		query          string
+		uid            int64
+		wantCode       int
+		wantMsg        string
+		wantItems      []ArticleVO
+		wantNextCursor string
+	}{
+		{
+			name: "first page, has more",
+			mock: func(ctrl *gomock.Controller) (service.ArticleService, service.InteractiveService) {
+				svc := svcmock.NewMockArticleService(ctrl)
+				svc.EXPECT().ListPub(gomock.Any(), int64(0), domain.ArticleCursor{}, 2).
+					Return([]domain.Article{
+						{Id: 8, Title: "a", Content: "aaa", Author: domain.Author{Id: 1}, Ctime: ctime, Utime: utime},
+						{Id: 7, Title: "b", Content: "bbb", Author: domain.Author{Id: 2}, Ctime: ctime, Utime: utime},
+					}, nil)
+				interSvc := svcmock.NewMockInteractiveService(ctrl)
+				interSvc.EXPECT().GetByIds(gomock.Any(), "articles", []int64{8, 7}).
+					Return(map[int64]domain.Interactive{
+						8: {ReadCnt: 10, LikeCnt: 2, CollectCnt: 1},
+					}, nil)
+				return svc, interSvc
+			},
+			query: "limit=2",
+			wantItems: []ArticleVO{
+				{Id: 8, Title: "a", Abstract: "aaa", AuthorId: 1, ReadCnt: 10, LikeCnt: 2, CollectCnt: 1,
+					Ctime: ctime.Format(time.DateTime), Utime: utime.Format(time.DateTime)},
+				{Id: 7, Title: "b", Abstract: "bbb", AuthorId: 2,
+					Ctime: ctime.Format(time.DateTime), Utime: utime.Format(time.DateTime)},
+			},
+			wantNextCursor: "1700000000123_7",
+		},
+		{
+			// 登录了要带上读者，过滤掉拉黑的作者
+			name: "last page, logged in",
+			mock: func(ctrl *gomock.Controller) (service.ArticleService, service.InteractiveService) {
+				svc := svcmock.NewMockArticleService(ctrl)
+				svc.EXPECT().ListPub(gomock.Any(), int64(123), domain.ArticleCursor{Time: 1700000000123, Aid: 7}, 2).
+					Return([]domain.Article{}, nil)
+				interSvc := svcmock.NewMockInteractiveService(ctrl)
+				interSvc.EXPECT().GetByIds(gomock.Any(), "articles", []int64{}).
+					Return(map[int64]domain.Interactive{}, nil)
+				return svc, interSvc
+			},
+			query:     "limit=2&cursor=1700000000123_7",
+			uid:       123,
+			wantItems: []ArticleVO{},
+		},
+		{
+			name: "bad cursor",
+			mock: func(ctrl *gomock.Controller) (service.ArticleService, service.InteractiveService) {
+				return svcmock.NewMockArticleService(ctrl), svcmock.NewMockInteractiveService(ctrl)
+			},
+			query:    "limit=2&cursor=abc",
+			wantCode: 4,
+			wantMsg:  "Invalid cursor",
+		},
+		{
+			name: "limit too large",
+			mock: func(ctrl *gomock.Controller) (service.ArticleService, service.InteractiveService) {
+				return svcmock.NewMockArticleService(ctrl), svcmock.NewMockInteractiveService(ctrl)
+			},
+			query:    "limit=500",
+			wantCode: 4,
+			wantMsg:  "Invalid limit",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, interSvc := tc.mock(ctrl)
+			server := gin.Default()
+			if tc.uid > 0 {
+				server.Use(func(ctx *gin.Context) {
+					ctx.Set("users", &ijwt.UserClaims{Id: tc.uid})
+				})
+			}
+			NewArticleHandler(svc, interSvc, svcmock.NewMockFollowService(ctrl),
+				svcmock.NewMockSeriesService(ctrl), svcmock.NewMockBlockService(ctrl),
+				logger.NewNoOpLogger()).RegisterRoutes(server)
+			req, err := http.NewRequest(http.MethodGet, "/articles/latest?"+tc.query, nil)
+			require.NoError(t, err)
+			resp := httptest.NewRecorder()
+
+			server.ServeHTTP(resp, req)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			var res struct {
+				Code int           `json:"code"`
+				Msg  string        `json:"msg"`
+				Data ArticlePageVO `json:"data"`
+			}
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantCode, res.Code)
+			assert.Equal(t, tc.wantMsg, res.Msg)
+			assert.Equal(t, tc.wantItems, res.Data.Items)
+			assert.Equal(t, tc.wantNextCursor, res.Data.NextCursor)
+		})
+	}
+}
+
+func TestArticleHandler_List(t *testing.T) {
+	utime := time.UnixMilli(1700000000123)
+	testCases := []struct {
+		name           string
+		mock           func(ctrl *gomock.Controller) service.ArticleService
+		query          string
+		wantCode       int
+		wantMsg        string
+		wantIds        []int64
+		wantNextCursor string
+	}{
+		{
+			name: "drafts with keyword",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmock.NewMockArticleService(ctrl)
+				svc.EXPECT().GetByAuthor(gomock.Any(), int64(123), domain.ArticleFilter{
+					Status:  domain.ArticleStatusUnpublished,
+					Keyword: "go",
+				}, domain.ArticleCursor{}, 1).
+					Return([]domain.Article{
+						{Id: 9, Title: "go", Status: domain.ArticleStatusUnpublished, Utime: utime},
+					}, nil)
+				return svc
+			},
+			query:          "limit=1&status=1&keyword=%20go%20",
+			wantIds:        []int64{9},
+			wantNextCursor: "1700000000123_9",
+		},
+		{
+			name: "last page",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmock.NewMockArticleService(ctrl)
+				svc.EXPECT().GetByAuthor(gomock.Any(), int64(123), domain.ArticleFilter{},
+					domain.ArticleCursor{Time: 1700000000123, Aid: 9}, 10).
+					Return([]domain.Article{
+						{Id: 3, Utime: utime},
+					}, nil)
+				return svc
+			},
+			query:   "limit=10&cursor=1700000000123_9",
+			wantIds: []int64{3},
+		},
+		{
+			name: "bad status",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				return svcmock.NewMockArticleService(ctrl)
+			},
+			query:    "limit=10&status=9",
+			wantCode: 4,
+			wantMsg:  "Invalid status",
+		},
+		{
+			name: "limit too large",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				return svcmock.NewMockArticleService(ctrl)
+			},
+			query:    "limit=101",
+			wantCode: 4,
+			wantMsg:  "Invalid limit",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := gin.Default()
+			server.Use(func(ctx *gin.Context) {
+				ctx.Set("users", &ijwt.UserClaims{Id: 123})
+			})
+			NewArticleHandler(tc.mock(ctrl), svcmock.NewMockInteractiveService(ctrl),
+				svcmock.NewMockFollowService(ctrl), svcmock.NewMockSeriesService(ctrl),
+				svcmock.NewMockBlockService(ctrl), logger.NewNoOpLogger()).RegisterRoutes(server)
+			req, err := http.NewRequest(http.MethodGet, "/articles/list?"+tc.query, nil)
+			require.NoError(t, err)
+			resp := httptest.NewRecorder()
+
+			server.ServeHTTP(resp, req)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			var res struct {
+				Code int           `json:"code"`
+				Msg  string        `json:"msg"`
+				Data ArticlePageVO `json:"data"`
+			}
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantCode, res.Code)
+			assert.Equal(t, tc.wantMsg, res.Msg)
+			ids := make([]int64, 0, len(res.Data.Items))
+			for _, item := range res.Data.Items {
+				ids = append(ids, item.Id)
+			}
+			if tc.wantIds != nil {
+				assert.Equal(t, tc.wantIds, ids)
+			}
+			assert.Equal(t, tc.wantNextCursor, res.Data.NextCursor)
+		})
+	}
+}
+
+func TestArticleHandler_DiffRevisions(t *testing.T) {
+	ctime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local)
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) service.ArticleService
+		path     string
+		wantBody Result
+	}{
+		{
+			name: "diff",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmock.NewMockArticleService(ctrl)
+				svc.EXPECT().DiffRevisions(gomock.Any(), int64(123), int64(1), int64(2), int64(3)).
+					Return(domain.RevisionDiff{
+						From: domain.ArticleRevision{Version: 2, Title: "t", Ctime: ctime},
+						To:   domain.ArticleRevision{Version: 3, Title: "t", Ctime: ctime},
+						Lines: []domain.DiffLine{
+							{Op: domain.DiffOpDelete, Text: "old"},
+							{Op: domain.DiffOpInsert, Text: "new"},
+						},
+					}, nil)
+				return svc
+			},
+			path: "/articles/revisions/1/diff?from=2&to=3",
+			wantBody: Result{Data: map[string]any{
+				"from": map[string]any{"version": float64(2), "title": "t",
+					"status": float64(0), "ctime": "2024-01-02 03:04:05"},
+				"to": map[string]any{"version": float64(3), "title": "t",
+					"status": float64(0), "ctime": "2024-01-02 03:04:05"},
+				"lines": []any{
+					map[string]any{"op": "delete", "text": "old"},
+					map[string]any{"op": "insert", "text": "new"},
+				},
+			}},
+		},
+		{
+			name: "revision not found",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmock.NewMockArticleService(ctrl)
+				svc.EXPECT().DiffRevisions(gomock.Any(), int64(123), int64(1), int64(2), int64(9)).
+					Return(domain.RevisionDiff{}, service.ErrRevisionNotFound)
+				return svc
+			},
+			path:     "/articles/revisions/1/diff?from=2&to=9",
+			wantBody: Result{Code: 4, Msg: "Revision not found"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := gin.Default()
+			server.Use(func(ctx *gin.Context) {
+				ctx.Set("users", &ijwt.UserClaims{Id: 123})
+			})
+			NewArticleHandler(tc.mock(ctrl), svcmock.NewMockInteractiveService(ctrl),
+				svcmock.NewMockFollowService(ctrl), svcmock.NewMockSeriesService(ctrl),
+				svcmock.NewMockBlockService(ctrl), logger.NewNoOpLogger()).RegisterRoutes(server)
+			req, err := http.NewRequest(http.MethodGet, tc.path, nil)
+			require.NoError(t, err)
+			resp := httptest.NewRecorder()
+
+			server.ServeHTTP(resp, req)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			var res Result
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantBody, res)
+		})
+	}
+}
+
+func TestArticleHandler_RestoreRevision(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) service.ArticleService
+		wantBody Result
+	}{
+		{
+			name: "恢复成功",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmock.NewMockArticleService(ctrl)
+				svc.EXPECT().RestoreRevision(gomock.Any(), int64(123), int64(1), int64(2)).Return(nil)
+				return svc
+			},
+			wantBody: Result{Msg: "OK"},
+		},
+		{
+			// 恢复的时候别的地方又改了，带上最新的版本号
+			name: "版本冲突",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmock.NewMockArticleService(ctrl)
+				svc.EXPECT().RestoreRevision(gomock.Any(), int64(123), int64(1), int64(2)).
+					Return(service.ErrArticleConflict)
+				svc.EXPECT().GetById(gomock.Any(), int64(1)).
+					Return(domain.Article{Id: 1, Version: 5}, nil)
+				return svc
+			},
+			wantBody: Result{Code: codeConflict, Msg: "Article has been modified elsewhere",
+				Data: map[string]any{"id": float64(1), "version": float64(5)}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := gin.Default()
+			server.Use(func(ctx *gin.Context) {
+				ctx.Set("users", &ijwt.UserClaims{Id: 123})
+			})
+			NewArticleHandler(tc.mock(ctrl), svcmock.NewMockInteractiveService(ctrl),
+				svcmock.NewMockFollowService(ctrl), svcmock.NewMockSeriesService(ctrl),
+				svcmock.NewMockBlockService(ctrl), logger.NewNoOpLogger()).RegisterRoutes(server)
+			req, err := http.NewRequest(http.MethodPost, "/articles/revisions/restore",
+				strings.NewReader(`{"id":1,"version":2}`))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+
+			server.ServeHTTP(resp, req)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			var res Result
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantBody, res)
+		})
+	}
+}
+
+func TestArticleHandler_Edit(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) service.ArticleService
+		reqBody  string
+		wantBody Result
+	}{
+		{
+			name: "新建",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmock.NewMockArticleService(ctrl)
+				svc.EXPECT().Save(gomock.Any(), domain.Article{
+					Title:   "标题",
+					Content: "内容",
+					Author:  domain.Author{Id: 123},
+				}).Return(int64(1), nil)
+				return svc
+			},
+			reqBody:  `{"title":"标题","content":"内容"}`,
+			wantBody: Result{Data: map[string]any{"id": float64(1), "version": float64(1)}},
+		},
+		{
+			name: "修改",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmock.NewMockArticleService(ctrl)
+				svc.EXPECT().Save(gomock.Any(), domain.Article{
+					Id:      1,
+					Title:   "标题",
+					Content: "内容",
+					Author:  domain.Author{Id: 123},
+					Version: 3,
+				}).Return(int64(1), nil)
+				return svc
+			},
+			reqBody:  `{"id":1,"title":"标题","content":"内容","version":3}`,
+			wantBody: Result{Data: map[string]any{"id": float64(1), "version": float64(4)}},
+		},
+		{
+			name: "标签归一化",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmock.NewMockArticleService(ctrl)
+				svc.EXPECT().Save(gomock.Any(), domain.Article{
+					Title:      "标题",
+					Author:     domain.Author{Id: 123},
+					Tags:       []string{"go", "gin web"},
+					CategoryId: 101,
+				}).Return(int64(1), nil)
+				return svc
+			},
+			reqBody:  `{"title":"标题","tags":[" Go ","gin  Web","go",""],"categoryId":101}`,
+			wantBody: Result{Data: map[string]any{"id": float64(1), "version": float64(1)}},
+		},
+		{
+			name: "标签太多",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				return svcmock.NewMockArticleService(ctrl)
+			},
+			reqBody:  `{"title":"标题","tags":["a","b","c","d","e","f"]}`,
+			wantBody: Result{Code: 4, Msg: "Too many tags"},
+		},
+		{
+			name: "分类不存在",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				return svcmock.NewMockArticleService(ctrl)
+			},
+			reqBody:  `{"title":"标题","categoryId":999}`,
+			wantBody: Result{Code: 4, Msg: "Invalid category"},
+		},
+		{
+			name: "版本冲突",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmock.NewMockArticleService(ctrl)
+				svc.EXPECT().Save(gomock.Any(), domain.Article{
+					Id:      1,
+					Title:   "标题",
+					Content: "内容",
+					Author:  domain.Author{Id: 123},
+					Version: 3,
+				}).Return(int64(0), service.ErrArticleConflict)
+				svc.EXPECT().GetById(gomock.Any(), int64(1)).
+					Return(domain.Article{Id: 1, Version: 5}, nil)
+				return svc
+			},
+			reqBody: `{"id":1,"title":"标题","content":"内容","version":3}`,
+			wantBody: Result{Code: codeConflict, Msg: "Article has been modified elsewhere",
+				Data: map[string]any{"id": float64(1), "version": float64(5)}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := gin.Default()
+			server.Use(func(ctx *gin.Context) {
+				ctx.Set("users", &ijwt.UserClaims{Id: 123})
+			})
+			NewArticleHandler(tc.mock(ctrl), svcmock.NewMockInteractiveService(ctrl),
+				svcmock.NewMockFollowService(ctrl), svcmock.NewMockSeriesService(ctrl),
+				svcmock.NewMockBlockService(ctrl), logger.NewNoOpLogger()).RegisterRoutes(server)
+			req, err := http.NewRequest(http.MethodPost, "/articles/edit", strings.NewReader(tc.reqBody))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+
+			server.ServeHTTP(resp, req)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			var res Result
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantBody, res)
+		})
+	}
+}
+
+func TestArticleHandler_CancelSchedule(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) service.ArticleService
+		wantBody Result
+	}{
+		{
+			name: "取消成功",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmock.NewMockArticleService(ctrl)
+				svc.EXPECT().CancelSchedule(gomock.Any(), int64(123), int64(1)).Return(nil)
+				return svc
+			},
+			wantBody: Result{Msg: "OK"},
+		},
+		{
+			name: "不是定时文章",
+			mock: func(ctrl *gomock.Controller) service.ArticleService {
+				svc := svcmock.NewMockArticleService(ctrl)
+				svc.EXPECT().CancelSchedule(gomock.Any(), int64(123), int64(1)).
+					Return(service.ErrScheduleNotFound)
+				return svc
+			},
+			wantBody: Result{Code: 4, Msg: "Article is not scheduled"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := gin.Default()
+			server.Use(func(ctx *gin.Context) {
+				ctx.Set("users", &ijwt.UserClaims{Id: 123})
+			})
+			NewArticleHandler(tc.mock(ctrl), svcmock.NewMockInteractiveService(ctrl),
+				svcmock.NewMockFollowService(ctrl), svcmock.NewMockSeriesService(ctrl),
+				svcmock.NewMockBlockService(ctrl), logger.NewNoOpLogger()).RegisterRoutes(server)
+			req, err := http.NewRequest(http.MethodPost, "/articles/scheduled/cancel", strings.NewReader(`{"id":1}`))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+
+			server.ServeHTTP(resp, req)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			var res Result
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantBody, res)
+		})
+	}
+}
+
+func TestArticleHandler_PubByTag(t *testing.T) {
+	ctime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local)
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (service.ArticleService, service.InteractiveService)
+		path string
+		// 登录了的读者，0 是没登录
+		uid      int64
+		wantBody Result
+	}{
+		{
+			name: "查询成功",
+			mock: func(ctrl *gomock.Controller) (service.ArticleService, service.InteractiveService) {
+				svc := svcmock.NewMockArticleService(ctrl)
+				interSvc := svcmock.NewMockInteractiveService(ctrl)
+				// 标签要归一化之后再查
+				svc.EXPECT().ListPubByTag(gomock.Any(), int64(0), "go", 0, 10).
+					Return([]domain.Article{
+						{Id: 1, Title: "标题", Content: "摘要", Author: domain.Author{Id: 123},
+							Ctime: ctime, Utime: ctime},
+					}, int64(21), nil)
+				interSvc.EXPECT().GetByIds(gomock.Any(), "articles", []int64{1}).
+					Return(map[int64]domain.Interactive{1: {ReadCnt: 3}}, nil)
+				return svc, interSvc
+			},
+			path: "/articles/pub/tags/Go?offset=0&limit=10",
+			wantBody: Result{Data: map[string]any{
+				"tag":   "go",
+				"total": float64(21),
+				"items": []any{
+					map[string]any{"id": float64(1), "title": "标题", "abstract": "摘要",
+						"authorId": float64(123), "ctime": "2024-01-02 03:04:05", "utime": "2024-01-02 03:04:05",
+						"readCnt": float64(3), "likeCnt": float64(0), "collectCnt": float64(0),
+						"liked": false, "collected": false, "followed": false},
+				},
+			}},
+		},
+		{
+			// 登录了要带上读者，过滤掉拉黑的作者
+			name: "登录的读者",
+			mock: func(ctrl *gomock.Controller) (service.ArticleService, service.InteractiveService) {
+				svc := svcmock.NewMockArticleService(ctrl)
+				interSvc := svcmock.NewMockInteractiveService(ctrl)
+				svc.EXPECT().ListPubByTag(gomock.Any(), int64(123), "go", 0, 10).
+					Return([]domain.Article{}, int64(0), nil)
+				interSvc.EXPECT().GetByIds(gomock.Any(), "articles", []int64{}).
+					Return(map[int64]domain.Interactive{}, nil)
+				return svc, interSvc
+			},
+			path: "/articles/pub/tags/go?offset=0&limit=10",
+			uid:  123,
+			wantBody: Result{Data: map[string]any{
+				"tag":   "go",
+				"total": float64(0),
+				"items": []any{},
+			}},
+		},
+		{
+			name: "limit 太大",
+			mock: func(ctrl *gomock.Controller) (service.ArticleService, service.InteractiveService) {
+				return svcmock.NewMockArticleService(ctrl), svcmock.NewMockInteractiveService(ctrl)
+			},
+			path:     "/articles/pub/tags/go?offset=0&limit=100",
+			wantBody: Result{Code: 4, Msg: "Invalid offset or limit"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := gin.Default()
+			if tc.uid > 0 {
+				server.Use(func(ctx *gin.Context) {
+					ctx.Set("users", &ijwt.UserClaims{Id: tc.uid})
+				})
+			}
+			svc, interSvc := tc.mock(ctrl)
+			NewArticleHandler(svc, interSvc, svcmock.NewMockFollowService(ctrl),
+				svcmock.NewMockSeriesService(ctrl), svcmock.NewMockBlockService(ctrl),
+				logger.NewNoOpLogger()).RegisterRoutes(server)
+			req, err := http.NewRequest(http.MethodGet, tc.path, nil)
+			require.NoError(t, err)
+			resp := httptest.NewRecorder()
+
+			server.ServeHTTP(resp, req)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			var res Result
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantBody, res)
+		})
+	}
+}
+
+func TestArticleHandler_PubDetail(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) (service.ArticleService, service.BlockService)
+		wantBody Result
+	}{
+		{
+			// 拉黑了作者就不给看了，也不算阅读数
+			name: "拉黑了作者",
+			mock: func(ctrl *gomock.Controller) (service.ArticleService, service.BlockService) {
+				svc := svcmock.NewMockArticleService(ctrl)
+				blockSvc := svcmock.NewMockBlockService(ctrl)
+				svc.EXPECT().GetPubById(gomock.Any(), int64(1), int64(123)).
+					Return(domain.Article{Id: 1, Title: "标题", Author: domain.Author{Id: 456}}, nil)
+				blockSvc.EXPECT().Blocked(gomock.Any(), int64(123), int64(456)).Return(true, nil)
+				return svc, blockSvc
+			},
+			wantBody: Result{Code: 4, Msg: "You have blocked the author"},
+		},
+		{
+			name: "查询拉黑关系失败",
+			mock: func(ctrl *gomock.Controller) (service.ArticleService, service.BlockService) {
+				svc := svcmock.NewMockArticleService(ctrl)
+				blockSvc := svcmock.NewMockBlockService(ctrl)
+				svc.EXPECT().GetPubById(gomock.Any(), int64(1), int64(123)).
+					Return(domain.Article{Id: 1, Title: "标题", Author: domain.Author{Id: 456}}, nil)
+				blockSvc.EXPECT().Blocked(gomock.Any(), int64(123), int64(456)).
+					Return(false, errors.New("mock db error"))
+				return svc, blockSvc
+			},
+			wantBody: Result{Code: 5, Msg: "系统错误"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := gin.Default()
+			server.Use(func(ctx *gin.Context) {
+				ctx.Set("users", &ijwt.UserClaims{Id: 123})
+			})
+			svc, blockSvc := tc.mock(ctrl)
+			NewArticleHandler(svc, svcmock.NewMockInteractiveService(ctrl),
+				svcmock.NewMockFollowService(ctrl), svcmock.NewMockSeriesService(ctrl),
+				blockSvc, logger.NewNoOpLogger()).RegisterRoutes(server)
+			req, err := http.NewRequest(http.MethodGet, "/articles/pub/1", nil)
+			require.NoError(t, err)
+			resp := httptest.NewRecorder()
+
+			server.ServeHTTP(resp, req)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			var res Result
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantBody, res)
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package web
 
-import "webook/internal/domain"
+import (
+	"fmt"
+	"time"
+	"webook/internal/domain"
+)
 
 // 直接对标前端
 type Article struct {
@@ -18,18 +22,42 @@ type ArticleVO struct {
 	Status     uint8  `json:"status,omitempty"`
 	Ctime      string `json:"ctime,omitempty"`
 	Utime      string `json:"utime,omitempty"`
+	// 编辑的时候要带回来
+	Version int64 `json:"version,omitempty"`
+	// 定时发表的时间
+	PublishAt    string   `json:"publishAt,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	CategoryId   int64    `json:"categoryId,omitempty"`
+	CategoryName string   `json:"categoryName,omitempty"`
+	// 文章在系列里面的话，带上上一篇下一篇
+	Series *SeriesNavVO `json:"series,omitempty"`
 
 	ReadCnt    int64 `json:"readCnt"`
 	LikeCnt    int64 `json:"likeCnt"`
 	CollectCnt int64 `json:"collectCnt"`
 	Liked      bool  `json:"liked"`
 	Collected  bool  `json:"collected"`
+	// 读者有没有关注作者
+	Followed bool `json:"followed"`
+}
+
+// ArticleVersionVO 保存之后或者冲突的时候告诉前端最新的版本号
+type ArticleVersionVO struct {
+	Id      int64 `json:"id"`
+	Version int64 `json:"version"`
 }
 
 type ArticleReq struct {
 	Id      int64  `json:"id"`
 	Title   string `json:"title"`
 	Content string `json:"content"`
+	// 编辑的时候拿到的版本号，新建的时候不用传
+	Version int64 `json:"version"`
+	// 定时发表的时间，毫秒数，只有发表的时候用，不传就是马上发表
+	PublishAt int64 `json:"publishAt"`
+	// 每次都要传全部的标签，后端会归一化
+	Tags       []string `json:"tags"`
+	CategoryId int64    `json:"categoryId"`
 }
 
 type ListReq struct {
@@ -38,12 +66,157 @@ type ListReq struct {
 }
 
 func (req ArticleReq) toDomain(uid int64) domain.Article {
-	return domain.Article{
+	art := domain.Article{
 		Id:      req.Id,
 		Title:   req.Title,
 		Content: req.Content,
 		Author: domain.Author{
 			Id: uid,
 		},
+		Version:    req.Version,
+		Tags:       domain.NormalizeTags(req.Tags),
+		CategoryId: req.CategoryId,
+	}
+	if req.PublishAt > 0 {
+		art.PublishAt = time.UnixMilli(req.PublishAt)
+	}
+	return art
+}
+
+// ArticlePageVO 按游标翻页的文章列表
+type ArticlePageVO struct {
+	Items []ArticleVO `json:"items"`
+	// 空字符串表示没有下一页了
+	NextCursor string `json:"nextCursor"`
+}
+
+// parseArticleCursor 游标对前端来说是不透明的，原样带回来就行，空字符串是第一页
+func parseArticleCursor(s string) (domain.ArticleCursor, error) {
+	var cursor domain.ArticleCursor
+	if s == "" {
+		return cursor, nil
+	}
+	_, err := fmt.Sscanf(s, "%d_%d", &cursor.Time, &cursor.Aid)
+	return cursor, err
+}
+
+func formatArticleCursor(t time.Time, aid int64) string {
+	return fmt.Sprintf("%d_%d", t.UnixMilli(), aid)
+}
+
+// RevisionVO 历史版本列表里面的一项，不带内容
+type RevisionVO struct {
+	Version int64  `json:"version"`
+	Title   string `json:"title"`
+	Status  uint8  `json:"status"`
+	Ctime   string `json:"ctime"`
+}
+
+type RevisionDiffVO struct {
+	From RevisionVO `json:"from"`
+	To   RevisionVO `json:"to"`
+	// 标题和内容分开比较，标题只有一行，前端自己对比就行
+	Lines []DiffLineVO `json:"lines"`
+}
+
+type DiffLineVO struct {
+	// equal, insert, delete
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+func newRevisionVO(rev domain.ArticleRevision) RevisionVO {
+	return RevisionVO{
+		Version: rev.Version,
+		Title:   rev.Title,
+		Status:  rev.Status.ToUint8(),
+		Ctime:   rev.Ctime.Format(time.DateTime),
+	}
+}
+
+// formatPublishAt 没有定时就是空字符串
+func formatPublishAt(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.DateTime)
+}
+
+// TagPageVO 标签页，Total 是这个标签下面一共有多少篇文章
+type TagPageVO struct {
+	Tag   string      `json:"tag"`
+	Total int64       `json:"total"`
+	Items []ArticleVO `json:"items"`
+}
+
+type CategoryVO struct {
+	Id       int64        `json:"id"`
+	Name     string       `json:"name"`
+	Children []CategoryVO `json:"children,omitempty"`
+}
+
+// categoryName 没有分类就是空字符串
+func categoryName(id int64) string {
+	c, _ := domain.CategoryById(id)
+	return c.Name
+}
+
+type SeriesVO struct {
+	Id          int64  `json:"id"`
+	AuthorId    int64  `json:"authorId"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	// 列表里面没有
+	Articles []SeriesArticleVO `json:"articles,omitempty"`
+	Ctime    string            `json:"ctime"`
+	Utime    string            `json:"utime"`
+}
+
+type SeriesArticleVO struct {
+	Id    int64  `json:"id"`
+	Title string `json:"title"`
+}
+
+// SeriesNavVO 文章详情里面的系列导航，第一篇没有 prev，最后一篇没有 next
+type SeriesNavVO struct {
+	Id    int64            `json:"id"`
+	Title string           `json:"title"`
+	Index int              `json:"index"`
+	Total int              `json:"total"`
+	Prev  *SeriesArticleVO `json:"prev,omitempty"`
+	Next  *SeriesArticleVO `json:"next,omitempty"`
+}
+
+func newSeriesVO(s domain.Series) SeriesVO {
+	vo := SeriesVO{
+		Id:          s.Id,
+		AuthorId:    s.AuthorId,
+		Title:       s.Title,
+		Description: s.Description,
+		Ctime:       s.Ctime.Format(time.DateTime),
+		Utime:       s.Utime.Format(time.DateTime),
+	}
+	for _, art := range s.Articles {
+		vo.Articles = append(vo.Articles, SeriesArticleVO{
+			Id:    art.Id,
+			Title: art.Title,
+		})
+	}
+	return vo
+}
+
+func newSeriesNavVO(nav domain.SeriesNav) *SeriesNavVO {
+	vo := &SeriesNavVO{
+		Id:    nav.Sid,
+		Title: nav.Title,
+		Index: nav.Index,
+		Total: nav.Total,
+	}
+	if nav.Prev.Id > 0 {
+		vo.Prev = &SeriesArticleVO{Id: nav.Prev.Id, Title: nav.Prev.Title}
+	}
+	if nav.Next.Id > 0 {
+		vo.Next = &SeriesArticleVO{Id: nav.Next.Id, Title: nav.Next.Title}
 	}
+	return vo
 }
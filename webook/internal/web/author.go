@@ -0,0 +1,129 @@
+package web
+
+import (
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/service"
+	"webook/pkg/logger"
+)
+
+// 作者主页文章列表一页最多多少条
+const authorArticlesMaxLimit = 50
+
+// AuthorHandler 作者主页，不需要登录
+type AuthorHandler struct {
+	svc service.AuthorService
+	log logger.LoggerV1
+}
+
+func NewAuthorHandler(svc service.AuthorService, log logger.LoggerV1) *AuthorHandler {
+	return &AuthorHandler{
+		svc: svc,
+		log: log,
+	}
+}
+
+func (h *AuthorHandler) RegisterRoutes(server *gin.Engine) {
+	ag := server.Group("/authors")
+	ag.GET("/:id", h.Profile)
+	// /:id/articles?offset=?&limit=?
+	ag.GET("/:id/articles", h.Articles)
+}
+
+func (h *AuthorHandler) Profile(ctx *gin.Context) {
+	uid, ok := h.authorId(ctx)
+	if !ok {
+		return
+	}
+	p, err := h.svc.Profile(ctx, uid)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Data: AuthorVO{
+				Id:        p.Id,
+				Nickname:  p.Nickname,
+				AboutMe:   p.AboutMe,
+				Followers: p.Followers,
+				Followees: p.Followees,
+				Articles:  p.Articles,
+			},
+		})
+	case service.ErrUserNotFound:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Author not found",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		h.log.Error("查询作者主页失败",
+			logger.Int64("uid", uid),
+			logger.Error(err))
+	}
+}
+
+func (h *AuthorHandler) Articles(ctx *gin.Context) {
+	uid, ok := h.authorId(ctx)
+	if !ok {
+		return
+	}
+	type Req struct {
+		Offset int `form:"offset"`
+		Limit  int `form:"limit"`
+	}
+	var req Req
+	if err := ctx.BindQuery(&req); err != nil {
+		return
+	}
+	if req.Offset < 0 || req.Limit <= 0 || req.Limit > authorArticlesMaxLimit {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Invalid offset or limit",
+		})
+		return
+	}
+	arts, err := h.svc.PubArticles(ctx, uid, req.Offset, req.Limit)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		h.log.Error("查询作者文章列表失败",
+			logger.Int64("uid", uid),
+			logger.Int("offset", req.Offset),
+			logger.Int("limit", req.Limit),
+			logger.Error(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: slice.Map(arts, func(idx int, src domain.Article) ArticleVO {
+			return ArticleVO{
+				Id:       src.Id,
+				Title:    src.Title,
+				Abstract: src.Abstract(),
+				AuthorId: src.Author.Id,
+				Ctime:    src.Ctime.Format(time.DateTime),
+				Utime:    src.Utime.Format(time.DateTime),
+			}
+		}),
+	})
+}
+
+func (h *AuthorHandler) authorId(ctx *gin.Context) (int64, bool) {
+	idstr := ctx.Param("id")
+	uid, err := strconv.ParseInt(idstr, 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Invalid author id",
+		})
+		return 0, false
+	}
+	return uid, true
+}
@@ -0,0 +1,154 @@
+package web
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/service"
+	svcmock "webook/internal/service/mocks"
+	"webook/pkg/logger"
+)
+
+func TestAuthorHandler_Profile(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) service.AuthorService
+		path     string
+		wantBody Result
+	}{
+		{
+			name: "profile",
+			mock: func(ctrl *gomock.Controller) service.AuthorService {
+				svc := svcmock.NewMockAuthorService(ctrl)
+				svc.EXPECT().Profile(gomock.Any(), int64(123)).Return(domain.AuthorProfile{
+					Id:        123,
+					Nickname:  "Tom",
+					AboutMe:   "hello",
+					Followers: 10,
+					Followees: 2,
+					Articles:  7,
+				}, nil)
+				return svc
+			},
+			path: "/authors/123",
+			wantBody: Result{Data: map[string]any{
+				"id":        float64(123),
+				"nickname":  "Tom",
+				"aboutMe":   "hello",
+				"followers": float64(10),
+				"followees": float64(2),
+				"articles":  float64(7),
+			}},
+		},
+		{
+			name: "author not found",
+			mock: func(ctrl *gomock.Controller) service.AuthorService {
+				svc := svcmock.NewMockAuthorService(ctrl)
+				svc.EXPECT().Profile(gomock.Any(), int64(123)).
+					Return(domain.AuthorProfile{}, service.ErrUserNotFound)
+				return svc
+			},
+			path:     "/authors/123",
+			wantBody: Result{Code: 4, Msg: "Author not found"},
+		},
+		{
+			name: "bad id",
+			mock: func(ctrl *gomock.Controller) service.AuthorService {
+				return svcmock.NewMockAuthorService(ctrl)
+			},
+			path:     "/authors/abc",
+			wantBody: Result{Code: 4, Msg: "Invalid author id"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := gin.Default()
+			NewAuthorHandler(tc.mock(ctrl), logger.NewNoOpLogger()).RegisterRoutes(server)
+			req, err := http.NewRequest(http.MethodGet, tc.path, nil)
+			require.NoError(t, err)
+			resp := httptest.NewRecorder()
+
+			server.ServeHTTP(resp, req)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			var res Result
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantBody, res)
+		})
+	}
+}
+
+func TestAuthorHandler_Articles(t *testing.T) {
+	ctime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local)
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) service.AuthorService
+		query    string
+		wantBody Result
+	}{
+		{
+			name: "list",
+			mock: func(ctrl *gomock.Controller) service.AuthorService {
+				svc := svcmock.NewMockAuthorService(ctrl)
+				svc.EXPECT().PubArticles(gomock.Any(), int64(123), 0, 10).
+					Return([]domain.Article{
+						{Id: 1, Title: "标题", Content: "内容", Author: domain.Author{Id: 123},
+							Ctime: ctime, Utime: ctime},
+					}, nil)
+				return svc
+			},
+			query: "offset=0&limit=10",
+			wantBody: Result{Data: []any{
+				map[string]any{
+					"id":         float64(1),
+					"title":      "标题",
+					"abstract":   "内容",
+					"authorId":   float64(123),
+					"readCnt":    float64(0),
+					"likeCnt":    float64(0),
+					"collectCnt": float64(0),
+					"liked":      false,
+					"collected":  false,
+					"followed":   false,
+					"ctime":      "2024-01-02 03:04:05",
+					"utime":      "2024-01-02 03:04:05",
+				},
+			}},
+		},
+		{
+			name: "limit too large",
+			mock: func(ctrl *gomock.Controller) service.AuthorService {
+				return svcmock.NewMockAuthorService(ctrl)
+			},
+			query:    "offset=0&limit=100",
+			wantBody: Result{Code: 4, Msg: "Invalid offset or limit"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := gin.Default()
+			NewAuthorHandler(tc.mock(ctrl), logger.NewNoOpLogger()).RegisterRoutes(server)
+			req, err := http.NewRequest(http.MethodGet, "/authors/123/articles?"+tc.query, nil)
+			require.NoError(t, err)
+			resp := httptest.NewRecorder()
+
+			server.ServeHTTP(resp, req)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			var res Result
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantBody, res)
+		})
+	}
+}
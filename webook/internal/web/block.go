@@ -0,0 +1,137 @@
+package web
+
+import (
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/service"
+	ijwt "webook/pkg/ginx/jwt"
+	"webook/pkg/logger"
+)
+
+// 拉黑名单一页最多多少条
+const blockListMaxLimit = 100
+
+type BlockHandler struct {
+	svc service.BlockService
+	log logger.LoggerV1
+}
+
+func NewBlockHandler(svc service.BlockService, log logger.LoggerV1) *BlockHandler {
+	return &BlockHandler{
+		svc: svc,
+		log: log,
+	}
+}
+
+func (h *BlockHandler) RegisterRoutes(server *gin.Engine) {
+	bg := server.Group("/block")
+	bg.POST("/block", h.Block)
+	bg.POST("/cancel", h.Unblock)
+	// 只能看自己的拉黑名单
+	// /list?offset=?&limit=?
+	bg.GET("/list", h.List)
+}
+
+func (h *BlockHandler) Block(ctx *gin.Context) {
+	type Req struct {
+		Uid int64 `json:"uid"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	uc := ctx.MustGet("users").(*ijwt.UserClaims)
+	err := h.svc.Block(ctx, uc.Id, req.Uid)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "OK",
+		})
+	case service.ErrBlockSelf:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Cannot block yourself",
+		})
+	case service.ErrUserNotFound:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "User not found",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		h.log.Error("拉黑失败",
+			logger.Int64("blocker", uc.Id),
+			logger.Int64("blocked", req.Uid),
+			logger.Error(err))
+	}
+}
+
+func (h *BlockHandler) Unblock(ctx *gin.Context) {
+	type Req struct {
+		Uid int64 `json:"uid"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	uc := ctx.MustGet("users").(*ijwt.UserClaims)
+	err := h.svc.Unblock(ctx, uc.Id, req.Uid)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		h.log.Error("解除拉黑失败",
+			logger.Int64("blocker", uc.Id),
+			logger.Int64("blocked", req.Uid),
+			logger.Error(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "OK",
+	})
+}
+
+func (h *BlockHandler) List(ctx *gin.Context) {
+	type Req struct {
+		Offset int `form:"offset"`
+		Limit  int `form:"limit"`
+	}
+	var req Req
+	if err := ctx.BindQuery(&req); err != nil {
+		return
+	}
+	if req.Offset < 0 || req.Limit <= 0 || req.Limit > blockListMaxLimit {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Invalid offset or limit",
+		})
+		return
+	}
+	uc := ctx.MustGet("users").(*ijwt.UserClaims)
+	rs, err := h.svc.GetBlockedList(ctx, uc.Id, req.Offset, req.Limit)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		h.log.Error("查询拉黑名单失败",
+			logger.Int64("uid", uc.Id),
+			logger.Error(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: slice.Map(rs, func(idx int, src domain.BlockRelation) BlockVO {
+			return BlockVO{
+				Uid:   src.Blocked,
+				Ctime: src.Ctime.Format(time.DateTime),
+			}
+		}),
+	})
+}
@@ -0,0 +1,136 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/service"
+	svcmock "webook/internal/service/mocks"
+	ijwt "webook/pkg/ginx/jwt"
+	"webook/pkg/logger"
+)
+
+func TestBlockHandler_Block(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) service.BlockService
+		reqBody  string
+		wantBody Result
+	}{
+		{
+			name: "block",
+			mock: func(ctrl *gomock.Controller) service.BlockService {
+				svc := svcmock.NewMockBlockService(ctrl)
+				svc.EXPECT().Block(gomock.Any(), int64(123), int64(456)).Return(nil)
+				return svc
+			},
+			reqBody:  `{"uid":456}`,
+			wantBody: Result{Msg: "OK"},
+		},
+		{
+			name: "block self",
+			mock: func(ctrl *gomock.Controller) service.BlockService {
+				svc := svcmock.NewMockBlockService(ctrl)
+				svc.EXPECT().Block(gomock.Any(), int64(123), int64(123)).Return(service.ErrBlockSelf)
+				return svc
+			},
+			reqBody:  `{"uid":123}`,
+			wantBody: Result{Code: 4, Msg: "Cannot block yourself"},
+		},
+		{
+			name: "user not found",
+			mock: func(ctrl *gomock.Controller) service.BlockService {
+				svc := svcmock.NewMockBlockService(ctrl)
+				svc.EXPECT().Block(gomock.Any(), int64(123), int64(789)).Return(service.ErrUserNotFound)
+				return svc
+			},
+			reqBody:  `{"uid":789}`,
+			wantBody: Result{Code: 4, Msg: "User not found"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := gin.Default()
+			server.Use(func(ctx *gin.Context) {
+				ctx.Set("users", &ijwt.UserClaims{Id: 123})
+			})
+			NewBlockHandler(tc.mock(ctrl), logger.NewNoOpLogger()).RegisterRoutes(server)
+			req, err := http.NewRequest(http.MethodPost, "/block/block",
+				bytes.NewBuffer([]byte(tc.reqBody)))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+
+			server.ServeHTTP(resp, req)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			var res Result
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantBody, res)
+		})
+	}
+}
+
+func TestBlockHandler_List(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) service.BlockService
+		query    string
+		wantBody Result
+	}{
+		{
+			name: "list",
+			mock: func(ctrl *gomock.Controller) service.BlockService {
+				svc := svcmock.NewMockBlockService(ctrl)
+				svc.EXPECT().GetBlockedList(gomock.Any(), int64(123), 0, 10).
+					Return([]domain.BlockRelation{
+						{Blocker: 123, Blocked: 456, Ctime: time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local)},
+					}, nil)
+				return svc
+			},
+			query: "offset=0&limit=10",
+			wantBody: Result{Data: []any{
+				map[string]any{"uid": float64(456), "ctime": "2024-01-02 03:04:05"},
+			}},
+		},
+		{
+			name: "limit too large",
+			mock: func(ctrl *gomock.Controller) service.BlockService {
+				return svcmock.NewMockBlockService(ctrl)
+			},
+			query:    "offset=0&limit=1000",
+			wantBody: Result{Code: 4, Msg: "Invalid offset or limit"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := gin.Default()
+			server.Use(func(ctx *gin.Context) {
+				ctx.Set("users", &ijwt.UserClaims{Id: 123})
+			})
+			NewBlockHandler(tc.mock(ctrl), logger.NewNoOpLogger()).RegisterRoutes(server)
+			req, err := http.NewRequest(http.MethodGet, "/block/list?"+tc.query, nil)
+			require.NoError(t, err)
+			resp := httptest.NewRecorder()
+
+			server.ServeHTTP(resp, req)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			var res Result
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantBody, res)
+		})
+	}
+}
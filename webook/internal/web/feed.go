@@ -0,0 +1,92 @@
+package web
+
+import (
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/service"
+	ijwt "webook/pkg/ginx/jwt"
+	"webook/pkg/logger"
+)
+
+// 信息流一页最多多少条
+const feedMaxLimit = 50
+
+type FeedHandler struct {
+	svc service.FeedService
+	log logger.LoggerV1
+}
+
+func NewFeedHandler(svc service.FeedService, log logger.LoggerV1) *FeedHandler {
+	return &FeedHandler{
+		svc: svc,
+		log: log,
+	}
+}
+
+func (h *FeedHandler) RegisterRoutes(server *gin.Engine) {
+	// /feed?cursor=?&limit=?，第一页不带 cursor
+	server.GET("/feed", h.Feed)
+}
+
+func (h *FeedHandler) Feed(ctx *gin.Context) {
+	type Req struct {
+		Cursor string `form:"cursor"`
+		Limit  int    `form:"limit"`
+	}
+	var req Req
+	if err := ctx.BindQuery(&req); err != nil {
+		return
+	}
+	if req.Limit <= 0 || req.Limit > feedMaxLimit {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Invalid limit",
+		})
+		return
+	}
+	cursor, err := parseArticleCursor(req.Cursor)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Invalid cursor",
+		})
+		return
+	}
+	uc := ctx.MustGet("users").(*ijwt.UserClaims)
+	items, err := h.svc.Feed(ctx, uc.Id, cursor, req.Limit)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		h.log.Error("查询信息流失败",
+			logger.Int64("uid", uc.Id),
+			logger.String("cursor", req.Cursor),
+			logger.Error(err))
+		return
+	}
+	res := ArticlePageVO{
+		Items: slice.Map(items, func(idx int, src domain.FeedItem) ArticleVO {
+			return ArticleVO{
+				Id:       src.Article.Id,
+				Title:    src.Article.Title,
+				Abstract: src.Article.Abstract(),
+				AuthorId: src.Article.Author.Id,
+				Status:   src.Article.Status.ToUint8(),
+				Ctime:    src.Article.Ctime.Format(time.DateTime),
+				Utime:    src.Time.Format(time.DateTime),
+			}
+		}),
+	}
+	// 不满一页说明没有更多了
+	if len(items) == req.Limit {
+		last := items[len(items)-1]
+		res.NextCursor = formatArticleCursor(last.Time, last.Article.Id)
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: res,
+	})
+}
@@ -0,0 +1,113 @@
+package web
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/service"
+	svcmock "webook/internal/service/mocks"
+	ijwt "webook/pkg/ginx/jwt"
+	"webook/pkg/logger"
+)
+
+func TestFeedHandler_Feed(t *testing.T) {
+	published := time.UnixMilli(1700000000123)
+	testCases := []struct {
+		name           string
+		mock           func(ctrl *gomock.Controller) service.FeedService
+		query          string
+		wantCode       int
+		wantMsg        string
+		wantIds        []int64
+		wantNextCursor string
+	}{
+		{
+			name: "first page, has more",
+			mock: func(ctrl *gomock.Controller) service.FeedService {
+				svc := svcmock.NewMockFeedService(ctrl)
+				svc.EXPECT().Feed(gomock.Any(), int64(123), domain.ArticleCursor{}, 2).
+					Return([]domain.FeedItem{
+						{Article: domain.Article{Id: 8}, Time: published},
+						{Article: domain.Article{Id: 7}, Time: published},
+					}, nil)
+				return svc
+			},
+			query:          "limit=2",
+			wantIds:        []int64{8, 7},
+			wantNextCursor: "1700000000123_7",
+		},
+		{
+			name: "last page",
+			mock: func(ctrl *gomock.Controller) service.FeedService {
+				svc := svcmock.NewMockFeedService(ctrl)
+				svc.EXPECT().Feed(gomock.Any(), int64(123),
+					domain.ArticleCursor{Time: 1700000000123, Aid: 7}, 2).
+					Return([]domain.FeedItem{
+						{Article: domain.Article{Id: 3}, Time: published},
+					}, nil)
+				return svc
+			},
+			query:   "limit=2&cursor=1700000000123_7",
+			wantIds: []int64{3},
+		},
+		{
+			name: "bad cursor",
+			mock: func(ctrl *gomock.Controller) service.FeedService {
+				return svcmock.NewMockFeedService(ctrl)
+			},
+			query:    "limit=2&cursor=abc",
+			wantCode: 4,
+			wantMsg:  "Invalid cursor",
+		},
+		{
+			name: "limit too large",
+			mock: func(ctrl *gomock.Controller) service.FeedService {
+				return svcmock.NewMockFeedService(ctrl)
+			},
+			query:    "limit=500",
+			wantCode: 4,
+			wantMsg:  "Invalid limit",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := gin.Default()
+			server.Use(func(ctx *gin.Context) {
+				ctx.Set("users", &ijwt.UserClaims{Id: 123})
+			})
+			NewFeedHandler(tc.mock(ctrl), logger.NewNoOpLogger()).RegisterRoutes(server)
+			req, err := http.NewRequest(http.MethodGet, "/feed?"+tc.query, nil)
+			require.NoError(t, err)
+			resp := httptest.NewRecorder()
+
+			server.ServeHTTP(resp, req)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			var res struct {
+				Code int           `json:"code"`
+				Msg  string        `json:"msg"`
+				Data ArticlePageVO `json:"data"`
+			}
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantCode, res.Code)
+			assert.Equal(t, tc.wantMsg, res.Msg)
+			ids := make([]int64, 0, len(res.Data.Items))
+			for _, item := range res.Data.Items {
+				ids = append(ids, item.Id)
+			}
+			if tc.wantIds != nil {
+				assert.Equal(t, tc.wantIds, ids)
+			}
+			assert.Equal(t, tc.wantNextCursor, res.Data.NextCursor)
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package web
+
+import (
+	"context"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/errgroup"
+	"net/http"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/service"
+	ijwt "webook/pkg/ginx/jwt"
+	"webook/pkg/logger"
+)
+
+// 关注列表一页最多多少条
+const followListMaxLimit = 100
+
+type FollowHandler struct {
+	svc service.FollowService
+	log logger.LoggerV1
+}
+
+func NewFollowHandler(svc service.FollowService, log logger.LoggerV1) *FollowHandler {
+	return &FollowHandler{
+		svc: svc,
+		log: log,
+	}
+}
+
+func (h *FollowHandler) RegisterRoutes(server *gin.Engine) {
+	fg := server.Group("/follow")
+	fg.POST("/follow", h.Follow)
+	fg.POST("/cancel", h.CancelFollow)
+	// /followers?uid=?&offset=?&limit=?
+	fg.GET("/followers", h.Followers)
+	fg.GET("/followees", h.Followees)
+	fg.GET("/statics", h.Statics)
+}
+
+func (h *FollowHandler) Follow(ctx *gin.Context) {
+	type Req struct {
+		Followee int64 `json:"followee"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	uc := ctx.MustGet("users").(*ijwt.UserClaims)
+	err := h.svc.Follow(ctx, uc.Id, req.Followee)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "OK",
+		})
+	case service.ErrFollowSelf:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Cannot follow yourself",
+		})
+	case service.ErrUserNotFound:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "User not found",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		h.log.Error("关注失败",
+			logger.Int64("follower", uc.Id),
+			logger.Int64("followee", req.Followee),
+			logger.Error(err))
+	}
+}
+
+func (h *FollowHandler) CancelFollow(ctx *gin.Context) {
+	type Req struct {
+		Followee int64 `json:"followee"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	uc := ctx.MustGet("users").(*ijwt.UserClaims)
+	err := h.svc.CancelFollow(ctx, uc.Id, req.Followee)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		h.log.Error("取消关注失败",
+			logger.Int64("follower", uc.Id),
+			logger.Int64("followee", req.Followee),
+			logger.Error(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "OK",
+	})
+}
+
+// Followers 谁关注了 uid
+func (h *FollowHandler) Followers(ctx *gin.Context) {
+	h.list(ctx, h.svc.GetFollowers, func(r domain.FollowRelation) int64 {
+		return r.Follower
+	})
+}
+
+// Followees uid 关注了谁
+func (h *FollowHandler) Followees(ctx *gin.Context) {
+	h.list(ctx, h.svc.GetFollowees, func(r domain.FollowRelation) int64 {
+		return r.Followee
+	})
+}
+
+func (h *FollowHandler) list(ctx *gin.Context,
+	find func(ctx context.Context, uid int64, offset, limit int) ([]domain.FollowRelation, error),
+	other func(r domain.FollowRelation) int64) {
+	type Req struct {
+		Uid    int64 `form:"uid"`
+		Offset int   `form:"offset"`
+		Limit  int   `form:"limit"`
+	}
+	var req Req
+	if err := ctx.BindQuery(&req); err != nil {
+		return
+	}
+	if req.Offset < 0 || req.Limit <= 0 || req.Limit > followListMaxLimit {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Invalid offset or limit",
+		})
+		return
+	}
+	rs, err := find(ctx, req.Uid, req.Offset, req.Limit)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		h.log.Error("查询关注列表失败",
+			logger.Int64("uid", req.Uid),
+			logger.Error(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: slice.Map(rs, func(idx int, src domain.FollowRelation) FollowVO {
+			return FollowVO{
+				Uid:   other(src),
+				Ctime: src.Ctime.Format(time.DateTime),
+			}
+		}),
+	})
+}
+
+func (h *FollowHandler) Statics(ctx *gin.Context) {
+	type Req struct {
+		Uid int64 `form:"uid"`
+	}
+	var req Req
+	if err := ctx.BindQuery(&req); err != nil {
+		return
+	}
+	uc := ctx.MustGet("users").(*ijwt.UserClaims)
+	var (
+		eg       errgroup.Group
+		statics  domain.FollowStatics
+		followed bool
+	)
+	eg.Go(func() error {
+		var er error
+		statics, er = h.svc.GetStatics(ctx, req.Uid)
+		return er
+	})
+	eg.Go(func() error {
+		var er error
+		followed, er = h.svc.Followed(ctx, uc.Id, req.Uid)
+		return er
+	})
+	err := eg.Wait()
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		h.log.Error("查询关注数失败",
+			logger.Int64("uid", req.Uid),
+			logger.Error(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: FollowStaticsVO{
+			Followers: statics.Followers,
+			Followees: statics.Followees,
+			Followed:  followed,
+		},
+	})
+}
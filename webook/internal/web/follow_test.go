@@ -0,0 +1,136 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/service"
+	svcmock "webook/internal/service/mocks"
+	ijwt "webook/pkg/ginx/jwt"
+	"webook/pkg/logger"
+)
+
+func TestFollowHandler_Follow(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) service.FollowService
+		reqBody  string
+		wantBody Result
+	}{
+		{
+			name: "follow",
+			mock: func(ctrl *gomock.Controller) service.FollowService {
+				svc := svcmock.NewMockFollowService(ctrl)
+				svc.EXPECT().Follow(gomock.Any(), int64(123), int64(456)).Return(nil)
+				return svc
+			},
+			reqBody:  `{"followee":456}`,
+			wantBody: Result{Msg: "OK"},
+		},
+		{
+			name: "follow self",
+			mock: func(ctrl *gomock.Controller) service.FollowService {
+				svc := svcmock.NewMockFollowService(ctrl)
+				svc.EXPECT().Follow(gomock.Any(), int64(123), int64(123)).Return(service.ErrFollowSelf)
+				return svc
+			},
+			reqBody:  `{"followee":123}`,
+			wantBody: Result{Code: 4, Msg: "Cannot follow yourself"},
+		},
+		{
+			name: "followee not found",
+			mock: func(ctrl *gomock.Controller) service.FollowService {
+				svc := svcmock.NewMockFollowService(ctrl)
+				svc.EXPECT().Follow(gomock.Any(), int64(123), int64(789)).Return(service.ErrUserNotFound)
+				return svc
+			},
+			reqBody:  `{"followee":789}`,
+			wantBody: Result{Code: 4, Msg: "User not found"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := gin.Default()
+			server.Use(func(ctx *gin.Context) {
+				ctx.Set("users", &ijwt.UserClaims{Id: 123})
+			})
+			NewFollowHandler(tc.mock(ctrl), logger.NewNoOpLogger()).RegisterRoutes(server)
+			req, err := http.NewRequest(http.MethodPost, "/follow/follow",
+				bytes.NewBuffer([]byte(tc.reqBody)))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+
+			server.ServeHTTP(resp, req)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			var res Result
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantBody, res)
+		})
+	}
+}
+
+func TestFollowHandler_Followers(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) service.FollowService
+		query    string
+		wantBody Result
+	}{
+		{
+			name: "list",
+			mock: func(ctrl *gomock.Controller) service.FollowService {
+				svc := svcmock.NewMockFollowService(ctrl)
+				svc.EXPECT().GetFollowers(gomock.Any(), int64(456), 0, 10).
+					Return([]domain.FollowRelation{
+						{Follower: 123, Followee: 456, Ctime: time.Date(2024, 1, 2, 3, 4, 5, 0, time.Local)},
+					}, nil)
+				return svc
+			},
+			query: "uid=456&offset=0&limit=10",
+			wantBody: Result{Data: []any{
+				map[string]any{"uid": float64(123), "ctime": "2024-01-02 03:04:05"},
+			}},
+		},
+		{
+			name: "limit too large",
+			mock: func(ctrl *gomock.Controller) service.FollowService {
+				return svcmock.NewMockFollowService(ctrl)
+			},
+			query:    "uid=456&offset=0&limit=1000",
+			wantBody: Result{Code: 4, Msg: "Invalid offset or limit"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := gin.Default()
+			server.Use(func(ctx *gin.Context) {
+				ctx.Set("users", &ijwt.UserClaims{Id: 123})
+			})
+			NewFollowHandler(tc.mock(ctrl), logger.NewNoOpLogger()).RegisterRoutes(server)
+			req, err := http.NewRequest(http.MethodGet, "/follow/followers?"+tc.query, nil)
+			require.NoError(t, err)
+			resp := httptest.NewRecorder()
+
+			server.ServeHTTP(resp, req)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			var res Result
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantBody, res)
+		})
+	}
+}
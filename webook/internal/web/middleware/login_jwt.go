@@ -3,19 +3,25 @@ package middleware
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
-	"log"
 	"net/http"
 	"strings"
-	"time"
-	"webook/internal/web"
+	ijwt "webook/pkg/ginx/jwt"
 )
 
 type LoginJWTMiddlewareBuilder struct {
 	paths []string
+	// 这些前缀开头的路径都不需要登录，用于带路径参数的公开接口
+	prefixes []string
+	// 这些前缀开头的路径登录不登录都行，带了有效的 token 就解析出来，
+	// 用于对登录用户有区别的公开接口，比如要过滤掉自己拉黑的作者
+	optionalPrefixes []string
+	ijwt.Handler
 }
 
-func NewLoginJWTMiddlewareBuilder() *LoginJWTMiddlewareBuilder {
-	return &LoginJWTMiddlewareBuilder{}
+func NewLoginJWTMiddlewareBuilder(hdl ijwt.Handler) *LoginJWTMiddlewareBuilder {
+	return &LoginJWTMiddlewareBuilder{
+		Handler: hdl,
+	}
 }
 
 func (l *LoginJWTMiddlewareBuilder) Build() gin.HandlerFunc {
@@ -27,61 +33,82 @@ func (l *LoginJWTMiddlewareBuilder) Build() gin.HandlerFunc {
 				return
 			}
 		}
-
-		tokenHeader := ctx.GetHeader("Authorization")
-		if tokenHeader == "" {
-			//	没登陆
-			ctx.AbortWithStatus(http.StatusUnauthorized)
-			return
-		}
-		segs := strings.SplitN(tokenHeader, " ", 2)
-		if len(segs) != 2 {
-			//	没登陆
-			ctx.AbortWithStatus(http.StatusUnauthorized)
-			return
+		for _, prefix := range l.prefixes {
+			if strings.HasPrefix(ctx.Request.URL.Path, prefix) {
+				return
+			}
 		}
-		tokenStr := segs[1]
-		claims := &web.UserClaims{}
-		token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
-			return []byte("f2d9e3c7b4a1f5d8e0c6b3a7d1f4e9a2"), nil
-		})
-
-		if err != nil {
-			//	没登陆
-			ctx.AbortWithStatus(http.StatusUnauthorized)
-			return
+		optional := false
+		for _, prefix := range l.optionalPrefixes {
+			if strings.HasPrefix(ctx.Request.URL.Path, prefix) {
+				optional = true
+				break
+			}
 		}
 
-		if token == nil || !token.Valid || claims.Uid == 0 {
+		claims, ok := l.parse(ctx)
+		if !ok {
+			if optional {
+				// 当成没登录的读者
+				return
+			}
 			ctx.AbortWithStatus(http.StatusUnauthorized)
 			return
 		}
+		// 只是记录一下最近活跃时间，失败了不影响请求
+		_ = l.TouchSession(ctx, claims.Id, claims.Ssid)
 
-		if claims.UserAgent != ctx.Request.UserAgent() {
-			//	严重的安全问题
-			ctx.AbortWithStatus(http.StatusUnauthorized)
-		}
-
-		//10秒刷新一次
+		ctx.Set("users", claims)
+	}
+}
 
-		now := time.Now()
-		if claims.ExpiresAt.Sub(now) < time.Second*50 {
+// parse 解析并且校验短 token，不合法返回 false
+func (l *LoginJWTMiddlewareBuilder) parse(ctx *gin.Context) (*ijwt.UserClaims, bool) {
+	tokenStr := l.ExtractToken(ctx)
+	if tokenStr == "" {
+		//	没登陆
+		return nil, false
+	}
+	claims := &ijwt.UserClaims{}
+	token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) {
+		return ijwt.AtKey, nil
+	})
 
-			claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(time.Minute * 1))
-			tokenStr, err = token.SignedString([]byte("f2d9e3c7b4a1f5d8e0c6b3a7d1f4e9a2"))
-			if err != nil {
-				log.Print("jwt signing error:", err)
-			}
+	if err != nil {
+		//	没登陆
+		return nil, false
+	}
 
-			ctx.Header("x-jwt-token", tokenStr)
+	if token == nil || !token.Valid || claims.Id == 0 {
+		return nil, false
+	}
 
-		}
-		ctx.Set("claims", claims)
+	if claims.UserAgent != ctx.Request.UserAgent() {
+		//	严重的安全问题
+		return nil, false
+	}
 
+	// 短 token 过期了就让前端拿 refresh token 来换，这里不再续约
+	// 但是要看一下这个 session 有没有被撤销，用户注销清除之后也会被撤销
+	err = l.CheckSession(ctx, claims.Id, claims.Ssid)
+	if err != nil {
+		// 要么 redis 有问题，要么已经退出登录，要么账号已经清除
+		return nil, false
 	}
+	return claims, true
 }
 
 func (l *LoginJWTMiddlewareBuilder) IgnorePath(path string) *LoginJWTMiddlewareBuilder {
 	l.paths = append(l.paths, path)
 	return l
 }
+
+func (l *LoginJWTMiddlewareBuilder) IgnorePrefix(prefix string) *LoginJWTMiddlewareBuilder {
+	l.prefixes = append(l.prefixes, prefix)
+	return l
+}
+
+func (l *LoginJWTMiddlewareBuilder) OptionalPrefix(prefix string) *LoginJWTMiddlewareBuilder {
+	l.optionalPrefixes = append(l.optionalPrefixes, prefix)
+	return l
+}
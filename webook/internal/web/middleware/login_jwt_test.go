@@ -0,0 +1,192 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	ijwt "webook/pkg/ginx/jwt"
+	jwtmocks "webook/pkg/ginx/jwt/mocks"
+)
+
+func TestLoginJWTMiddlewareBuilder_Build(t *testing.T) {
+	const userAgent = "webook-test"
+	accessToken := func(key []byte, uid int64, exp time.Duration) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS512, ijwt.UserClaims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(exp)),
+			},
+			Id:        uid,
+			Ssid:      "ssid-1",
+			UserAgent: userAgent,
+		})
+		str, err := token.SignedString(key)
+		require.NoError(t, err)
+		return str
+	}
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) ijwt.Handler
+		wantCode int
+		wantUid  int64
+	}{
+		{
+			name: "pass",
+			mock: func(ctrl *gomock.Controller) ijwt.Handler {
+				hdl := jwtmocks.NewMockHandler(ctrl)
+				hdl.EXPECT().ExtractToken(gomock.Any()).
+					Return(accessToken(ijwt.AtKey, 123, time.Minute))
+				hdl.EXPECT().CheckSession(gomock.Any(), int64(123), "ssid-1").Return(nil)
+				hdl.EXPECT().TouchSession(gomock.Any(), int64(123), "ssid-1").Return(nil)
+				return hdl
+			},
+			wantCode: http.StatusOK,
+			wantUid:  123,
+		},
+		{
+			name: "no token",
+			mock: func(ctrl *gomock.Controller) ijwt.Handler {
+				hdl := jwtmocks.NewMockHandler(ctrl)
+				hdl.EXPECT().ExtractToken(gomock.Any()).Return("")
+				return hdl
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name: "refresh token used as access token",
+			mock: func(ctrl *gomock.Controller) ijwt.Handler {
+				hdl := jwtmocks.NewMockHandler(ctrl)
+				hdl.EXPECT().ExtractToken(gomock.Any()).
+					Return(accessToken(ijwt.RtKey, 123, time.Minute))
+				return hdl
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name: "expired",
+			mock: func(ctrl *gomock.Controller) ijwt.Handler {
+				hdl := jwtmocks.NewMockHandler(ctrl)
+				hdl.EXPECT().ExtractToken(gomock.Any()).
+					Return(accessToken(ijwt.AtKey, 123, -time.Minute))
+				return hdl
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name: "session revoked",
+			mock: func(ctrl *gomock.Controller) ijwt.Handler {
+				hdl := jwtmocks.NewMockHandler(ctrl)
+				hdl.EXPECT().ExtractToken(gomock.Any()).
+					Return(accessToken(ijwt.AtKey, 123, time.Minute))
+				hdl.EXPECT().CheckSession(gomock.Any(), int64(123), "ssid-1").
+					Return(ijwt.ErrSessionRevoked)
+				return hdl
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			var uid int64
+			server := gin.New()
+			server.Use(NewLoginJWTMiddlewareBuilder(tc.mock(ctrl)).Build())
+			server.GET("/profile", func(ctx *gin.Context) {
+				uid = ctx.MustGet("users").(*ijwt.UserClaims).Id
+			})
+			req, err := http.NewRequest(http.MethodGet, "/profile", nil)
+			require.NoError(t, err)
+			req.Header.Set("User-Agent", userAgent)
+			resp := httptest.NewRecorder()
+
+			server.ServeHTTP(resp, req)
+			assert.Equal(t, tc.wantCode, resp.Code)
+			assert.Equal(t, tc.wantUid, uid)
+		})
+	}
+}
+
+func TestLoginJWTMiddlewareBuilder_OptionalPrefix(t *testing.T) {
+	const userAgent = "webook-test"
+	token := jwt.NewWithClaims(jwt.SigningMethodHS512, ijwt.UserClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+		Id:        123,
+		Ssid:      "ssid-1",
+		UserAgent: userAgent,
+	})
+	validToken, err := token.SignedString(ijwt.AtKey)
+	require.NoError(t, err)
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) ijwt.Handler
+		wantCode int
+		wantUid  int64
+	}{
+		{
+			name: "logged in",
+			mock: func(ctrl *gomock.Controller) ijwt.Handler {
+				hdl := jwtmocks.NewMockHandler(ctrl)
+				hdl.EXPECT().ExtractToken(gomock.Any()).Return(validToken)
+				hdl.EXPECT().CheckSession(gomock.Any(), int64(123), "ssid-1").Return(nil)
+				hdl.EXPECT().TouchSession(gomock.Any(), int64(123), "ssid-1").Return(nil)
+				return hdl
+			},
+			wantCode: http.StatusOK,
+			wantUid:  123,
+		},
+		{
+			name: "anonymous",
+			mock: func(ctrl *gomock.Controller) ijwt.Handler {
+				hdl := jwtmocks.NewMockHandler(ctrl)
+				hdl.EXPECT().ExtractToken(gomock.Any()).Return("")
+				return hdl
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			// 撤销了的 session 也当成没登录，不能报 401
+			name: "session revoked",
+			mock: func(ctrl *gomock.Controller) ijwt.Handler {
+				hdl := jwtmocks.NewMockHandler(ctrl)
+				hdl.EXPECT().ExtractToken(gomock.Any()).Return(validToken)
+				hdl.EXPECT().CheckSession(gomock.Any(), int64(123), "ssid-1").
+					Return(ijwt.ErrSessionRevoked)
+				return hdl
+			},
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			var uid int64
+			server := gin.New()
+			server.Use(NewLoginJWTMiddlewareBuilder(tc.mock(ctrl)).
+				OptionalPrefix("/series/detail/").Build())
+			server.GET("/series/detail/:id", func(ctx *gin.Context) {
+				if val, ok := ctx.Get("users"); ok {
+					uid = val.(*ijwt.UserClaims).Id
+				}
+			})
+			req, err := http.NewRequest(http.MethodGet, "/series/detail/1", nil)
+			require.NoError(t, err)
+			req.Header.Set("User-Agent", userAgent)
+			resp := httptest.NewRecorder()
+
+			server.ServeHTTP(resp, req)
+			assert.Equal(t, tc.wantCode, resp.Code)
+			assert.Equal(t, tc.wantUid, uid)
+		})
+	}
+}
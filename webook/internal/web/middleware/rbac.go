@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"webook/internal/domain"
+	ijwt "webook/pkg/ginx/jwt"
+)
+
+// RBACMiddlewareBuilder 路由级别的权限校验，必须放在登录校验后面
+type RBACMiddlewareBuilder struct {
+	roles []string
+}
+
+// NewRBACMiddlewareBuilder 有其中任何一个角色就可以访问
+func NewRBACMiddlewareBuilder(roles ...domain.Role) *RBACMiddlewareBuilder {
+	return &RBACMiddlewareBuilder{
+		roles: slice.Map(roles, func(idx int, src domain.Role) string {
+			return src.String()
+		}),
+	}
+}
+
+func (b *RBACMiddlewareBuilder) Build() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		val, _ := ctx.Get("users")
+		claims, ok := val.(*ijwt.UserClaims)
+		if !ok {
+			// 没有经过登录校验
+			ctx.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		if !claims.HasAnyRole(b.roles...) {
+			ctx.AbortWithStatus(http.StatusForbidden)
+			return
+		}
+	}
+}
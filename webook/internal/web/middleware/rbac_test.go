@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"webook/internal/domain"
+	ijwt "webook/pkg/ginx/jwt"
+)
+
+func TestRBACMiddlewareBuilder_Build(t *testing.T) {
+	testCases := []struct {
+		name string
+		// nil 表示没有登录
+		claims   *ijwt.UserClaims
+		wantCode int
+	}{
+		{
+			name:     "admin",
+			claims:   &ijwt.UserClaims{Id: 1, Roles: []string{"reader", "admin"}},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "moderator",
+			claims:   &ijwt.UserClaims{Id: 1, Roles: []string{"reader", "moderator"}},
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "reader",
+			claims:   &ijwt.UserClaims{Id: 1, Roles: []string{"reader"}},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "old token without roles",
+			claims:   &ijwt.UserClaims{Id: 1},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "not logged in",
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := gin.New()
+			server.Use(func(ctx *gin.Context) {
+				if tc.claims != nil {
+					ctx.Set("users", tc.claims)
+				}
+			})
+			server.GET("/admin",
+				NewRBACMiddlewareBuilder(domain.RoleModerator, domain.RoleAdmin).Build(),
+				func(ctx *gin.Context) {
+					ctx.Status(http.StatusOK)
+				})
+			req, err := http.NewRequest(http.MethodGet, "/admin", nil)
+			require.NoError(t, err)
+			resp := httptest.NewRecorder()
+			server.ServeHTTP(resp, req)
+			assert.Equal(t, tc.wantCode, resp.Code)
+		})
+	}
+}
@@ -6,3 +6,6 @@ type Result struct {
 	Msg  string `json:"msg"`
 	Data any    `json:"data"`
 }
+
+// codeConflict 数据在别的地方被改过了，前端要提示用户刷新或者合并
+const codeConflict = 409
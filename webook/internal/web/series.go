@@ -0,0 +1,292 @@
+package web
+
+import (
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+	"webook/internal/domain"
+	"webook/internal/service"
+	ijwt "webook/pkg/ginx/jwt"
+	"webook/pkg/logger"
+)
+
+const (
+	// 自己的系列列表一页最多多少条
+	seriesListMaxLimit = 50
+	// 一个系列最多多少篇文章
+	seriesMaxArticles          = 100
+	seriesTitleMaxLength       = 100
+	seriesDescriptionMaxLength = 500
+)
+
+type SeriesHandler struct {
+	svc      service.SeriesService
+	blockSvc service.BlockService
+	log      logger.LoggerV1
+}
+
+func NewSeriesHandler(svc service.SeriesService, blockSvc service.BlockService, log logger.LoggerV1) *SeriesHandler {
+	return &SeriesHandler{
+		svc:      svc,
+		blockSvc: blockSvc,
+		log:      log,
+	}
+}
+
+func (h *SeriesHandler) RegisterRoutes(server *gin.Engine) {
+	sg := server.Group("/series")
+	sg.POST("/create", h.Create)
+	sg.POST("/edit", h.Edit)
+	sg.POST("/delete", h.Delete)
+	// 传入全部文章的 id，按照顺序覆盖原来的
+	sg.POST("/articles", h.SetArticles)
+	// 自己的系列，/list?offset=?&limit=?
+	sg.GET("/list", h.List)
+	// 不需要登录，登录了会检查是不是拉黑了作者
+	sg.GET("/detail/:id", h.Detail)
+}
+
+type SeriesReq struct {
+	Id          int64  `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// valid 不合法就直接返回给前端
+func (req SeriesReq) valid(ctx *gin.Context) bool {
+	var msg string
+	switch {
+	case req.Title == "" || utf8.RuneCountInString(req.Title) > seriesTitleMaxLength:
+		msg = "Invalid title"
+	case utf8.RuneCountInString(req.Description) > seriesDescriptionMaxLength:
+		msg = "Description too long"
+	default:
+		return true
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Code: 4,
+		Msg:  msg,
+	})
+	return false
+}
+
+func (req SeriesReq) toDomain(uid int64) domain.Series {
+	return domain.Series{
+		Id:          req.Id,
+		AuthorId:    uid,
+		Title:       req.Title,
+		Description: req.Description,
+	}
+}
+
+func (h *SeriesHandler) Create(ctx *gin.Context) {
+	var req SeriesReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	if !req.valid(ctx) {
+		return
+	}
+	uc := ctx.MustGet("users").(*ijwt.UserClaims)
+	id, err := h.svc.Create(ctx, req.toDomain(uc.Id))
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("创建系列失败",
+			logger.Int64("uid", uc.Id),
+			logger.Error(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: id,
+	})
+}
+
+func (h *SeriesHandler) Edit(ctx *gin.Context) {
+	var req SeriesReq
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	if !req.valid(ctx) {
+		return
+	}
+	uc := ctx.MustGet("users").(*ijwt.UserClaims)
+	err := h.svc.Update(ctx, req.toDomain(uc.Id))
+	h.respond(ctx, err, "修改系列失败", uc.Id, req.Id)
+}
+
+func (h *SeriesHandler) Delete(ctx *gin.Context) {
+	type Req struct {
+		Id int64 `json:"id"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	uc := ctx.MustGet("users").(*ijwt.UserClaims)
+	err := h.svc.Delete(ctx, uc.Id, req.Id)
+	h.respond(ctx, err, "删除系列失败", uc.Id, req.Id)
+}
+
+func (h *SeriesHandler) SetArticles(ctx *gin.Context) {
+	type Req struct {
+		Id   int64   `json:"id"`
+		Aids []int64 `json:"aids"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	if len(req.Aids) > seriesMaxArticles {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Too many articles",
+		})
+		return
+	}
+	seen := make(map[int64]struct{}, len(req.Aids))
+	for _, aid := range req.Aids {
+		if _, ok := seen[aid]; ok {
+			ctx.JSON(http.StatusOK, Result{
+				Code: 4,
+				Msg:  "Duplicate articles",
+			})
+			return
+		}
+		seen[aid] = struct{}{}
+	}
+	uc := ctx.MustGet("users").(*ijwt.UserClaims)
+	err := h.svc.SetArticles(ctx, uc.Id, req.Id, req.Aids)
+	h.respond(ctx, err, "修改系列文章失败", uc.Id, req.Id)
+}
+
+// respond 修改类接口的错误处理都一样
+func (h *SeriesHandler) respond(ctx *gin.Context, err error, logMsg string, uid int64, sid int64) {
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "OK",
+		})
+	case service.ErrSeriesNotFound:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Series not found",
+		})
+	case service.ErrSeriesInvalidArticle:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Only your published articles can be added",
+		})
+	case service.ErrArticleInOtherSeries:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Article already in another series",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error(logMsg,
+			logger.Int64("uid", uid),
+			logger.Int64("sid", sid),
+			logger.Error(err))
+	}
+}
+
+func (h *SeriesHandler) List(ctx *gin.Context) {
+	type Req struct {
+		Offset int `form:"offset"`
+		Limit  int `form:"limit"`
+	}
+	var req Req
+	if err := ctx.BindQuery(&req); err != nil {
+		return
+	}
+	if req.Offset < 0 || req.Limit <= 0 || req.Limit > seriesListMaxLimit {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Invalid offset or limit",
+		})
+		return
+	}
+	uc := ctx.MustGet("users").(*ijwt.UserClaims)
+	ss, err := h.svc.ListByAuthor(ctx, uc.Id, req.Offset, req.Limit)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("查询系列列表失败",
+			logger.Int64("uid", uc.Id),
+			logger.Error(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: slice.Map(ss, func(idx int, src domain.Series) SeriesVO {
+			return newSeriesVO(src)
+		}),
+	})
+}
+
+func (h *SeriesHandler) Detail(ctx *gin.Context) {
+	sid, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Invalid series id",
+		})
+		return
+	}
+	s, err := h.svc.Detail(ctx, sid)
+	switch err {
+	case nil:
+		h.detail(ctx, s)
+	case service.ErrSeriesNotFound:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Series not found",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "系统错误",
+		})
+		h.log.Error("查询系列失败",
+			logger.Int64("sid", sid),
+			logger.Error(err))
+	}
+}
+
+// detail 登录了的读者看不到自己拉黑的作者的系列
+func (h *SeriesHandler) detail(ctx *gin.Context, s domain.Series) {
+	uid := readerId(ctx)
+	if uid > 0 {
+		blocked, err := h.blockSvc.Blocked(ctx, uid, s.AuthorId)
+		if err != nil {
+			ctx.JSON(http.StatusOK, Result{
+				Code: 5,
+				Msg:  "系统错误",
+			})
+			h.log.Error("查询拉黑关系失败",
+				logger.Int64("uid", uid),
+				logger.Int64("author", s.AuthorId),
+				logger.Error(err))
+			return
+		}
+		if blocked {
+			ctx.JSON(http.StatusOK, Result{
+				Code: 4,
+				Msg:  "You have blocked the author",
+			})
+			return
+		}
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: newSeriesVO(s),
+	})
+}
@@ -0,0 +1,163 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"webook/internal/domain"
+	"webook/internal/service"
+	svcmock "webook/internal/service/mocks"
+	ijwt "webook/pkg/ginx/jwt"
+	"webook/pkg/logger"
+)
+
+func TestSeriesHandler_SetArticles(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) service.SeriesService
+		reqBody  string
+		wantBody Result
+	}{
+		{
+			name: "设置成功",
+			mock: func(ctrl *gomock.Controller) service.SeriesService {
+				svc := svcmock.NewMockSeriesService(ctrl)
+				svc.EXPECT().SetArticles(gomock.Any(), int64(123), int64(1), []int64{3, 2, 5}).Return(nil)
+				return svc
+			},
+			reqBody:  `{"id":1,"aids":[3,2,5]}`,
+			wantBody: Result{Msg: "OK"},
+		},
+		{
+			name: "文章重复",
+			mock: func(ctrl *gomock.Controller) service.SeriesService {
+				return svcmock.NewMockSeriesService(ctrl)
+			},
+			reqBody:  `{"id":1,"aids":[3,2,3]}`,
+			wantBody: Result{Code: 4, Msg: "Duplicate articles"},
+		},
+		{
+			name: "不是自己的系列",
+			mock: func(ctrl *gomock.Controller) service.SeriesService {
+				svc := svcmock.NewMockSeriesService(ctrl)
+				svc.EXPECT().SetArticles(gomock.Any(), int64(123), int64(1), []int64{3}).
+					Return(service.ErrSeriesNotFound)
+				return svc
+			},
+			reqBody:  `{"id":1,"aids":[3]}`,
+			wantBody: Result{Code: 4, Msg: "Series not found"},
+		},
+		{
+			name: "文章在别的系列里面",
+			mock: func(ctrl *gomock.Controller) service.SeriesService {
+				svc := svcmock.NewMockSeriesService(ctrl)
+				svc.EXPECT().SetArticles(gomock.Any(), int64(123), int64(1), []int64{3}).
+					Return(service.ErrArticleInOtherSeries)
+				return svc
+			},
+			reqBody:  `{"id":1,"aids":[3]}`,
+			wantBody: Result{Code: 4, Msg: "Article already in another series"},
+		},
+		{
+			name: "不是自己发表的文章",
+			mock: func(ctrl *gomock.Controller) service.SeriesService {
+				svc := svcmock.NewMockSeriesService(ctrl)
+				svc.EXPECT().SetArticles(gomock.Any(), int64(123), int64(1), []int64{3}).
+					Return(service.ErrSeriesInvalidArticle)
+				return svc
+			},
+			reqBody:  `{"id":1,"aids":[3]}`,
+			wantBody: Result{Code: 4, Msg: "Only your published articles can be added"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := gin.Default()
+			server.Use(func(ctx *gin.Context) {
+				ctx.Set("users", &ijwt.UserClaims{Id: 123})
+			})
+			NewSeriesHandler(tc.mock(ctrl), svcmock.NewMockBlockService(ctrl), logger.NewNoOpLogger()).RegisterRoutes(server)
+			req, err := http.NewRequest(http.MethodPost, "/series/articles",
+				bytes.NewBuffer([]byte(tc.reqBody)))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+
+			server.ServeHTTP(resp, req)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			var res Result
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantBody, res)
+		})
+	}
+}
+
+func TestSeriesHandler_Detail(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) (service.SeriesService, service.BlockService)
+		uid      int64
+		wantBody Result
+	}{
+		{
+			// 没登录不用查拉黑关系
+			name: "没登录",
+			mock: func(ctrl *gomock.Controller) (service.SeriesService, service.BlockService) {
+				svc := svcmock.NewMockSeriesService(ctrl)
+				svc.EXPECT().Detail(gomock.Any(), int64(1)).
+					Return(domain.Series{Id: 1, AuthorId: 456, Title: "系列"}, nil)
+				return svc, svcmock.NewMockBlockService(ctrl)
+			},
+			wantBody: Result{Data: map[string]any{
+				"id": float64(1), "authorId": float64(456), "title": "系列", "description": "",
+				"ctime": "0001-01-01 00:00:00", "utime": "0001-01-01 00:00:00",
+			}},
+		},
+		{
+			name: "拉黑了作者",
+			mock: func(ctrl *gomock.Controller) (service.SeriesService, service.BlockService) {
+				svc := svcmock.NewMockSeriesService(ctrl)
+				blockSvc := svcmock.NewMockBlockService(ctrl)
+				svc.EXPECT().Detail(gomock.Any(), int64(1)).
+					Return(domain.Series{Id: 1, AuthorId: 456, Title: "系列"}, nil)
+				blockSvc.EXPECT().Blocked(gomock.Any(), int64(123), int64(456)).Return(true, nil)
+				return svc, blockSvc
+			},
+			uid:      123,
+			wantBody: Result{Code: 4, Msg: "You have blocked the author"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := gin.Default()
+			if tc.uid > 0 {
+				server.Use(func(ctx *gin.Context) {
+					ctx.Set("users", &ijwt.UserClaims{Id: tc.uid})
+				})
+			}
+			svc, blockSvc := tc.mock(ctrl)
+			NewSeriesHandler(svc, blockSvc, logger.NewNoOpLogger()).RegisterRoutes(server)
+			req, err := http.NewRequest(http.MethodGet, "/series/detail/1", nil)
+			require.NoError(t, err)
+			resp := httptest.NewRecorder()
+
+			server.ServeHTTP(resp, req)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			var res Result
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantBody, res)
+		})
+	}
+}
@@ -1 +1,19 @@
 package web
+
+import (
+	"github.com/gin-gonic/gin"
+	ijwt "webook/pkg/ginx/jwt"
+)
+
+// readerId 登录可选的公开接口上的读者，没登录返回 0
+func readerId(ctx *gin.Context) int64 {
+	val, ok := ctx.Get("users")
+	if !ok {
+		return 0
+	}
+	uc, ok := val.(*ijwt.UserClaims)
+	if !ok {
+		return 0
+	}
+	return uc.Id
+}
@@ -3,35 +3,54 @@ package web
 import (
 	"fmt"
 	regexp "github.com/dlclark/regexp2"
-	"github.com/gin-contrib/sessions"
+	"github.com/ecodeclub/ekit/slice"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"net/http"
+	"sort"
 	"time"
+	"unicode/utf8"
 	"webook/internal/domain"
 	"webook/internal/service"
+	ijwt "webook/pkg/ginx/jwt"
 )
 
 const (
 	emailRegexPattern = `^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`
 
 	passwordRegexPattern = `^(?=.*[A-Za-z])(?=.*\d).{8,}$`
+
+	// 大陆手机号
+	phoneRegexPattern = `^1[3-9]\d{9}$`
+
+	bizLogin = "login"
+
+	nicknameMaxLen = 32
+	aboutMeMaxLen  = 1024
 )
 
 type UserHandler struct {
-	svc service.UserService
+	svc       service.UserService
+	codeSvc   service.CodeService
+	resetSvc  service.PasswordResetService
+	verifySvc service.EmailVerifyService
+	mfaSvc    service.MFAService
+	dataSvc   service.UserDataService
+	ijwt.Handler
 }
 
-// JWT
-type UserClaims struct {
-	jwt.RegisteredClaims
-	Uid       int64
-	UserAgent string
-}
-
-func NewUserHandler(svc service.UserService) *UserHandler {
+func NewUserHandler(svc service.UserService, codeSvc service.CodeService,
+	resetSvc service.PasswordResetService, verifySvc service.EmailVerifyService,
+	mfaSvc service.MFAService, dataSvc service.UserDataService,
+	jwtHdl ijwt.Handler) *UserHandler {
 	return &UserHandler{
-		svc: svc,
+		svc:       svc,
+		codeSvc:   codeSvc,
+		resetSvc:  resetSvc,
+		verifySvc: verifySvc,
+		mfaSvc:    mfaSvc,
+		dataSvc:   dataSvc,
+		Handler:   jwtHdl,
 	}
 }
 
@@ -99,7 +118,8 @@ func (uh *UserHandler) SignUp(ctx *gin.Context) {
 		return
 	}
 
-	//登陆成功
+	// 发验证邮件失败了不影响注册，用户可以自己重发
+	_ = uh.verifySvc.SendVerifyEmail(ctx, req.Email)
 
 	ctx.String(http.StatusOK, "Sign up successful")
 	//	数据库操作
@@ -162,13 +182,23 @@ func (uh *UserHandler) LogInJWT(ctx *gin.Context) {
 	user, err := uh.svc.LogIn(ctx, domain.User{
 		Email:    req.Email,
 		Password: req.Password,
-	})
+	}, ctx.ClientIP())
 
 	if err == service.ErrInvalidUserOrPassword {
 		ctx.String(http.StatusOK, "Invalid email or password")
 		return
 	}
 
+	if err == service.ErrAccountLocked {
+		ctx.String(http.StatusOK, "Account temporarily locked, check your email to unlock")
+		return
+	}
+
+	if err == service.ErrLoginTooFrequent {
+		ctx.String(http.StatusOK, "Too many failed attempts, please try again later")
+		return
+	}
+
 	if err != nil {
 		ctx.String(http.StatusOK, "System error")
 		return
@@ -183,114 +213,618 @@ func (uh *UserHandler) LogInJWT(ctx *gin.Context) {
 	//})
 	//sess.Save()
 
-	claims := UserClaims{
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 1)),
-		},
-		Uid:       user.Id,
-		UserAgent: ctx.Request.UserAgent(),
-	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS512, claims)
-	tokenStr, err := token.SignedString([]byte("f2d9e3c7b4a1f5d8e0c6b3a7d1f4e9a2"))
+	mfaRequired, err := loginOrMFA(ctx, uh.mfaSvc, uh.Handler, user)
 	if err != nil {
-		ctx.String(http.StatusInternalServerError, "System error")
+		ctx.String(http.StatusOK, "System error")
+		return
+	}
+	if mfaRequired {
+		// 先不发登录态，只给一个很短的令牌去做第二步
+		ctx.String(http.StatusOK, "MFA required")
+		return
 	}
-	ctx.Header("x-jwt-token", tokenStr)
-	fmt.Println(user)
-	fmt.Println(tokenStr)
 
 	ctx.String(http.StatusOK, "Sign in successful")
 	return
 }
 
-func (u *UserHandler) LogOut(ctx *gin.Context) {
+func (uh *UserHandler) SendLoginSMSCode(ctx *gin.Context) {
+	type Req struct {
+		Phone string `json:"phone"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	phoneReg := regexp.MustCompile(phoneRegexPattern, 0)
+	isMatch, err := phoneReg.MatchString(req.Phone)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		return
+	}
+	if !isMatch {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Phone format error",
+		})
+		return
+	}
+
+	err = uh.codeSvc.Send(ctx, bizLogin, req.Phone)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "Code sent",
+		})
+	case service.ErrCodeSendTooMany:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Too many requests, try again later",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+	}
+}
+
+func (uh *UserHandler) LoginSMS(ctx *gin.Context) {
+	type Req struct {
+		Phone string `json:"phone"`
+		Code  string `json:"code"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
 
-	sess := sessions.Default(ctx)
+	ok, err := uh.codeSvc.Verify(ctx, bizLogin, req.Phone, req.Code)
+	if err == service.ErrCodeVerifyTooManyTimes {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Too many attempts, please resend the code",
+		})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		return
+	}
+	if !ok {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Invalid code",
+		})
+		return
+	}
 
-	sess.Options(sessions.Options{MaxAge: -1})
+	// 手机号第一次登录就直接注册
+	user, err := uh.svc.FindOrCreate(ctx, req.Phone)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		return
+	}
+	mfaRequired, err := loginOrMFA(ctx, uh.mfaSvc, uh.Handler, user)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		return
+	}
+	if mfaRequired {
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "MFA required",
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "Sign in successful",
+	})
+}
 
-	ctx.String(http.StatusOK, "Sign out successful")
-	return
+// RefreshToken 用长 token 换一个新的短 token
+func (uh *UserHandler) RefreshToken(ctx *gin.Context) {
+	// 约定前端在 Authorization 里面带上 refresh token
+	tokenStr := uh.ExtractToken(ctx)
+	var rc ijwt.RefreshClaims
+	token, err := jwt.ParseWithClaims(tokenStr, &rc, func(token *jwt.Token) (interface{}, error) {
+		return ijwt.RtKey, nil
+	})
+	if err != nil || token == nil || !token.Valid {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	// 退出登录之后 refresh token 也不能再用
+	err = uh.CheckSession(ctx, rc.Uid, rc.Ssid)
+	if err != nil {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+
+	// 角色每次刷新都重新查，管理员改了角色最多半个小时就生效
+	u, err := uh.svc.Profile(ctx, rc.Uid)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		return
+	}
+	// 账号已经清除了，只剩一个空壳
+	if u.Purged {
+		ctx.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	err = uh.SetJWTToken(ctx, rc.Uid, rc.Ssid, roleNames(u))
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "OK",
+	})
+}
+
+// LogoutJWT 退出登录，ssid 会进 redis 的黑名单
+func (uh *UserHandler) LogoutJWT(ctx *gin.Context) {
+	err := uh.ClearToken(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "Sign out successful",
+	})
+}
+
+// Sessions 列出所有登录的设备
+func (uh *UserHandler) Sessions(ctx *gin.Context) {
+	uc := ctx.MustGet("users").(*ijwt.UserClaims)
+	sessions, err := uh.Handler.Sessions(ctx, uc.Id)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		return
+	}
+	// 最近活跃的排前面
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeen.After(sessions[j].LastSeen)
+	})
+	ctx.JSON(http.StatusOK, Result{
+		Data: slice.Map[ijwt.Session, SessionVO](sessions, func(idx int, src ijwt.Session) SessionVO {
+			return SessionVO{
+				Ssid:      src.Ssid,
+				UserAgent: src.UserAgent,
+				IP:        src.IP,
+				LoginTime: src.LoginTime.Format(time.DateTime),
+				LastSeen:  src.LastSeen.Format(time.DateTime),
+				Current:   src.Ssid == uc.Ssid,
+			}
+		}),
+	})
+}
+
+// RevokeSession 踢掉某一个设备
+func (uh *UserHandler) RevokeSession(ctx *gin.Context) {
+	type Req struct {
+		Ssid string `json:"ssid"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	if req.Ssid == "" {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "ssid is required",
+		})
+		return
+	}
+	uc := ctx.MustGet("users").(*ijwt.UserClaims)
+	// 只能操作自己名下的 session，别人的 ssid 在自己的索引里面找不到
+	sessions, err := uh.Handler.Sessions(ctx, uc.Id)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		return
+	}
+	_, found := slice.Find[ijwt.Session](sessions, func(src ijwt.Session) bool {
+		return src.Ssid == req.Ssid
+	})
+	if !found {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "session not found",
+		})
+		return
+	}
+	err = uh.Handler.RevokeSession(ctx, uc.Id, req.Ssid)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "OK",
+	})
+}
+
+// RevokeOtherSessions 退出其它所有设备
+func (uh *UserHandler) RevokeOtherSessions(ctx *gin.Context) {
+	uc := ctx.MustGet("users").(*ijwt.UserClaims)
+	err := uh.Handler.RevokeOtherSessions(ctx, uc.Id, uc.Ssid)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "OK",
+	})
+}
+
+// UnlockAccount 处理解锁邮件里面的链接，不需要登录
+func (uh *UserHandler) UnlockAccount(ctx *gin.Context) {
+	type Req struct {
+		Token string `json:"token"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	err := uh.svc.UnlockAccount(ctx, req.Token)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "OK",
+		})
+	case service.ErrInvalidUnlockToken:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Unlock link is invalid or expired",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+	}
+}
+
+// ForgotPassword 发送重置密码的邮件
+func (uh *UserHandler) ForgotPassword(ctx *gin.Context) {
+	type Req struct {
+		Email string `json:"email"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	emailReg := regexp.MustCompile(emailRegexPattern, 0)
+	isMatch, err := emailReg.MatchString(req.Email)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		return
+	}
+	if !isMatch {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Email format error",
+		})
+		return
+	}
+	err = uh.resetSvc.SendResetEmail(ctx, req.Email)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		return
+	}
+	// 不管邮箱有没有注册都是一样的返回
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "If the email is registered, a reset link has been sent",
+	})
+}
+
+// ResetPassword 用邮件里面的令牌重置密码，成功之后所有设备都要重新登录
+func (uh *UserHandler) ResetPassword(ctx *gin.Context) {
+	type Req struct {
+		Token           string `json:"token"`
+		Password        string `json:"password"`
+		ConfirmPassword string `json:"confirmPassword"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	passwordReg := regexp.MustCompile(passwordRegexPattern, 0)
+	isMatch, err := passwordReg.MatchString(req.Password)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		return
+	}
+	if !isMatch {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "At least 8 characters in length, containing at least one letter and one number",
+		})
+		return
+	}
+	if req.ConfirmPassword != req.Password {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Password error",
+		})
+		return
+	}
+	uid, err := uh.resetSvc.Reset(ctx, req.Token, req.Password)
+	switch err {
+	case nil:
+	case service.ErrInvalidResetToken:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Reset link is invalid or expired",
+		})
+		return
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		return
+	}
+	// 密码已经改了，这里失败了也只能让用户自己去设备管理里面退出
+	err = uh.Handler.RevokeOtherSessions(ctx, uid, "")
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "OK",
+	})
+}
+
+// SendVerifyEmail 重新给当前邮箱发验证邮件
+func (uh *UserHandler) SendVerifyEmail(ctx *gin.Context) {
+	uc := ctx.MustGet("users").(*ijwt.UserClaims)
+	u, err := uh.svc.Profile(ctx, uc.Id)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		return
+	}
+	if u.Email == "" {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "No email bound",
+		})
+		return
+	}
+	if u.EmailVerified {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Email already verified",
+		})
+		return
+	}
+	err = uh.verifySvc.SendVerifyEmail(ctx, u.Email)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "OK",
+	})
+}
+
+// ChangeEmail 给新邮箱发确认邮件，确认之后才会真正换绑
+func (uh *UserHandler) ChangeEmail(ctx *gin.Context) {
+	type Req struct {
+		Email string `json:"email"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	emailReg := regexp.MustCompile(emailRegexPattern, 0)
+	isMatch, err := emailReg.MatchString(req.Email)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		return
+	}
+	if !isMatch {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Email format error",
+		})
+		return
+	}
+	uc := ctx.MustGet("users").(*ijwt.UserClaims)
+	err = uh.verifySvc.SendChangeEmail(ctx, uc.Id, req.Email)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "OK",
+		})
+	case service.ErrUserDuplicatedEmail:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Email already exists",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+	}
+}
+
+// VerifyEmail 处理邮件里面的链接，不需要登录
+func (uh *UserHandler) VerifyEmail(ctx *gin.Context) {
+	type Req struct {
+		Token string `json:"token"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	err := uh.verifySvc.Verify(ctx, req.Token)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "OK",
+		})
+	case service.ErrInvalidVerifyToken:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Verification link is invalid or expired",
+		})
+	case service.ErrUserDuplicatedEmail:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Email already exists",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+	}
 }
 
-func (h *UserHandler) Edit(ctx *gin.Context) {
-	//	// 嵌入一段刷新过期时间的代码
-	//	type Req struct {
-	//		// 改邮箱，密码，或者能不能改手机号
-	//
-	//		Nickname string `json:"nickname"`
-	//		// YYYY-MM-DD
-	//		Birthday string `json:"birthday"`
-	//		AboutMe  string `json:"aboutMe"`
-	//	}
-	//	var req Req
-	//	if err := ctx.Bind(&req); err != nil {
-	//		return
-	//	}
-	//	//sess := sessions.Default(ctx)
-	//	//sess.Get("uid")
-	//	uc, ok := ctx.MustGet("user").(UserClaims)
-	//	if !ok {
-	//		//ctx.String(http.StatusOK, "系统错误")
-	//		ctx.AbortWithStatus(http.StatusUnauthorized)
-	//		return
-	//	}
-	//	// 用户输入不对
-	//	birthday, err := time.Parse(time.DateOnly, req.Birthday)
-	//	if err != nil {
-	//		//ctx.String(http.StatusOK, "系统错误")
-	//		ctx.String(http.StatusOK, "生日格式不对")
-	//		return
-	//	}
-	//	err = h.svc.UpdateNonSensitiveInfo(ctx, domain.User{
-	//		Id:       uc.Uid,
-	//		Nickname: req.Nickname,
-	//		Birthday: birthday,
-	//		AboutMe:  req.AboutMe,
-	//	})
-	//	if err != nil {
-	//		ctx.String(http.StatusOK, "系统异常")
-	//		return
-	//	}
-	//	ctx.String(http.StatusOK, "更新成功")
-	//}
-
-	//func (u *UserHandler) Profile(ctx *gin.Context) {
-	//
-	//	ctx.String(http.StatusOK, "Sign in successful")
-	//
+func (uh *UserHandler) Edit(ctx *gin.Context) {
+	type Req struct {
+		// 邮箱、密码、手机号这些敏感信息不在这里改
+		Nickname string `json:"nickname"`
+		// YYYY-MM-DD
+		Birthday string `json:"birthday"`
+		AboutMe  string `json:"aboutMe"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	if utf8.RuneCountInString(req.Nickname) > nicknameMaxLen {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Nickname is too long",
+		})
+		return
+	}
+	if utf8.RuneCountInString(req.AboutMe) > aboutMeMaxLen {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "About me is too long",
+		})
+		return
+	}
+	var birthday time.Time
+	if req.Birthday != "" {
+		var err error
+		birthday, err = time.Parse(time.DateOnly, req.Birthday)
+		if err != nil || birthday.After(time.Now()) {
+			ctx.JSON(http.StatusOK, Result{
+				Code: 4,
+				Msg:  "Invalid birthday",
+			})
+			return
+		}
+	}
+	uc := ctx.MustGet("users").(*ijwt.UserClaims)
+	err := uh.svc.UpdateNonSensitiveInfo(ctx, domain.User{
+		Id:       uc.Id,
+		Nickname: req.Nickname,
+		Birthday: birthday,
+		AboutMe:  req.AboutMe,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "OK",
+	})
 }
 
-func (h *UserHandler) ProfileJWT(ctx *gin.Context) {
-	//
-	//	//us := ctx.MustGet("user").(UserClaims)
-	//	//ctx.String(http.StatusOK, "这是 profile")
-	//	// 嵌入一段刷新过期时间的代码
-	//
-	//	uc, ok := ctx.MustGet("user").(UserClaims)
-	//	if !ok {
-	//		//ctx.String(http.StatusOK, "系统错误")
-	//		ctx.AbortWithStatus(http.StatusUnauthorized)
-	//		return
-	//	}
-	//	u, err := h.svc.FindById(ctx, uc.Uid)
-	//	if err != nil {
-	//		ctx.String(http.StatusOK, "系统异常")
-	//		return
-	//	}
-	//	type User struct {
-	//		Nickname string `json:"nickname"`
-	//		Email    string `json:"email"`
-	//		AboutMe  string `json:"aboutMe"`
-	//		Birthday string `json:"birthday"`
-	//	}
-	//	ctx.JSON(http.StatusOK, User{
-	//		Nickname: u.Nickname,
-	//		Email:    u.Email,
-	//		AboutMe:  u.AboutMe,
-	//		Birthday: u.Birthday.Format(time.DateOnly),
-	//	})
-	//
+func (uh *UserHandler) ProfileJWT(ctx *gin.Context) {
+	uc := ctx.MustGet("users").(*ijwt.UserClaims)
+	u, err := uh.svc.Profile(ctx, uc.Id)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		return
+	}
+	profile := ProfileVO{
+		Nickname:      u.Nickname,
+		Email:         u.Email,
+		EmailVerified: u.EmailVerified,
+		Phone:         u.Phone,
+		AboutMe:       u.AboutMe,
+		Roles:         roleNames(u),
+	}
+	if !u.Birthday.IsZero() {
+		profile.Birthday = u.Birthday.Format(time.DateOnly)
+	}
+	if !u.DeleteAfter.IsZero() {
+		profile.DeleteAfter = u.DeleteAfter.Format(time.DateTime)
+	}
+	ctx.JSON(http.StatusOK, profile)
+}
+
+// roleNames token 里面只放角色的名字
+func roleNames(u domain.User) []string {
+	return slice.Map(u.Roles, func(idx int, src domain.Role) string {
+		return src.String()
+	})
 }
 
 func (u *UserHandler) RegisterRoutes(server *gin.Engine) {
@@ -299,7 +833,30 @@ func (u *UserHandler) RegisterRoutes(server *gin.Engine) {
 
 	ug.POST("/signup", u.SignUp)
 	ug.POST("/login", u.LogInJWT)
+	ug.POST("/refresh_token", u.RefreshToken)
+	ug.POST("/login/mfa", u.LoginMFA)
+	ug.POST("/unlock", u.UnlockAccount)
+	ug.POST("/login_sms/code/send", u.SendLoginSMSCode)
+	ug.POST("/login_sms", u.LoginSMS)
+	ug.POST("/logout", u.LogoutJWT)
+	ug.POST("/password/forgot", u.ForgotPassword)
+	ug.POST("/password/reset", u.ResetPassword)
+	ug.POST("/email/verify/send", u.SendVerifyEmail)
+	ug.POST("/email/verify", u.VerifyEmail)
+	ug.POST("/email/change", u.ChangeEmail)
+	ug.POST("/mfa/enroll", u.EnrollMFA)
+	ug.POST("/mfa/confirm", u.ConfirmMFA)
+
+	// 登录设备管理
+	ug.GET("/sessions", u.Sessions)
+	ug.POST("/sessions/revoke", u.RevokeSession)
+	ug.POST("/sessions/revoke_others", u.RevokeOtherSessions)
 	ug.POST("/edit", u.Edit)
 	ug.GET("/profile", u.ProfileJWT)
 
+	// 个人数据导出和注销
+	ug.GET("/export", u.Export)
+	ug.POST("/delete", u.DeleteAccount)
+	ug.POST("/delete/cancel", u.CancelDeletion)
+
 }
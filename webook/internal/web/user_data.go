@@ -0,0 +1,158 @@
+package web
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/ecodeclub/ekit/slice"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/service"
+	ijwt "webook/pkg/ginx/jwt"
+)
+
+// Export 把我们保存的这个用户的全部数据打成一个 zip 包下载
+func (uh *UserHandler) Export(ctx *gin.Context) {
+	uc := ctx.MustGet("users").(*ijwt.UserClaims)
+	data, err := uh.dataSvc.Export(ctx, uc.Id)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		return
+	}
+	archive, err := uh.exportArchive(data)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		return
+	}
+	filename := fmt.Sprintf("webook-export-%d-%s.zip", uc.Id, time.Now().Format("20060102"))
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	ctx.Data(http.StatusOK, "application/zip", archive)
+}
+
+// DeleteAccount 申请注销，冷静期过了之后才真正清除数据
+func (uh *UserHandler) DeleteAccount(ctx *gin.Context) {
+	uc := ctx.MustGet("users").(*ijwt.UserClaims)
+	deleteAfter, err := uh.dataSvc.RequestDeletion(ctx, uc.Id)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		return
+	}
+	// 其它设备全部踢下线，这个设备还要留着撤销注销
+	err = uh.Handler.RevokeOtherSessions(ctx, uc.Id, uc.Ssid)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "OK",
+		Data: DeletionVO{
+			DeleteAfter: deleteAfter.Format(time.DateTime),
+		},
+	})
+}
+
+// CancelDeletion 冷静期内撤销注销
+func (uh *UserHandler) CancelDeletion(ctx *gin.Context) {
+	uc := ctx.MustGet("users").(*ijwt.UserClaims)
+	err := uh.dataSvc.CancelDeletion(ctx, uc.Id)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "OK",
+		})
+	case service.ErrDeletionNotRequested:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Account deletion not requested",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+	}
+}
+
+func (uh *UserHandler) exportArchive(data domain.UserDataExport) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := []struct {
+		name string
+		val  any
+	}{
+		{name: "profile.json", val: uh.exportProfile(data.Profile)},
+		{name: "articles.json", val: slice.Map(data.Articles, uh.exportArticle)},
+		{name: "published_articles.json", val: slice.Map(data.PublishedArticles, uh.exportArticle)},
+		{name: "likes.json", val: slice.Map(data.Likes, uh.exportUserBiz)},
+		{name: "collections.json", val: slice.Map(data.Collections, uh.exportUserBiz)},
+	}
+	for _, f := range files {
+		w, err := zw.Create(f.name)
+		if err != nil {
+			return nil, err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(f.val)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err := zw.Close()
+	return buf.Bytes(), err
+}
+
+func (uh *UserHandler) exportProfile(u domain.User) ExportProfileVO {
+	res := ExportProfileVO{
+		Id:            u.Id,
+		Email:         u.Email,
+		EmailVerified: u.EmailVerified,
+		Phone:         u.Phone,
+		WechatOpenId:  u.WechatInfo.OpenId,
+		Nickname:      u.Nickname,
+		AboutMe:       u.AboutMe,
+		Ctime:         u.Ctime.Format(time.DateTime),
+	}
+	if !u.Birthday.IsZero() {
+		res.Birthday = u.Birthday.Format(time.DateOnly)
+	}
+	if !u.DeleteAfter.IsZero() {
+		res.DeleteAfter = u.DeleteAfter.Format(time.DateTime)
+	}
+	return res
+}
+
+func (uh *UserHandler) exportArticle(idx int, src domain.Article) ExportArticleVO {
+	return ExportArticleVO{
+		Id:      src.Id,
+		Title:   src.Title,
+		Content: src.Content,
+		Status:  src.Status.String(),
+		Ctime:   src.Ctime.Format(time.DateTime),
+		Utime:   src.Utime.Format(time.DateTime),
+	}
+}
+
+func (uh *UserHandler) exportUserBiz(idx int, src domain.UserBiz) ExportUserBizVO {
+	return ExportUserBizVO{
+		Biz:   src.Biz,
+		BizId: src.BizId,
+		Cid:   src.Cid,
+		Ctime: src.Ctime.Format(time.DateTime),
+	}
+}
@@ -0,0 +1,116 @@
+package web
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"webook/internal/domain"
+	svcmock "webook/internal/service/mocks"
+	ijwt "webook/pkg/ginx/jwt"
+	jwtmocks "webook/pkg/ginx/jwt/mocks"
+)
+
+func TestUserHandler_Export(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	dataSvc := svcmock.NewMockUserDataService(ctrl)
+	dataSvc.EXPECT().Export(gomock.Any(), int64(123)).Return(domain.UserDataExport{
+		Profile: domain.User{
+			Id:       123,
+			Email:    "123@qq.com",
+			Password: "hashed",
+			Nickname: "Tom",
+		},
+		Articles: []domain.Article{
+			{Id: 1, Title: "draft", Content: "hello", Status: domain.ArticleStatusUnpublished},
+		},
+		Likes: []domain.UserBiz{
+			{Biz: "article", BizId: 2, Ctime: time.Now()},
+		},
+	}, nil)
+	server := gin.Default()
+	server.Use(func(ctx *gin.Context) {
+		ctx.Set("users", &ijwt.UserClaims{Id: 123})
+	})
+	h := newTestUserHandler(ctrl, userHandlerDeps{dataSvc: dataSvc})
+	h.RegisterRoutes(server)
+	req, err := http.NewRequest(http.MethodGet, "/users/export", nil)
+	require.NoError(t, err)
+	resp := httptest.NewRecorder()
+
+	server.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "application/zip", resp.Header().Get("Content-Type"))
+
+	zr, err := zip.NewReader(bytes.NewReader(resp.Body.Bytes()), int64(resp.Body.Len()))
+	require.NoError(t, err)
+	files := map[string][]byte{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		files[f.Name], err = io.ReadAll(rc)
+		require.NoError(t, err)
+		_ = rc.Close()
+	}
+	assert.Len(t, files, 5)
+
+	// 密码不能导出去
+	assert.NotContains(t, string(files["profile.json"]), "hashed")
+	var profile ExportProfileVO
+	require.NoError(t, json.Unmarshal(files["profile.json"], &profile))
+	assert.Equal(t, int64(123), profile.Id)
+	assert.Equal(t, "Tom", profile.Nickname)
+
+	var arts []ExportArticleVO
+	require.NoError(t, json.Unmarshal(files["articles.json"], &arts))
+	require.Len(t, arts, 1)
+	assert.Equal(t, "Unpublished", arts[0].Status)
+
+	var pubs []ExportArticleVO
+	require.NoError(t, json.Unmarshal(files["published_articles.json"], &pubs))
+	assert.Len(t, pubs, 0)
+
+	var likes []ExportUserBizVO
+	require.NoError(t, json.Unmarshal(files["likes.json"], &likes))
+	require.Len(t, likes, 1)
+	assert.Equal(t, int64(2), likes[0].BizId)
+}
+
+func TestUserHandler_DeleteAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	deleteAfter := time.Date(2030, 1, 2, 3, 4, 5, 0, time.Local)
+	dataSvc := svcmock.NewMockUserDataService(ctrl)
+	dataSvc.EXPECT().RequestDeletion(gomock.Any(), int64(123)).Return(deleteAfter, nil)
+	// 当前设备要留着，不然没法撤销
+	jwtHdl := jwtmocks.NewMockHandler(ctrl)
+	jwtHdl.EXPECT().RevokeOtherSessions(gomock.Any(), int64(123), "ssid-1").Return(nil)
+	server := gin.Default()
+	server.Use(func(ctx *gin.Context) {
+		ctx.Set("users", &ijwt.UserClaims{Id: 123, Ssid: "ssid-1"})
+	})
+	h := newTestUserHandler(ctrl, userHandlerDeps{dataSvc: dataSvc, jwtHdl: jwtHdl})
+	h.RegisterRoutes(server)
+	req, err := http.NewRequest(http.MethodPost, "/users/delete", nil)
+	require.NoError(t, err)
+	resp := httptest.NewRecorder()
+
+	server.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var res Result
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	require.NoError(t, err)
+	assert.Equal(t, Result{
+		Msg:  "OK",
+		Data: map[string]any{"deleteAfter": "2030-01-02 03:04:05"},
+	}, res)
+}
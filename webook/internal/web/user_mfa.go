@@ -0,0 +1,204 @@
+package web
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"net/http"
+	"time"
+	"webook/internal/domain"
+	"webook/internal/service"
+	ijwt "webook/pkg/ginx/jwt"
+)
+
+const (
+	mfaTokenHeader = "x-mfa-token"
+	// 输完密码之后要在这个时间内输入验证码
+	mfaTokenExpiration = time.Minute * 5
+)
+
+var mfaKey = []byte("Zt8Rw3Yp6Lk1Qv9Nm4Xs7Hb2Jc5Fd0Ge")
+
+// MFAClaims 密码已经验证过，还差两步验证的"半登录"状态
+type MFAClaims struct {
+	Uid       int64
+	UserAgent string
+	// 第二步通过之后原样放进登录态
+	Roles []string
+	jwt.RegisteredClaims
+}
+
+// EnrollMFA 生成新的 TOTP 密钥，确认之前不生效
+func (uh *UserHandler) EnrollMFA(ctx *gin.Context) {
+	uc := ctx.MustGet("users").(*ijwt.UserClaims)
+	u, err := uh.svc.Profile(ctx, uc.Id)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		return
+	}
+	// 验证器里面显示的账号名
+	account := u.Email
+	if account == "" {
+		account = u.Phone
+	}
+	if account == "" {
+		account = fmt.Sprintf("user-%d", u.Id)
+	}
+	secret, uri, err := uh.mfaSvc.Enroll(ctx, uc.Id, account)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Data: MFAEnrollVO{
+				Secret: secret,
+				URI:    uri,
+			},
+		})
+	case service.ErrMFAAlreadyEnabled:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Two-factor authentication already enabled",
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+	}
+}
+
+// ConfirmMFA 用验证器上的验证码确认绑定，返回恢复码
+func (uh *UserHandler) ConfirmMFA(ctx *gin.Context) {
+	type Req struct {
+		Code string `json:"code"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	uc := ctx.MustGet("users").(*ijwt.UserClaims)
+	codes, err := uh.mfaSvc.Confirm(ctx, uc.Id, req.Code)
+	switch err {
+	case nil:
+		ctx.JSON(http.StatusOK, Result{
+			Data: MFAConfirmVO{
+				RecoveryCodes: codes,
+			},
+		})
+	case service.ErrInvalidMFACode:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Invalid code",
+		})
+	case service.ErrMFANotEnrolled, service.ErrMFAAlreadyEnabled:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  err.Error(),
+		})
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+	}
+}
+
+// LoginMFA 登录的第二步，验证码通过之后才发长短 token
+func (uh *UserHandler) LoginMFA(ctx *gin.Context) {
+	type Req struct {
+		Token string `json:"token"`
+		Code  string `json:"code"`
+	}
+	var req Req
+	if err := ctx.Bind(&req); err != nil {
+		return
+	}
+	var mc MFAClaims
+	token, err := jwt.ParseWithClaims(req.Token, &mc, func(token *jwt.Token) (interface{}, error) {
+		return mfaKey, nil
+	})
+	if err != nil || !token.Valid || mc.Uid == 0 || mc.ID == "" || mc.UserAgent != ctx.Request.UserAgent() {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Login expired, please sign in again",
+		})
+		return
+	}
+	err = uh.mfaSvc.Verify(ctx, mc.Uid, mc.ID, req.Code)
+	switch err {
+	case nil:
+	case service.ErrInvalidMFACode:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Invalid code",
+		})
+		return
+	case service.ErrMFATooManyAttempts:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Too many attempts, please sign in again later",
+		})
+		return
+	case service.ErrMFATokenInvalid:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Login expired, please sign in again",
+		})
+		return
+	default:
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		return
+	}
+	err = uh.SetLoginToken(ctx, mc.Uid, mc.Roles)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "Sign in successful",
+	})
+}
+
+// loginOrMFA 所有登录方式最后都走这里，开了两步验证的只发第二步的令牌，返回是否还要第二步
+func loginOrMFA(ctx *gin.Context, mfaSvc service.MFAService, jwtHdl ijwt.Handler, u domain.User) (bool, error) {
+	enabled, err := mfaSvc.Enabled(ctx, u.Id)
+	if err != nil {
+		return false, err
+	}
+	if enabled {
+		return true, setMFAToken(ctx, mfaSvc, u)
+	}
+	// 长短 token，ssid 用来标识这一次登录
+	return false, jwtHdl.SetLoginToken(ctx, u.Id, roleNames(u))
+}
+
+func setMFAToken(ctx *gin.Context, mfaSvc service.MFAService, u domain.User) error {
+	// 令牌的 id 记在服务端，第二步通过之后就作废
+	tokenId, err := mfaSvc.StartLogin(ctx, u.Id, mfaTokenExpiration)
+	if err != nil {
+		return err
+	}
+	claims := MFAClaims{
+		Uid:       u.Id,
+		UserAgent: ctx.Request.UserAgent(),
+		Roles:     roleNames(u),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenId,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(mfaTokenExpiration)),
+		},
+	}
+	tokenStr, err := jwt.NewWithClaims(jwt.SigningMethodHS512, claims).SignedString(mfaKey)
+	if err != nil {
+		return err
+	}
+	ctx.Header(mfaTokenHeader, tokenStr)
+	return nil
+}
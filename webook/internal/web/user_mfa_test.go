@@ -0,0 +1,132 @@
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"webook/internal/domain"
+	"webook/internal/service"
+	svcmock "webook/internal/service/mocks"
+	ijwt "webook/pkg/ginx/jwt"
+	jwtmocks "webook/pkg/ginx/jwt/mocks"
+)
+
+func TestUserHandler_LoginMFA(t *testing.T) {
+	const userAgent = "test-agent"
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (service.MFAService, ijwt.Handler)
+		code string
+		// 第二步换了一个浏览器
+		userAgent string
+		wantBody  Result
+	}{
+		{
+			name: "log in success",
+			mock: func(ctrl *gomock.Controller) (service.MFAService, ijwt.Handler) {
+				mfaSvc := svcmock.NewMockMFAService(ctrl)
+				mfaSvc.EXPECT().Verify(gomock.Any(), int64(123), "token_id", "123456").Return(nil)
+				jwtHdl := jwtmocks.NewMockHandler(ctrl)
+				jwtHdl.EXPECT().SetLoginToken(gomock.Any(), int64(123), gomock.Any()).Return(nil)
+				return mfaSvc, jwtHdl
+			},
+			code:      "123456",
+			userAgent: userAgent,
+			wantBody:  Result{Msg: "Sign in successful"},
+		},
+		{
+			name: "invalid code",
+			mock: func(ctrl *gomock.Controller) (service.MFAService, ijwt.Handler) {
+				mfaSvc := svcmock.NewMockMFAService(ctrl)
+				mfaSvc.EXPECT().Verify(gomock.Any(), int64(123), "token_id", "654321").
+					Return(service.ErrInvalidMFACode)
+				return mfaSvc, jwtmocks.NewMockHandler(ctrl)
+			},
+			code:      "654321",
+			userAgent: userAgent,
+			wantBody:  Result{Code: 4, Msg: "Invalid code"},
+		},
+		{
+			name: "too many attempts",
+			mock: func(ctrl *gomock.Controller) (service.MFAService, ijwt.Handler) {
+				mfaSvc := svcmock.NewMockMFAService(ctrl)
+				mfaSvc.EXPECT().Verify(gomock.Any(), int64(123), "token_id", "654321").
+					Return(service.ErrMFATooManyAttempts)
+				return mfaSvc, jwtmocks.NewMockHandler(ctrl)
+			},
+			code:      "654321",
+			userAgent: userAgent,
+			wantBody:  Result{Code: 4, Msg: "Too many attempts, please sign in again later"},
+		},
+		{
+			name: "token already used",
+			mock: func(ctrl *gomock.Controller) (service.MFAService, ijwt.Handler) {
+				mfaSvc := svcmock.NewMockMFAService(ctrl)
+				mfaSvc.EXPECT().Verify(gomock.Any(), int64(123), "token_id", "123456").
+					Return(service.ErrMFATokenInvalid)
+				return mfaSvc, jwtmocks.NewMockHandler(ctrl)
+			},
+			code:      "123456",
+			userAgent: userAgent,
+			wantBody:  Result{Code: 4, Msg: "Login expired, please sign in again"},
+		},
+		{
+			name: "user agent changed",
+			mock: func(ctrl *gomock.Controller) (service.MFAService, ijwt.Handler) {
+				return svcmock.NewMockMFAService(ctrl), jwtmocks.NewMockHandler(ctrl)
+			},
+			code:      "123456",
+			userAgent: "another-agent",
+			wantBody:  Result{Code: 4, Msg: "Login expired, please sign in again"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			mfaSvc, jwtHdl := tc.mock(ctrl)
+
+			// 先走第一步，拿到 mfa token
+			usersvc := svcmock.NewMockUserService(ctrl)
+			usersvc.EXPECT().LogIn(gomock.Any(), gomock.Any(), gomock.Any()).Return(domain.User{Id: 123}, nil)
+			step1 := svcmock.NewMockMFAService(ctrl)
+			step1.EXPECT().Enabled(gomock.Any(), int64(123)).Return(true, nil)
+			step1.EXPECT().StartLogin(gomock.Any(), int64(123), gomock.Any()).Return("token_id", nil)
+			server := gin.Default()
+			newTestUserHandler(ctrl, userHandlerDeps{svc: usersvc, mfaSvc: step1}).RegisterRoutes(server)
+			req, err := http.NewRequest(http.MethodPost, "/users/login",
+				bytes.NewBuffer([]byte(`{"email":"123@gmail.com","password":"123hello123"}`)))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("User-Agent", userAgent)
+			resp := httptest.NewRecorder()
+			server.ServeHTTP(resp, req)
+			mfaToken := resp.Header().Get("x-mfa-token")
+			require.NotEmpty(t, mfaToken)
+
+			server = gin.Default()
+			newTestUserHandler(ctrl, userHandlerDeps{mfaSvc: mfaSvc, jwtHdl: jwtHdl}).RegisterRoutes(server)
+			body, err := json.Marshal(map[string]string{"token": mfaToken, "code": tc.code})
+			require.NoError(t, err)
+			req, err = http.NewRequest(http.MethodPost, "/users/login/mfa", bytes.NewBuffer(body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("User-Agent", tc.userAgent)
+			resp = httptest.NewRecorder()
+			server.ServeHTTP(resp, req)
+
+			assert.Equal(t, http.StatusOK, resp.Code)
+			var res Result
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantBody, res)
+		})
+	}
+}
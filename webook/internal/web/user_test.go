@@ -2,36 +2,81 @@ package web
 
 import (
 	"bytes"
+	"encoding/json"
 	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	gomock "go.uber.org/mock/gomock"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 	"webook/internal/domain"
 	"webook/internal/service"
 	svcmock "webook/internal/service/mocks"
+	ijwt "webook/pkg/ginx/jwt"
+	jwtmocks "webook/pkg/ginx/jwt/mocks"
 )
 
+// userHandlerDeps 没有指定的依赖都用一个没有任何预期的 mock
+type userHandlerDeps struct {
+	svc       service.UserService
+	codeSvc   service.CodeService
+	resetSvc  service.PasswordResetService
+	verifySvc service.EmailVerifyService
+	mfaSvc    service.MFAService
+	dataSvc   service.UserDataService
+	jwtHdl    ijwt.Handler
+}
+
+func newTestUserHandler(ctrl *gomock.Controller, deps userHandlerDeps) *UserHandler {
+	if deps.svc == nil {
+		deps.svc = svcmock.NewMockUserService(ctrl)
+	}
+	if deps.codeSvc == nil {
+		deps.codeSvc = svcmock.NewMockCodeService(ctrl)
+	}
+	if deps.resetSvc == nil {
+		deps.resetSvc = svcmock.NewMockPasswordResetService(ctrl)
+	}
+	if deps.verifySvc == nil {
+		deps.verifySvc = svcmock.NewMockEmailVerifyService(ctrl)
+	}
+	if deps.mfaSvc == nil {
+		deps.mfaSvc = svcmock.NewMockMFAService(ctrl)
+	}
+	if deps.dataSvc == nil {
+		deps.dataSvc = svcmock.NewMockUserDataService(ctrl)
+	}
+	if deps.jwtHdl == nil {
+		deps.jwtHdl = jwtmocks.NewMockHandler(ctrl)
+	}
+	return NewUserHandler(deps.svc, deps.codeSvc, deps.resetSvc, deps.verifySvc, deps.mfaSvc,
+		deps.dataSvc, deps.jwtHdl)
+}
+
 func TestUserHandler_SignUp(t *testing.T) {
 	testCases := []struct {
 		name     string
-		mock     func(ctrl *gomock.Controller) service.UserService
+		mock     func(ctrl *gomock.Controller) (service.UserService, service.EmailVerifyService)
 		reqBody  string
 		wantCode int
 		wantBody string
 	}{
 		{
 			name: "sign up success",
-			mock: func(ctrl *gomock.Controller) service.UserService {
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.EmailVerifyService) {
 				usersvc := svcmock.NewMockUserService(ctrl)
 				usersvc.EXPECT().SignUp(gomock.Any(), domain.User{
 					Email:    "123@gmail.com",
 					Password: "123hello123",
 				}).Return(nil)
-				return usersvc
+				verifySvc := svcmock.NewMockEmailVerifyService(ctrl)
+				verifySvc.EXPECT().SendVerifyEmail(gomock.Any(), "123@gmail.com").Return(nil)
+				return usersvc, verifySvc
 			},
 			reqBody: `{
 				"email":"123@gmail.com",
@@ -43,13 +88,13 @@ func TestUserHandler_SignUp(t *testing.T) {
 		},
 		{
 			name: "bind fail",
-			mock: func(ctrl *gomock.Controller) service.UserService {
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.EmailVerifyService) {
 				usersvc := svcmock.NewMockUserService(ctrl)
 				//usersvc.EXPECT().SignUp(gomock.Any(), domain.User{
 				//	Email:    "123@gmail.com",
 				//	Password: "123hello123",
 				//}).Return(nil)
-				return usersvc
+				return usersvc, svcmock.NewMockEmailVerifyService(ctrl)
 			},
 			reqBody: `{
 				"email":"123@gmail.com",
@@ -59,13 +104,13 @@ func TestUserHandler_SignUp(t *testing.T) {
 		},
 		{
 			name: "Email format error",
-			mock: func(ctrl *gomock.Controller) service.UserService {
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.EmailVerifyService) {
 				usersvc := svcmock.NewMockUserService(ctrl)
 				//usersvc.EXPECT().SignUp(gomock.Any(), domain.User{
 				//	Email:    "123@gmail.com",
 				//	Password: "123hello123",
 				//}).Return(nil)
-				return usersvc
+				return usersvc, svcmock.NewMockEmailVerifyService(ctrl)
 			},
 			reqBody: `{
 				"email":"123gmail.com",
@@ -77,13 +122,13 @@ func TestUserHandler_SignUp(t *testing.T) {
 		},
 		{
 			name: "password confirm error",
-			mock: func(ctrl *gomock.Controller) service.UserService {
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.EmailVerifyService) {
 				usersvc := svcmock.NewMockUserService(ctrl)
 				//usersvc.EXPECT().SignUp(gomock.Any(), domain.User{
 				//	Email:    "123@gmail.com",
 				//	Password: "123hello123",
 				//}).Return(nil)
-				return usersvc
+				return usersvc, svcmock.NewMockEmailVerifyService(ctrl)
 			},
 			reqBody: `{
 				"email":"123@gmail.com",
@@ -95,13 +140,13 @@ func TestUserHandler_SignUp(t *testing.T) {
 		},
 		{
 			name: "password format error",
-			mock: func(ctrl *gomock.Controller) service.UserService {
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.EmailVerifyService) {
 				usersvc := svcmock.NewMockUserService(ctrl)
 				//usersvc.EXPECT().SignUp(gomock.Any(), domain.User{
 				//	Email:    "123@gmail.com",
 				//	Password: "123hello123",
 				//}).Return(nil)
-				return usersvc
+				return usersvc, svcmock.NewMockEmailVerifyService(ctrl)
 			},
 			reqBody: `{
 				"email":"123@gmail.com",
@@ -113,13 +158,13 @@ func TestUserHandler_SignUp(t *testing.T) {
 		},
 		{
 			name: "email exist",
-			mock: func(ctrl *gomock.Controller) service.UserService {
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.EmailVerifyService) {
 				usersvc := svcmock.NewMockUserService(ctrl)
 				usersvc.EXPECT().SignUp(gomock.Any(), domain.User{
 					Email:    "123@gmail.com",
 					Password: "123hello123",
 				}).Return(service.ErrUserDuplicatedEmail)
-				return usersvc
+				return usersvc, svcmock.NewMockEmailVerifyService(ctrl)
 			},
 			reqBody: `{
 				"email":"123@gmail.com",
@@ -131,13 +176,13 @@ func TestUserHandler_SignUp(t *testing.T) {
 		},
 		{
 			name: "other error",
-			mock: func(ctrl *gomock.Controller) service.UserService {
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.EmailVerifyService) {
 				usersvc := svcmock.NewMockUserService(ctrl)
 				usersvc.EXPECT().SignUp(gomock.Any(), domain.User{
 					Email:    "123@gmail.com",
 					Password: "123hello123",
 				}).Return(errors.New("other error"))
-				return usersvc
+				return usersvc, svcmock.NewMockEmailVerifyService(ctrl)
 			},
 			reqBody: `{
 				"email":"123@gmail.com",
@@ -155,7 +200,8 @@ func TestUserHandler_SignUp(t *testing.T) {
 			ctrl := gomock.NewController(t)
 			defer ctrl.Finish()
 			server := gin.Default()
-			h := NewUserHandler(tc.mock(ctrl))
+			usersvc, verifySvc := tc.mock(ctrl)
+			h := newTestUserHandler(ctrl, userHandlerDeps{svc: usersvc, verifySvc: verifySvc})
 			h.RegisterRoutes(server)
 			req, err := http.NewRequest(http.MethodPost,
 				"/users/signup", bytes.NewBuffer([]byte(tc.reqBody)))
@@ -173,6 +219,692 @@ func TestUserHandler_SignUp(t *testing.T) {
 	}
 }
 
+func TestUserHandler_LogInJWT(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) (service.UserService, service.MFAService, ijwt.Handler)
+		reqBody  string
+		wantCode int
+		wantBody string
+	}{
+		{
+			name: "log in success",
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.MFAService, ijwt.Handler) {
+				usersvc := svcmock.NewMockUserService(ctrl)
+				usersvc.EXPECT().LogIn(gomock.Any(), domain.User{
+					Email:    "123@gmail.com",
+					Password: "123hello123",
+				}, gomock.Any()).Return(domain.User{Id: 123}, nil)
+				mfaSvc := svcmock.NewMockMFAService(ctrl)
+				mfaSvc.EXPECT().Enabled(gomock.Any(), int64(123)).Return(false, nil)
+				jwtHdl := jwtmocks.NewMockHandler(ctrl)
+				jwtHdl.EXPECT().SetLoginToken(gomock.Any(), int64(123), gomock.Any()).Return(nil)
+				return usersvc, mfaSvc, jwtHdl
+			},
+			reqBody: `{
+				"email":"123@gmail.com",
+				"password":"123hello123"
+			}`,
+			wantCode: http.StatusOK,
+			wantBody: "Sign in successful",
+		},
+		{
+			name: "invalid password",
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.MFAService, ijwt.Handler) {
+				usersvc := svcmock.NewMockUserService(ctrl)
+				usersvc.EXPECT().LogIn(gomock.Any(), domain.User{
+					Email:    "123@gmail.com",
+					Password: "123hello123",
+				}, gomock.Any()).Return(domain.User{}, service.ErrInvalidUserOrPassword)
+				return usersvc, svcmock.NewMockMFAService(ctrl), jwtmocks.NewMockHandler(ctrl)
+			},
+			reqBody: `{
+				"email":"123@gmail.com",
+				"password":"123hello123"
+			}`,
+			wantCode: http.StatusOK,
+			wantBody: "Invalid email or password",
+		},
+		{
+			name: "set token fail",
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.MFAService, ijwt.Handler) {
+				usersvc := svcmock.NewMockUserService(ctrl)
+				usersvc.EXPECT().LogIn(gomock.Any(), domain.User{
+					Email:    "123@gmail.com",
+					Password: "123hello123",
+				}, gomock.Any()).Return(domain.User{Id: 123}, nil)
+				mfaSvc := svcmock.NewMockMFAService(ctrl)
+				mfaSvc.EXPECT().Enabled(gomock.Any(), int64(123)).Return(false, nil)
+				jwtHdl := jwtmocks.NewMockHandler(ctrl)
+				jwtHdl.EXPECT().SetLoginToken(gomock.Any(), int64(123), gomock.Any()).
+					Return(errors.New("mock redis error"))
+				return usersvc, mfaSvc, jwtHdl
+			},
+			reqBody: `{
+				"email":"123@gmail.com",
+				"password":"123hello123"
+			}`,
+			wantCode: http.StatusOK,
+			wantBody: "System error",
+		},
+		{
+			name: "mfa required",
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.MFAService, ijwt.Handler) {
+				usersvc := svcmock.NewMockUserService(ctrl)
+				usersvc.EXPECT().LogIn(gomock.Any(), domain.User{
+					Email:    "123@gmail.com",
+					Password: "123hello123",
+				}, gomock.Any()).Return(domain.User{Id: 123}, nil)
+				mfaSvc := svcmock.NewMockMFAService(ctrl)
+				mfaSvc.EXPECT().Enabled(gomock.Any(), int64(123)).Return(true, nil)
+				mfaSvc.EXPECT().StartLogin(gomock.Any(), int64(123), gomock.Any()).Return("token_id", nil)
+				// 不能直接发登录态
+				return usersvc, mfaSvc, jwtmocks.NewMockHandler(ctrl)
+			},
+			reqBody: `{
+				"email":"123@gmail.com",
+				"password":"123hello123"
+			}`,
+			wantCode: http.StatusOK,
+			wantBody: "MFA required",
+		},
+		{
+			name: "account locked",
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.MFAService, ijwt.Handler) {
+				usersvc := svcmock.NewMockUserService(ctrl)
+				usersvc.EXPECT().LogIn(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(domain.User{}, service.ErrAccountLocked)
+				return usersvc, svcmock.NewMockMFAService(ctrl), jwtmocks.NewMockHandler(ctrl)
+			},
+			reqBody: `{
+				"email":"123@gmail.com",
+				"password":"123hello123"
+			}`,
+			wantCode: http.StatusOK,
+			wantBody: "Account temporarily locked, check your email to unlock",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := gin.Default()
+			usersvc, mfaSvc, jwtHdl := tc.mock(ctrl)
+			h := newTestUserHandler(ctrl, userHandlerDeps{svc: usersvc, mfaSvc: mfaSvc, jwtHdl: jwtHdl})
+			h.RegisterRoutes(server)
+			req, err := http.NewRequest(http.MethodPost,
+				"/users/login", bytes.NewBuffer([]byte(tc.reqBody)))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+
+			server.ServeHTTP(resp, req)
+			assert.Equal(t, tc.wantCode, resp.Code)
+			assert.Equal(t, tc.wantBody, resp.Body.String())
+		})
+	}
+}
+
+func TestUserHandler_RefreshToken(t *testing.T) {
+	refreshToken := func(key []byte, exp time.Duration) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS512, ijwt.RefreshClaims{
+			Uid:  123,
+			Ssid: "ssid-1",
+			RegisteredClaims: jwt.RegisteredClaims{
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(exp)),
+			},
+		})
+		str, err := token.SignedString(key)
+		require.NoError(t, err)
+		return str
+	}
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) (service.UserService, ijwt.Handler)
+		token    string
+		wantCode int
+	}{
+		{
+			name: "refresh success",
+			mock: func(ctrl *gomock.Controller) (service.UserService, ijwt.Handler) {
+				jwtHdl := jwtmocks.NewMockHandler(ctrl)
+				jwtHdl.EXPECT().ExtractToken(gomock.Any()).
+					Return(refreshToken(ijwt.RtKey, time.Hour))
+				jwtHdl.EXPECT().CheckSession(gomock.Any(), int64(123), "ssid-1").Return(nil)
+				// 角色要重新查，不能沿用旧的
+				usersvc := svcmock.NewMockUserService(ctrl)
+				usersvc.EXPECT().Profile(gomock.Any(), int64(123)).
+					Return(domain.User{Id: 123, Roles: []domain.Role{domain.RoleReader, domain.RoleAdmin}}, nil)
+				jwtHdl.EXPECT().SetJWTToken(gomock.Any(), int64(123), "ssid-1",
+					[]string{"reader", "admin"}).Return(nil)
+				return usersvc, jwtHdl
+			},
+			wantCode: http.StatusOK,
+		},
+		{
+			name: "signed with access key",
+			mock: func(ctrl *gomock.Controller) (service.UserService, ijwt.Handler) {
+				jwtHdl := jwtmocks.NewMockHandler(ctrl)
+				jwtHdl.EXPECT().ExtractToken(gomock.Any()).
+					Return(refreshToken(ijwt.AtKey, time.Hour))
+				return svcmock.NewMockUserService(ctrl), jwtHdl
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name: "expired",
+			mock: func(ctrl *gomock.Controller) (service.UserService, ijwt.Handler) {
+				jwtHdl := jwtmocks.NewMockHandler(ctrl)
+				jwtHdl.EXPECT().ExtractToken(gomock.Any()).
+					Return(refreshToken(ijwt.RtKey, -time.Hour))
+				return svcmock.NewMockUserService(ctrl), jwtHdl
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name: "session revoked",
+			mock: func(ctrl *gomock.Controller) (service.UserService, ijwt.Handler) {
+				jwtHdl := jwtmocks.NewMockHandler(ctrl)
+				jwtHdl.EXPECT().ExtractToken(gomock.Any()).
+					Return(refreshToken(ijwt.RtKey, time.Hour))
+				jwtHdl.EXPECT().CheckSession(gomock.Any(), int64(123), "ssid-1").
+					Return(errors.New("session 已经无效了"))
+				return svcmock.NewMockUserService(ctrl), jwtHdl
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name: "user purged",
+			mock: func(ctrl *gomock.Controller) (service.UserService, ijwt.Handler) {
+				jwtHdl := jwtmocks.NewMockHandler(ctrl)
+				jwtHdl.EXPECT().ExtractToken(gomock.Any()).
+					Return(refreshToken(ijwt.RtKey, time.Hour))
+				jwtHdl.EXPECT().CheckSession(gomock.Any(), int64(123), "ssid-1").Return(nil)
+				// 注销清除之后不能再换新的短 token
+				usersvc := svcmock.NewMockUserService(ctrl)
+				usersvc.EXPECT().Profile(gomock.Any(), int64(123)).
+					Return(domain.User{Id: 123, Purged: true}, nil)
+				return usersvc, jwtHdl
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := gin.Default()
+			usersvc, jwtHdl := tc.mock(ctrl)
+			h := newTestUserHandler(ctrl, userHandlerDeps{svc: usersvc, jwtHdl: jwtHdl})
+			h.RegisterRoutes(server)
+			req, err := http.NewRequest(http.MethodPost, "/users/refresh_token", nil)
+			require.NoError(t, err)
+			resp := httptest.NewRecorder()
+
+			server.ServeHTTP(resp, req)
+			assert.Equal(t, tc.wantCode, resp.Code)
+		})
+	}
+}
+
+func TestUserHandler_LogoutJWT(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) ijwt.Handler
+		wantCode int
+		wantBody Result
+	}{
+		{
+			name: "log out success",
+			mock: func(ctrl *gomock.Controller) ijwt.Handler {
+				jwtHdl := jwtmocks.NewMockHandler(ctrl)
+				jwtHdl.EXPECT().ClearToken(gomock.Any()).Return(nil)
+				return jwtHdl
+			},
+			wantCode: http.StatusOK,
+			wantBody: Result{Msg: "Sign out successful"},
+		},
+		{
+			name: "redis error",
+			mock: func(ctrl *gomock.Controller) ijwt.Handler {
+				jwtHdl := jwtmocks.NewMockHandler(ctrl)
+				jwtHdl.EXPECT().ClearToken(gomock.Any()).
+					Return(errors.New("mock redis error"))
+				return jwtHdl
+			},
+			wantCode: http.StatusOK,
+			wantBody: Result{Code: 5, Msg: "system error"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := gin.Default()
+			h := newTestUserHandler(ctrl, userHandlerDeps{jwtHdl: tc.mock(ctrl)})
+			h.RegisterRoutes(server)
+			req, err := http.NewRequest(http.MethodPost, "/users/logout", nil)
+			require.NoError(t, err)
+			resp := httptest.NewRecorder()
+
+			server.ServeHTTP(resp, req)
+			assert.Equal(t, tc.wantCode, resp.Code)
+			var res Result
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantBody, res)
+		})
+	}
+}
+
+func TestUserHandler_RevokeSession(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) ijwt.Handler
+		reqBody  string
+		wantBody Result
+	}{
+		{
+			name: "revoke success",
+			mock: func(ctrl *gomock.Controller) ijwt.Handler {
+				jwtHdl := jwtmocks.NewMockHandler(ctrl)
+				jwtHdl.EXPECT().Sessions(gomock.Any(), int64(123)).
+					Return([]ijwt.Session{{Ssid: "ssid-1"}, {Ssid: "ssid-2"}}, nil)
+				jwtHdl.EXPECT().RevokeSession(gomock.Any(), int64(123), "ssid-2").Return(nil)
+				return jwtHdl
+			},
+			reqBody:  `{"ssid":"ssid-2"}`,
+			wantBody: Result{Msg: "OK"},
+		},
+		{
+			name: "not my session",
+			mock: func(ctrl *gomock.Controller) ijwt.Handler {
+				jwtHdl := jwtmocks.NewMockHandler(ctrl)
+				jwtHdl.EXPECT().Sessions(gomock.Any(), int64(123)).
+					Return([]ijwt.Session{{Ssid: "ssid-1"}}, nil)
+				return jwtHdl
+			},
+			reqBody:  `{"ssid":"someone-else"}`,
+			wantBody: Result{Code: 4, Msg: "session not found"},
+		},
+		{
+			name: "empty ssid",
+			mock: func(ctrl *gomock.Controller) ijwt.Handler {
+				return jwtmocks.NewMockHandler(ctrl)
+			},
+			reqBody:  `{}`,
+			wantBody: Result{Code: 4, Msg: "ssid is required"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := gin.Default()
+			server.Use(func(ctx *gin.Context) {
+				ctx.Set("users", &ijwt.UserClaims{Id: 123, Ssid: "ssid-1"})
+			})
+			h := newTestUserHandler(ctrl, userHandlerDeps{jwtHdl: tc.mock(ctrl)})
+			h.RegisterRoutes(server)
+			req, err := http.NewRequest(http.MethodPost,
+				"/users/sessions/revoke", bytes.NewBuffer([]byte(tc.reqBody)))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+
+			server.ServeHTTP(resp, req)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			var res Result
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantBody, res)
+		})
+	}
+}
+
+func TestUserHandler_Edit(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) service.UserService
+		reqBody  string
+		wantBody Result
+	}{
+		{
+			name: "edit success",
+			mock: func(ctrl *gomock.Controller) service.UserService {
+				usersvc := svcmock.NewMockUserService(ctrl)
+				usersvc.EXPECT().UpdateNonSensitiveInfo(gomock.Any(), domain.User{
+					Id:       123,
+					Nickname: "Tom",
+					Birthday: time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC),
+					AboutMe:  "hello",
+				}).Return(nil)
+				return usersvc
+			},
+			reqBody:  `{"nickname":"Tom","birthday":"2000-01-02","aboutMe":"hello"}`,
+			wantBody: Result{Msg: "OK"},
+		},
+		{
+			name: "invalid birthday",
+			mock: func(ctrl *gomock.Controller) service.UserService {
+				return svcmock.NewMockUserService(ctrl)
+			},
+			reqBody:  `{"nickname":"Tom","birthday":"2000/01/02"}`,
+			wantBody: Result{Code: 4, Msg: "Invalid birthday"},
+		},
+		{
+			name: "nickname too long",
+			mock: func(ctrl *gomock.Controller) service.UserService {
+				return svcmock.NewMockUserService(ctrl)
+			},
+			reqBody:  `{"nickname":"` + strings.Repeat("a", 33) + `"}`,
+			wantBody: Result{Code: 4, Msg: "Nickname is too long"},
+		},
+		{
+			name: "system error",
+			mock: func(ctrl *gomock.Controller) service.UserService {
+				usersvc := svcmock.NewMockUserService(ctrl)
+				usersvc.EXPECT().UpdateNonSensitiveInfo(gomock.Any(), gomock.Any()).
+					Return(errors.New("db error"))
+				return usersvc
+			},
+			reqBody:  `{"nickname":"Tom"}`,
+			wantBody: Result{Code: 5, Msg: "system error"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := gin.Default()
+			server.Use(func(ctx *gin.Context) {
+				ctx.Set("users", &ijwt.UserClaims{Id: 123})
+			})
+			h := newTestUserHandler(ctrl, userHandlerDeps{svc: tc.mock(ctrl)})
+			h.RegisterRoutes(server)
+			req, err := http.NewRequest(http.MethodPost,
+				"/users/edit", bytes.NewBuffer([]byte(tc.reqBody)))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+
+			server.ServeHTTP(resp, req)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			var res Result
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantBody, res)
+		})
+	}
+}
+
+func TestUserHandler_ProfileJWT(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	usersvc := svcmock.NewMockUserService(ctrl)
+	usersvc.EXPECT().Profile(gomock.Any(), int64(123)).Return(domain.User{
+		Id:       123,
+		Email:    "123@qq.com",
+		Nickname: "Tom",
+		Birthday: time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC),
+		Roles:    []domain.Role{domain.RoleReader},
+	}, nil)
+	server := gin.Default()
+	server.Use(func(ctx *gin.Context) {
+		ctx.Set("users", &ijwt.UserClaims{Id: 123})
+	})
+	h := newTestUserHandler(ctrl, userHandlerDeps{svc: usersvc})
+	h.RegisterRoutes(server)
+	req, err := http.NewRequest(http.MethodGet, "/users/profile", nil)
+	require.NoError(t, err)
+	resp := httptest.NewRecorder()
+
+	server.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var res ProfileVO
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	require.NoError(t, err)
+	assert.Equal(t, ProfileVO{
+		Nickname: "Tom",
+		Email:    "123@qq.com",
+		Birthday: "2000-01-02",
+		Roles:    []string{"reader"},
+	}, res)
+}
+
+func TestUserHandler_ResetPassword(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) (service.PasswordResetService, ijwt.Handler)
+		reqBody  string
+		wantBody Result
+	}{
+		{
+			name: "reset success",
+			mock: func(ctrl *gomock.Controller) (service.PasswordResetService, ijwt.Handler) {
+				resetSvc := svcmock.NewMockPasswordResetService(ctrl)
+				resetSvc.EXPECT().Reset(gomock.Any(), "the_token", "hello#world123").
+					Return(int64(123), nil)
+				jwtHdl := jwtmocks.NewMockHandler(ctrl)
+				// 所有设备都要下线
+				jwtHdl.EXPECT().RevokeOtherSessions(gomock.Any(), int64(123), "").Return(nil)
+				return resetSvc, jwtHdl
+			},
+			reqBody:  `{"token":"the_token","password":"hello#world123","confirmPassword":"hello#world123"}`,
+			wantBody: Result{Msg: "OK"},
+		},
+		{
+			name: "invalid token",
+			mock: func(ctrl *gomock.Controller) (service.PasswordResetService, ijwt.Handler) {
+				resetSvc := svcmock.NewMockPasswordResetService(ctrl)
+				resetSvc.EXPECT().Reset(gomock.Any(), "the_token", "hello#world123").
+					Return(int64(0), service.ErrInvalidResetToken)
+				return resetSvc, jwtmocks.NewMockHandler(ctrl)
+			},
+			reqBody:  `{"token":"the_token","password":"hello#world123","confirmPassword":"hello#world123"}`,
+			wantBody: Result{Code: 4, Msg: "Reset link is invalid or expired"},
+		},
+		{
+			name: "weak password",
+			mock: func(ctrl *gomock.Controller) (service.PasswordResetService, ijwt.Handler) {
+				return svcmock.NewMockPasswordResetService(ctrl), jwtmocks.NewMockHandler(ctrl)
+			},
+			reqBody: `{"token":"the_token","password":"hello","confirmPassword":"hello"}`,
+			wantBody: Result{Code: 4,
+				Msg: "At least 8 characters in length, containing at least one letter and one number"},
+		},
+		{
+			name: "passwords mismatch",
+			mock: func(ctrl *gomock.Controller) (service.PasswordResetService, ijwt.Handler) {
+				return svcmock.NewMockPasswordResetService(ctrl), jwtmocks.NewMockHandler(ctrl)
+			},
+			reqBody:  `{"token":"the_token","password":"hello#world123","confirmPassword":"hello#world1234"}`,
+			wantBody: Result{Code: 4, Msg: "Password error"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := gin.Default()
+			resetSvc, jwtHdl := tc.mock(ctrl)
+			h := newTestUserHandler(ctrl, userHandlerDeps{resetSvc: resetSvc, jwtHdl: jwtHdl})
+			h.RegisterRoutes(server)
+			req, err := http.NewRequest(http.MethodPost,
+				"/users/password/reset", bytes.NewBuffer([]byte(tc.reqBody)))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+
+			server.ServeHTTP(resp, req)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			var res Result
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantBody, res)
+		})
+	}
+}
+
+func TestUserHandler_VerifyEmail(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) service.EmailVerifyService
+		reqBody  string
+		wantBody Result
+	}{
+		{
+			name: "verify success",
+			mock: func(ctrl *gomock.Controller) service.EmailVerifyService {
+				verifySvc := svcmock.NewMockEmailVerifyService(ctrl)
+				verifySvc.EXPECT().Verify(gomock.Any(), "the_token").Return(nil)
+				return verifySvc
+			},
+			reqBody:  `{"token":"the_token"}`,
+			wantBody: Result{Msg: "OK"},
+		},
+		{
+			name: "invalid token",
+			mock: func(ctrl *gomock.Controller) service.EmailVerifyService {
+				verifySvc := svcmock.NewMockEmailVerifyService(ctrl)
+				verifySvc.EXPECT().Verify(gomock.Any(), "the_token").Return(service.ErrInvalidVerifyToken)
+				return verifySvc
+			},
+			reqBody:  `{"token":"the_token"}`,
+			wantBody: Result{Code: 4, Msg: "Verification link is invalid or expired"},
+		},
+		{
+			name: "email taken meanwhile",
+			mock: func(ctrl *gomock.Controller) service.EmailVerifyService {
+				verifySvc := svcmock.NewMockEmailVerifyService(ctrl)
+				verifySvc.EXPECT().Verify(gomock.Any(), "the_token").Return(service.ErrUserDuplicatedEmail)
+				return verifySvc
+			},
+			reqBody:  `{"token":"the_token"}`,
+			wantBody: Result{Code: 4, Msg: "Email already exists"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := gin.Default()
+			h := newTestUserHandler(ctrl, userHandlerDeps{verifySvc: tc.mock(ctrl)})
+			h.RegisterRoutes(server)
+			req, err := http.NewRequest(http.MethodPost,
+				"/users/email/verify", bytes.NewBuffer([]byte(tc.reqBody)))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+
+			server.ServeHTTP(resp, req)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			var res Result
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantBody, res)
+		})
+	}
+}
+
+func TestUserHandler_LoginSMS(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) (service.UserService, service.CodeService, service.MFAService, ijwt.Handler)
+		reqBody  string
+		wantBody Result
+	}{
+		{
+			name: "log in success",
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.CodeService, service.MFAService, ijwt.Handler) {
+				codeSvc := svcmock.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().Verify(gomock.Any(), "login", "15212345678", "123456").
+					Return(true, nil)
+				usersvc := svcmock.NewMockUserService(ctrl)
+				usersvc.EXPECT().FindOrCreate(gomock.Any(), "15212345678").
+					Return(domain.User{Id: 123}, nil)
+				mfaSvc := svcmock.NewMockMFAService(ctrl)
+				mfaSvc.EXPECT().Enabled(gomock.Any(), int64(123)).Return(false, nil)
+				jwtHdl := jwtmocks.NewMockHandler(ctrl)
+				jwtHdl.EXPECT().SetLoginToken(gomock.Any(), int64(123), gomock.Any()).Return(nil)
+				return usersvc, codeSvc, mfaSvc, jwtHdl
+			},
+			reqBody:  `{"phone":"15212345678","code":"123456"}`,
+			wantBody: Result{Msg: "Sign in successful"},
+		},
+		{
+			name: "mfa required",
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.CodeService, service.MFAService, ijwt.Handler) {
+				codeSvc := svcmock.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().Verify(gomock.Any(), "login", "15212345678", "123456").
+					Return(true, nil)
+				usersvc := svcmock.NewMockUserService(ctrl)
+				usersvc.EXPECT().FindOrCreate(gomock.Any(), "15212345678").
+					Return(domain.User{Id: 123}, nil)
+				mfaSvc := svcmock.NewMockMFAService(ctrl)
+				mfaSvc.EXPECT().Enabled(gomock.Any(), int64(123)).Return(true, nil)
+				mfaSvc.EXPECT().StartLogin(gomock.Any(), int64(123), gomock.Any()).Return("token_id", nil)
+				// 短信登录也不能绕过两步验证
+				return usersvc, codeSvc, mfaSvc, jwtmocks.NewMockHandler(ctrl)
+			},
+			reqBody:  `{"phone":"15212345678","code":"123456"}`,
+			wantBody: Result{Msg: "MFA required"},
+		},
+		{
+			name: "wrong code",
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.CodeService, service.MFAService, ijwt.Handler) {
+				codeSvc := svcmock.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().Verify(gomock.Any(), "login", "15212345678", "123456").
+					Return(false, nil)
+				return svcmock.NewMockUserService(ctrl), codeSvc, svcmock.NewMockMFAService(ctrl), jwtmocks.NewMockHandler(ctrl)
+			},
+			reqBody:  `{"phone":"15212345678","code":"123456"}`,
+			wantBody: Result{Code: 4, Msg: "Invalid code"},
+		},
+		{
+			name: "too many attempts",
+			mock: func(ctrl *gomock.Controller) (service.UserService, service.CodeService, service.MFAService, ijwt.Handler) {
+				codeSvc := svcmock.NewMockCodeService(ctrl)
+				codeSvc.EXPECT().Verify(gomock.Any(), "login", "15212345678", "123456").
+					Return(false, service.ErrCodeVerifyTooManyTimes)
+				return svcmock.NewMockUserService(ctrl), codeSvc, svcmock.NewMockMFAService(ctrl), jwtmocks.NewMockHandler(ctrl)
+			},
+			reqBody:  `{"phone":"15212345678","code":"123456"}`,
+			wantBody: Result{Code: 4, Msg: "Too many attempts, please resend the code"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			server := gin.Default()
+			usersvc, codeSvc, mfaSvc, jwtHdl := tc.mock(ctrl)
+			h := newTestUserHandler(ctrl, userHandlerDeps{svc: usersvc, codeSvc: codeSvc, mfaSvc: mfaSvc, jwtHdl: jwtHdl})
+			h.RegisterRoutes(server)
+			req, err := http.NewRequest(http.MethodPost,
+				"/users/login_sms", bytes.NewBuffer([]byte(tc.reqBody)))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			resp := httptest.NewRecorder()
+
+			server.ServeHTTP(resp, req)
+			assert.Equal(t, http.StatusOK, resp.Code)
+			var res Result
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantBody, res)
+		})
+	}
+}
+
 //func TestMock(t *testing.T) {
 //	ctrl := gomock.NewController(t)
 //
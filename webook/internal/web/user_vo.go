@@ -0,0 +1,116 @@
+package web
+
+// SessionVO 一个登录设备
+type SessionVO struct {
+	Ssid      string `json:"ssid"`
+	UserAgent string `json:"userAgent"`
+	IP        string `json:"ip"`
+	LoginTime string `json:"loginTime"`
+	LastSeen  string `json:"lastSeen"`
+	// 是不是发起这个请求的设备
+	Current bool `json:"current"`
+}
+
+type ProfileVO struct {
+	Nickname      string `json:"nickname"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"emailVerified"`
+	Phone         string `json:"phone"`
+	AboutMe       string `json:"aboutMe"`
+	// YYYY-MM-DD，没填就是空字符串
+	Birthday string `json:"birthday"`
+	// 申请了注销才有，过了这个时间账号会被清除
+	DeleteAfter string `json:"deleteAfter"`
+	// 前端用来决定要不要显示管理入口
+	Roles []string `json:"roles"`
+}
+
+// AdminUserVO 管理后台看到的用户信息
+type AdminUserVO struct {
+	Id          int64    `json:"id"`
+	Email       string   `json:"email"`
+	Phone       string   `json:"phone"`
+	Nickname    string   `json:"nickname"`
+	Roles       []string `json:"roles"`
+	Ctime       string   `json:"ctime"`
+	DeleteAfter string   `json:"deleteAfter"`
+}
+
+type MFAEnrollVO struct {
+	Secret string `json:"secret"`
+	// otpauth:// 开头，前端渲染成二维码
+	URI string `json:"uri"`
+}
+
+type MFAConfirmVO struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// 下面是导出个人数据用的，字段尽量完整，但是不包含密码
+
+type ExportProfileVO struct {
+	Id            int64  `json:"id"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"emailVerified"`
+	Phone         string `json:"phone"`
+	WechatOpenId  string `json:"wechatOpenId"`
+	Nickname      string `json:"nickname"`
+	Birthday      string `json:"birthday"`
+	AboutMe       string `json:"aboutMe"`
+	Ctime         string `json:"ctime"`
+	// 申请了注销才有
+	DeleteAfter string `json:"deleteAfter"`
+}
+
+type ExportArticleVO struct {
+	Id      int64  `json:"id"`
+	Title   string `json:"title"`
+	Content string `json:"content"`
+	Status  string `json:"status"`
+	Ctime   string `json:"ctime"`
+	Utime   string `json:"utime"`
+}
+
+type ExportUserBizVO struct {
+	Biz   string `json:"biz"`
+	BizId int64  `json:"bizId"`
+	// 收藏夹 ID，点赞没有这个字段
+	Cid   int64  `json:"cid,omitempty"`
+	Ctime string `json:"ctime"`
+}
+
+type DeletionVO struct {
+	// 过了这个时间数据就会被清除，在此之前都可以撤销
+	DeleteAfter string `json:"deleteAfter"`
+}
+
+// FollowVO 关注列表里面的一个人
+type FollowVO struct {
+	Uid int64 `json:"uid"`
+	// 什么时候关注的
+	Ctime string `json:"ctime"`
+}
+
+type FollowStaticsVO struct {
+	Followers int64 `json:"followers"`
+	Followees int64 `json:"followees"`
+	// 当前用户有没有关注这个人
+	Followed bool `json:"followed"`
+}
+
+// BlockVO 拉黑名单里面的一个人
+type BlockVO struct {
+	Uid int64 `json:"uid"`
+	// 什么时候拉黑的
+	Ctime string `json:"ctime"`
+}
+
+// AuthorVO 作者主页，谁都能看，不能放隐私信息
+type AuthorVO struct {
+	Id        int64  `json:"id"`
+	Nickname  string `json:"nickname"`
+	AboutMe   string `json:"aboutMe"`
+	Followers int64  `json:"followers"`
+	Followees int64  `json:"followees"`
+	Articles  int64  `json:"articles"`
+}
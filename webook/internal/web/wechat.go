@@ -0,0 +1,174 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"net/http"
+	"time"
+	"webook/internal/service"
+	"webook/internal/service/oauth2/wechat"
+	ijwt "webook/pkg/ginx/jwt"
+	"webook/pkg/logger"
+)
+
+const (
+	stateCookieName = "jwt-state"
+	// 用户要在这个时间内完成扫码
+	stateExpiration = time.Minute * 10
+)
+
+type OAuth2WechatHandler struct {
+	svc     wechat.Service
+	userSvc service.UserService
+	mfaSvc  service.MFAService
+	ijwt.Handler
+	stateKey []byte
+	log      logger.LoggerV1
+}
+
+// StateClaims 把 state 签名之后放在 cookie 里面，回调的时候比对，防止 CSRF
+type StateClaims struct {
+	State string
+	jwt.RegisteredClaims
+}
+
+func NewOAuth2WechatHandler(svc wechat.Service, userSvc service.UserService,
+	mfaSvc service.MFAService, jwtHdl ijwt.Handler, log logger.LoggerV1) *OAuth2WechatHandler {
+	return &OAuth2WechatHandler{
+		svc:      svc,
+		userSvc:  userSvc,
+		mfaSvc:   mfaSvc,
+		Handler:  jwtHdl,
+		stateKey: []byte("k6CswdUm77WKcbM68UQUuxVsHSpTCwgB"),
+		log:      log,
+	}
+}
+
+func (h *OAuth2WechatHandler) RegisterRoutes(server *gin.Engine) {
+	g := server.Group("/oauth2/wechat")
+	g.GET("/authurl", h.AuthURL)
+	// 微信回调用的是 GET
+	g.Any("/callback", h.Callback)
+}
+
+func (h *OAuth2WechatHandler) AuthURL(ctx *gin.Context) {
+	state := uuid.New().String()
+	url, err := h.svc.AuthURL(ctx, state)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		h.log.Error("构造微信登录 URL 失败", logger.Error(err))
+		return
+	}
+	err = h.setStateCookie(ctx, state)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		h.log.Error("设置 state cookie 失败", logger.Error(err))
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Data: url,
+	})
+}
+
+func (h *OAuth2WechatHandler) Callback(ctx *gin.Context) {
+	err := h.verifyState(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 4,
+			Msg:  "Invalid login request",
+		})
+		h.log.Warn("微信登录 state 校验失败", logger.Error(err))
+		return
+	}
+
+	code := ctx.Query("code")
+	info, err := h.svc.VerifyCode(ctx, code)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		h.log.Error("微信 code 换 token 失败", logger.Error(err))
+		return
+	}
+	u, err := h.userSvc.FindOrCreateByWechat(ctx, info)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		h.log.Error("微信登录查找或者创建用户失败",
+			logger.String("open_id", info.OpenId),
+			logger.Error(err))
+		return
+	}
+	// 开了两步验证的，微信登录也要走第二步
+	mfaRequired, err := loginOrMFA(ctx, h.mfaSvc, h.Handler, u)
+	if err != nil {
+		ctx.JSON(http.StatusOK, Result{
+			Code: 5,
+			Msg:  "system error",
+		})
+		return
+	}
+	if mfaRequired {
+		ctx.JSON(http.StatusOK, Result{
+			Msg: "MFA required",
+		})
+		return
+	}
+	ctx.JSON(http.StatusOK, Result{
+		Msg: "Sign in successful",
+	})
+}
+
+func (h *OAuth2WechatHandler) setStateCookie(ctx *gin.Context, state string) error {
+	claims := StateClaims{
+		State: state,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(stateExpiration)),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS512, claims)
+	tokenStr, err := token.SignedString(h.stateKey)
+	if err != nil {
+		return err
+	}
+	// 只有回调的时候需要带上
+	ctx.SetCookie(stateCookieName, tokenStr, int(stateExpiration.Seconds()),
+		"/oauth2/wechat/callback", "", true, true)
+	return nil
+}
+
+func (h *OAuth2WechatHandler) verifyState(ctx *gin.Context) error {
+	state := ctx.Query("state")
+	if state == "" {
+		return errors.New("回调没有带上 state")
+	}
+	ck, err := ctx.Cookie(stateCookieName)
+	if err != nil {
+		return fmt.Errorf("拿不到 state 的 cookie %w", err)
+	}
+	var sc StateClaims
+	token, err := jwt.ParseWithClaims(ck, &sc, func(token *jwt.Token) (interface{}, error) {
+		return h.stateKey, nil
+	})
+	if err != nil || !token.Valid {
+		return fmt.Errorf("state cookie 不合法 %w", err)
+	}
+	if sc.State != state {
+		return errors.New("state 不相等")
+	}
+	// state 只能用一次
+	ctx.SetCookie(stateCookieName, "", -1, "/oauth2/wechat/callback", "", true, true)
+	return nil
+}
@@ -0,0 +1,156 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"webook/internal/domain"
+	"webook/internal/service"
+	svcmock "webook/internal/service/mocks"
+	"webook/internal/service/oauth2/wechat"
+	wechatmocks "webook/internal/service/oauth2/wechat/mocks"
+	ijwt "webook/pkg/ginx/jwt"
+	jwtmocks "webook/pkg/ginx/jwt/mocks"
+	"webook/pkg/logger"
+)
+
+func TestOAuth2WechatHandler_Callback(t *testing.T) {
+	testCases := []struct {
+		name string
+		mock func(ctrl *gomock.Controller) (wechat.Service, *svcmock.MockUserService, service.MFAService, ijwt.Handler)
+		// 回调的时候带上的 state，空字符串表示用 authurl 生成的那个
+		state     string
+		setCookie bool
+		wantBody  Result
+	}{
+		{
+			name: "log in success",
+			mock: func(ctrl *gomock.Controller) (wechat.Service, *svcmock.MockUserService, service.MFAService, ijwt.Handler) {
+				svc := wechatmocks.NewMockService(ctrl)
+				svc.EXPECT().AuthURL(gomock.Any(), gomock.Any()).Return("https://open.weixin.qq.com", nil)
+				info := domain.WechatInfo{OpenId: "open_id", UnionId: "union_id"}
+				svc.EXPECT().VerifyCode(gomock.Any(), "the_code").Return(info, nil)
+				userSvc := svcmock.NewMockUserService(ctrl)
+				userSvc.EXPECT().FindOrCreateByWechat(gomock.Any(), info).
+					Return(domain.User{Id: 123}, nil)
+				mfaSvc := svcmock.NewMockMFAService(ctrl)
+				mfaSvc.EXPECT().Enabled(gomock.Any(), int64(123)).Return(false, nil)
+				jwtHdl := jwtmocks.NewMockHandler(ctrl)
+				jwtHdl.EXPECT().SetLoginToken(gomock.Any(), int64(123), gomock.Any()).Return(nil)
+				return svc, userSvc, mfaSvc, jwtHdl
+			},
+			setCookie: true,
+			wantBody:  Result{Msg: "Sign in successful"},
+		},
+		{
+			name: "mfa required",
+			mock: func(ctrl *gomock.Controller) (wechat.Service, *svcmock.MockUserService, service.MFAService, ijwt.Handler) {
+				svc := wechatmocks.NewMockService(ctrl)
+				svc.EXPECT().AuthURL(gomock.Any(), gomock.Any()).Return("https://open.weixin.qq.com", nil)
+				info := domain.WechatInfo{OpenId: "open_id", UnionId: "union_id"}
+				svc.EXPECT().VerifyCode(gomock.Any(), "the_code").Return(info, nil)
+				userSvc := svcmock.NewMockUserService(ctrl)
+				userSvc.EXPECT().FindOrCreateByWechat(gomock.Any(), info).
+					Return(domain.User{Id: 123}, nil)
+				mfaSvc := svcmock.NewMockMFAService(ctrl)
+				mfaSvc.EXPECT().Enabled(gomock.Any(), int64(123)).Return(true, nil)
+				mfaSvc.EXPECT().StartLogin(gomock.Any(), int64(123), gomock.Any()).Return("token_id", nil)
+				// 微信登录也不能绕过两步验证
+				return svc, userSvc, mfaSvc, jwtmocks.NewMockHandler(ctrl)
+			},
+			setCookie: true,
+			wantBody:  Result{Msg: "MFA required"},
+		},
+		{
+			name: "state mismatch",
+			mock: func(ctrl *gomock.Controller) (wechat.Service, *svcmock.MockUserService, service.MFAService, ijwt.Handler) {
+				svc := wechatmocks.NewMockService(ctrl)
+				svc.EXPECT().AuthURL(gomock.Any(), gomock.Any()).Return("https://open.weixin.qq.com", nil)
+				return svc, svcmock.NewMockUserService(ctrl), svcmock.NewMockMFAService(ctrl), jwtmocks.NewMockHandler(ctrl)
+			},
+			state:     "forged_state",
+			setCookie: true,
+			wantBody:  Result{Code: 4, Msg: "Invalid login request"},
+		},
+		{
+			name: "no state cookie",
+			mock: func(ctrl *gomock.Controller) (wechat.Service, *svcmock.MockUserService, service.MFAService, ijwt.Handler) {
+				svc := wechatmocks.NewMockService(ctrl)
+				svc.EXPECT().AuthURL(gomock.Any(), gomock.Any()).Return("https://open.weixin.qq.com", nil)
+				return svc, svcmock.NewMockUserService(ctrl), svcmock.NewMockMFAService(ctrl), jwtmocks.NewMockHandler(ctrl)
+			},
+			wantBody: Result{Code: 4, Msg: "Invalid login request"},
+		},
+		{
+			name: "verify code failed",
+			mock: func(ctrl *gomock.Controller) (wechat.Service, *svcmock.MockUserService, service.MFAService, ijwt.Handler) {
+				svc := wechatmocks.NewMockService(ctrl)
+				svc.EXPECT().AuthURL(gomock.Any(), gomock.Any()).Return("https://open.weixin.qq.com", nil)
+				svc.EXPECT().VerifyCode(gomock.Any(), "the_code").
+					Return(domain.WechatInfo{}, errors.New("invalid code"))
+				return svc, svcmock.NewMockUserService(ctrl), svcmock.NewMockMFAService(ctrl), jwtmocks.NewMockHandler(ctrl)
+			},
+			setCookie: true,
+			wantBody:  Result{Code: 5, Msg: "system error"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, userSvc, mfaSvc, jwtHdl := tc.mock(ctrl)
+			server := gin.Default()
+			h := NewOAuth2WechatHandler(svc, userSvc, mfaSvc, jwtHdl, logger.NewNoOpLogger())
+			h.RegisterRoutes(server)
+
+			// 先拿 authurl，顺便拿到 state cookie
+			req, err := http.NewRequest(http.MethodGet, "/oauth2/wechat/authurl", nil)
+			require.NoError(t, err)
+			resp := httptest.NewRecorder()
+			server.ServeHTTP(resp, req)
+			require.Equal(t, http.StatusOK, resp.Code)
+			cookies := resp.Result().Cookies()
+			require.Len(t, cookies, 1)
+			stateCookie := cookies[0]
+
+			state := tc.state
+			if state == "" {
+				state = stateFromCookie(t, h, stateCookie.Value)
+			}
+			q := url.Values{}
+			q.Set("code", "the_code")
+			q.Set("state", state)
+			req, err = http.NewRequest(http.MethodGet, "/oauth2/wechat/callback?"+q.Encode(), nil)
+			require.NoError(t, err)
+			if tc.setCookie {
+				req.AddCookie(stateCookie)
+			}
+			resp = httptest.NewRecorder()
+			server.ServeHTTP(resp, req)
+
+			assert.Equal(t, http.StatusOK, resp.Code)
+			var res Result
+			err = json.NewDecoder(resp.Body).Decode(&res)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantBody, res)
+		})
+	}
+}
+
+func stateFromCookie(t *testing.T, h *OAuth2WechatHandler, val string) string {
+	var sc StateClaims
+	_, err := jwt.ParseWithClaims(val, &sc, func(token *jwt.Token) (interface{}, error) {
+		return h.stateKey, nil
+	})
+	require.NoError(t, err)
+	return sc.State
+}
@@ -0,0 +1,11 @@
+package ioc
+
+import (
+	"webook/pkg/email"
+	"webook/pkg/email/localemail"
+)
+
+func InitEmailService() email.Service {
+	// 接入真正的邮件服务之前，先用本地的实现
+	return localemail.NewService()
+}
@@ -0,0 +1,9 @@
+package ioc
+
+import (
+	"webook/internal/job"
+)
+
+func InitJobs(j1 *job.ScheduledPublishJob) []job.Job {
+	return []job.Job{j1}
+}
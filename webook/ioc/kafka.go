@@ -28,6 +28,7 @@ func InitSyncProducer(c sarama.Client) sarama.SyncProducer {
 	return p
 }
 
-func InitConsumers(c1 *event.InteractiveReadEventConsumer) []event.Consumer {
-	return []event.Consumer{c1}
+func InitConsumers(c1 *event.InteractiveReadEventConsumer,
+	c2 *event.FeedPublishedEventConsumer) []event.Consumer {
+	return []event.Consumer{c1, c2}
 }
@@ -0,0 +1,26 @@
+package ioc
+
+import (
+	"context"
+	"github.com/redis/go-redis/v9"
+	"time"
+	"webook/internal/repository"
+	"webook/pkg/logger"
+	"webook/pkg/ratelimit"
+	"webook/pkg/sms"
+	"webook/pkg/sms/async"
+	"webook/pkg/sms/failover"
+	"webook/pkg/sms/localsms"
+	smsratelimit "webook/pkg/sms/ratelimit"
+)
+
+func InitSMSService(cmd redis.Cmdable, repo repository.AsyncSMSRepository, l logger.LoggerV1) sms.Service {
+	// 换成真正的供应商之前，先用本地的实现
+	// 接入多个供应商的时候，每个供应商单独限流，然后放进 failover 里面
+	local := smsratelimit.NewRateLimitSMSService(localsms.NewService(),
+		ratelimit.NewRedisSlidingWindowLimiter(cmd, time.Second, 100), "sms-limiter:local")
+	// 所有供应商都不行了，就存到数据库里面慢慢重试
+	svc := async.NewService(failover.NewFailoverSMSService([]sms.Service{local}, l), repo, l)
+	go svc.StartAsyncCycle(context.Background())
+	return svc
+}
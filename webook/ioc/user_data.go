@@ -0,0 +1,17 @@
+package ioc
+
+import (
+	"context"
+	"webook/internal/repository"
+	"webook/internal/service"
+	ijwt "webook/pkg/ginx/jwt"
+	"webook/pkg/logger"
+)
+
+func InitUserDataService(userRepo repository.UserRepository,
+	dataRepo repository.UserDataRepository, jwtHdl ijwt.Handler, l logger.LoggerV1) service.UserDataService {
+	svc := service.NewUserDataService(userRepo, dataRepo, jwtHdl, l)
+	// 注销冷静期过了的用户在后台清除
+	go svc.StartPurgeCycle(context.Background())
+	return svc
+}
@@ -8,24 +8,53 @@ import (
 	"time"
 	"webook/internal/web"
 	"webook/internal/web/middleware"
+	ijwt "webook/pkg/ginx/jwt"
 	"webook/pkg/ratelimit"
 )
 
 // articleHdl *web.ArticleHandler
-func InitWeb(mdls []gin.HandlerFunc, userHdl *web.UserHandler, articleHdl *web.ArticleHandler) *gin.Engine {
+func InitWeb(mdls []gin.HandlerFunc, userHdl *web.UserHandler,
+	articleHdl *web.ArticleHandler, wechatHdl *web.OAuth2WechatHandler,
+	adminHdl *web.AdminHandler, followHdl *web.FollowHandler,
+	feedHdl *web.FeedHandler, blockHdl *web.BlockHandler,
+	authorHdl *web.AuthorHandler, seriesHdl *web.SeriesHandler) *gin.Engine {
 	server := gin.Default()
 	server.Use(mdls...)
 	userHdl.RegisterRoutes(server)
 	articleHdl.RegisterRoutes(server)
+	wechatHdl.RegisterRoutes(server)
+	adminHdl.RegisterRoutes(server)
+	followHdl.RegisterRoutes(server)
+	feedHdl.RegisterRoutes(server)
+	blockHdl.RegisterRoutes(server)
+	authorHdl.RegisterRoutes(server)
+	seriesHdl.RegisterRoutes(server)
 	return server
 }
 
-func InitMiddlewares(redisClient redis.Cmdable) []gin.HandlerFunc {
+func InitMiddlewares(redisClient redis.Cmdable, jwtHdl ijwt.Handler) []gin.HandlerFunc {
 	return []gin.HandlerFunc{
 		corsHdlr(),
-		middleware.NewLoginJWTMiddlewareBuilder().
+		middleware.NewLoginJWTMiddlewareBuilder(jwtHdl).
 			IgnorePath("/users/login").
 			IgnorePath("/users/signup").
+			IgnorePath("/users/refresh_token").
+			IgnorePath("/users/login/mfa").
+			IgnorePath("/users/unlock").
+			IgnorePath("/users/login_sms/code/send").
+			IgnorePath("/users/login_sms").
+			IgnorePath("/users/password/forgot").
+			IgnorePath("/users/password/reset").
+			IgnorePath("/users/email/verify").
+			IgnorePath("/oauth2/wechat/authurl").
+			IgnorePath("/oauth2/wechat/callback").
+			IgnorePath("/articles/categories").
+			// 作者主页
+			IgnorePrefix("/authors/").
+			// 登录了要过滤掉自己拉黑的作者
+			OptionalPrefix("/articles/latest").
+			OptionalPrefix("/articles/pub/tags/").
+			OptionalPrefix("/series/detail/").
 			Build(),
 
 		ratelimit.NewBuilder(redisClient, time.Second, 100).Build(),
@@ -38,7 +67,7 @@ func corsHdlr() gin.HandlerFunc {
 
 		AllowHeaders:     []string{"authorization", "content-type"},
 		AllowCredentials: true,
-		ExposeHeaders:    []string{"x-jwt-token"},
+		ExposeHeaders:    []string{"x-jwt-token", "x-refresh-token", "x-mfa-token"},
 		AllowOriginFunc: func(origin string) bool {
 			if strings.HasPrefix(origin, "http://localhost") {
 				return true
@@ -0,0 +1,24 @@
+package ioc
+
+import (
+	"net/http"
+	"os"
+	"time"
+	"webook/config"
+	"webook/internal/service/oauth2/wechat"
+)
+
+func InitWechatService() wechat.Service {
+	cfg := config.Config.Wechat
+	// 密钥不放在代码里面
+	appID, ok := os.LookupEnv("WECHAT_APP_ID")
+	if ok {
+		cfg.AppID = appID
+	}
+	appSecret, ok := os.LookupEnv("WECHAT_APP_SECRET")
+	if ok {
+		cfg.AppSecret = appSecret
+	}
+	return wechat.NewService(cfg.AppID, cfg.AppSecret, cfg.RedirectURL, cfg.BaseURL,
+		&http.Client{Timeout: time.Second * 5})
+}
@@ -13,6 +13,12 @@ func main() {
 			panic(err)
 		}
 	}
+	for _, j := range app.jobs {
+		err := j.Start()
+		if err != nil {
+			panic(err)
+		}
+	}
 
 	server := app.server
 	server.GET("/hello", func(ctx *gin.Context) {
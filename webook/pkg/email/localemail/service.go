@@ -0,0 +1,45 @@
+package localemail
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// Mail 一封"发出去"的邮件
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Service 本地开发和测试用的实现，不真的发邮件，只是打日志并且记下来
+type Service struct {
+	mu    sync.Mutex
+	mails []Mail
+}
+
+func NewService() *Service {
+	return &Service{}
+}
+
+func (s *Service) Send(ctx context.Context, to, subject, body string) error {
+	log.Println("发送邮件", to, subject, body)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mails = append(s.mails, Mail{
+		To:      to,
+		Subject: subject,
+		Body:    body,
+	})
+	return nil
+}
+
+// Mails 到目前为止发送过的所有邮件
+func (s *Service) Mails() []Mail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := make([]Mail, len(s.mails))
+	copy(res, s.mails)
+	return res
+}
@@ -0,0 +1,8 @@
+package email
+
+import "context"
+
+// Service 发送邮件的抽象，屏蔽掉 SMTP 或者各种邮件服务商
+type Service interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
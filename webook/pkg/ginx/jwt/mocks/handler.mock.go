@@ -1,11 +1,18 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: ./webook/internal/web/jwt/types.go
+// Source: ./webook/pkg/ginx/jwt/types.go
+//
+// Generated by this command:
+//
+//	mockgen -source=./webook/pkg/ginx/jwt/types.go -package=jwtmocks -destination=./webook/pkg/ginx/jwt/mocks/handler.mock.go
+//
 
 // Package jwtmocks is a generated GoMock package.
 package jwtmocks
 
 import (
+	context "context"
 	reflect "reflect"
+	jwt "webook/pkg/ginx/jwt"
 
 	gin "github.com/gin-gonic/gin"
 	gomock "go.uber.org/mock/gomock"
@@ -15,6 +22,7 @@ import (
 type MockHandler struct {
 	ctrl     *gomock.Controller
 	recorder *MockHandlerMockRecorder
+	isgomock struct{}
 }
 
 // MockHandlerMockRecorder is the mock recorder for MockHandler.
@@ -35,17 +43,17 @@ func (m *MockHandler) EXPECT() *MockHandlerMockRecorder {
 }
 
 // CheckSession mocks base method.
-func (m *MockHandler) CheckSession(ctx *gin.Context, ssid string) error {
+func (m *MockHandler) CheckSession(ctx *gin.Context, uid int64, ssid string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CheckSession", ctx, ssid)
+	ret := m.ctrl.Call(m, "CheckSession", ctx, uid, ssid)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CheckSession indicates an expected call of CheckSession.
-func (mr *MockHandlerMockRecorder) CheckSession(ctx, ssid interface{}) *gomock.Call {
+func (mr *MockHandlerMockRecorder) CheckSession(ctx, uid, ssid any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckSession", reflect.TypeOf((*MockHandler)(nil).CheckSession), ctx, ssid)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckSession", reflect.TypeOf((*MockHandler)(nil).CheckSession), ctx, uid, ssid)
 }
 
 // ClearToken mocks base method.
@@ -57,49 +65,120 @@ func (m *MockHandler) ClearToken(ctx *gin.Context) error {
 }
 
 // ClearToken indicates an expected call of ClearToken.
-func (mr *MockHandlerMockRecorder) ClearToken(ctx interface{}) *gomock.Call {
+func (mr *MockHandlerMockRecorder) ClearToken(ctx any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearToken", reflect.TypeOf((*MockHandler)(nil).ClearToken), ctx)
 }
 
-// ExtractTokenString mocks base method.
-func (m *MockHandler) ExtractTokenString(ctx *gin.Context) string {
+// ExtractToken mocks base method.
+func (m *MockHandler) ExtractToken(ctx *gin.Context) string {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ExtractTokenString", ctx)
+	ret := m.ctrl.Call(m, "ExtractToken", ctx)
 	ret0, _ := ret[0].(string)
 	return ret0
 }
 
-// ExtractTokenString indicates an expected call of ExtractTokenString.
-func (mr *MockHandlerMockRecorder) ExtractTokenString(ctx interface{}) *gomock.Call {
+// ExtractToken indicates an expected call of ExtractToken.
+func (mr *MockHandlerMockRecorder) ExtractToken(ctx any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExtractTokenString", reflect.TypeOf((*MockHandler)(nil).ExtractTokenString), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExtractToken", reflect.TypeOf((*MockHandler)(nil).ExtractToken), ctx)
+}
+
+// RevokeOtherSessions mocks base method.
+func (m *MockHandler) RevokeOtherSessions(ctx *gin.Context, uid int64, keep string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeOtherSessions", ctx, uid, keep)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeOtherSessions indicates an expected call of RevokeOtherSessions.
+func (mr *MockHandlerMockRecorder) RevokeOtherSessions(ctx, uid, keep any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeOtherSessions", reflect.TypeOf((*MockHandler)(nil).RevokeOtherSessions), ctx, uid, keep)
+}
+
+// RevokeSession mocks base method.
+func (m *MockHandler) RevokeSession(ctx *gin.Context, uid int64, ssid string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", ctx, uid, ssid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockHandlerMockRecorder) RevokeSession(ctx, uid, ssid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockHandler)(nil).RevokeSession), ctx, uid, ssid)
+}
+
+// RevokeUser mocks base method.
+func (m *MockHandler) RevokeUser(ctx context.Context, uid int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUser", ctx, uid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeUser indicates an expected call of RevokeUser.
+func (mr *MockHandlerMockRecorder) RevokeUser(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUser", reflect.TypeOf((*MockHandler)(nil).RevokeUser), ctx, uid)
+}
+
+// Sessions mocks base method.
+func (m *MockHandler) Sessions(ctx *gin.Context, uid int64) ([]jwt.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sessions", ctx, uid)
+	ret0, _ := ret[0].([]jwt.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Sessions indicates an expected call of Sessions.
+func (mr *MockHandlerMockRecorder) Sessions(ctx, uid any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sessions", reflect.TypeOf((*MockHandler)(nil).Sessions), ctx, uid)
 }
 
 // SetJWTToken mocks base method.
-func (m *MockHandler) SetJWTToken(ctx *gin.Context, ssid string, uid int64) error {
+func (m *MockHandler) SetJWTToken(ctx *gin.Context, uid int64, ssid string, roles []string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetJWTToken", ctx, ssid, uid)
+	ret := m.ctrl.Call(m, "SetJWTToken", ctx, uid, ssid, roles)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // SetJWTToken indicates an expected call of SetJWTToken.
-func (mr *MockHandlerMockRecorder) SetJWTToken(ctx, ssid, uid interface{}) *gomock.Call {
+func (mr *MockHandlerMockRecorder) SetJWTToken(ctx, uid, ssid, roles any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetJWTToken", reflect.TypeOf((*MockHandler)(nil).SetJWTToken), ctx, ssid, uid)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetJWTToken", reflect.TypeOf((*MockHandler)(nil).SetJWTToken), ctx, uid, ssid, roles)
 }
 
 // SetLoginToken mocks base method.
-func (m *MockHandler) SetLoginToken(ctx *gin.Context, uid int64) error {
+func (m *MockHandler) SetLoginToken(ctx *gin.Context, uid int64, roles []string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SetLoginToken", ctx, uid)
+	ret := m.ctrl.Call(m, "SetLoginToken", ctx, uid, roles)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // SetLoginToken indicates an expected call of SetLoginToken.
-func (mr *MockHandlerMockRecorder) SetLoginToken(ctx, uid interface{}) *gomock.Call {
+func (mr *MockHandlerMockRecorder) SetLoginToken(ctx, uid, roles any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLoginToken", reflect.TypeOf((*MockHandler)(nil).SetLoginToken), ctx, uid, roles)
+}
+
+// TouchSession mocks base method.
+func (m *MockHandler) TouchSession(ctx *gin.Context, uid int64, ssid string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchSession", ctx, uid, ssid)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchSession indicates an expected call of TouchSession.
+func (mr *MockHandlerMockRecorder) TouchSession(ctx, uid, ssid any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLoginToken", reflect.TypeOf((*MockHandler)(nil).SetLoginToken), ctx, uid)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchSession", reflect.TypeOf((*MockHandler)(nil).TouchSession), ctx, uid, ssid)
 }
@@ -16,6 +16,14 @@ var (
 	RtKey = []byte("95osj3fUD7fo0mlYdDbncXz4VD2igvfx")
 )
 
+var ErrSessionRevoked = errors.New("session 已经无效了")
+
+const (
+	atExpiration = time.Minute * 30
+	// 撤销记录至少要活得和 refresh token 一样久
+	rtExpiration = time.Hour * 24 * 7
+)
+
 type RedisJWTHandler struct {
 	cmd redis.Cmdable
 }
@@ -26,21 +34,31 @@ func NewRedisJWTHandler(cmd redis.Cmdable) Handler {
 	}
 }
 
-func (h *RedisJWTHandler) SetLoginToken(ctx *gin.Context, uid int64) error {
+func (h *RedisJWTHandler) SetLoginToken(ctx *gin.Context, uid int64, roles []string) error {
 	ssid := uuid.New().String()
-	err := h.SetJWTToken(ctx, uid, ssid)
+	err := h.SetJWTToken(ctx, uid, ssid, roles)
 	if err != nil {
 		return err
 	}
 	err = h.setRefreshToken(ctx, uid, ssid)
-	return err
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	return h.saveSession(ctx, uid, Session{
+		Ssid:      ssid,
+		UserAgent: ctx.Request.UserAgent(),
+		IP:        ctx.ClientIP(),
+		LoginTime: now,
+		LastSeen:  now,
+	})
 }
 
 func (h *RedisJWTHandler) setRefreshToken(ctx *gin.Context, uid int64, ssid string) error {
 	claims := RefreshClaims{
 		Ssid: ssid,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 24 * 7)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(rtExpiration)),
 		},
 		Uid: uid,
 	}
@@ -57,13 +75,19 @@ func (h *RedisJWTHandler) ClearToken(ctx *gin.Context) error {
 	ctx.Header("x-jwt-token", "")
 	ctx.Header("x-refresh-token", "")
 
-	claims := ctx.MustGet("users").(*UserClaims)
-	return h.cmd.Set(ctx, fmt.Sprintf("users:ssid:%s", claims.Ssid),
-		"", time.Hour*24*7).Err()
+	val, ok := ctx.Get("users")
+	if !ok {
+		return errors.New("没有登录信息")
+	}
+	claims, ok := val.(*UserClaims)
+	if !ok {
+		return errors.New("登录信息类型不对")
+	}
+	return h.RevokeSession(ctx, claims.Id, claims.Ssid)
 }
 
-func (h *RedisJWTHandler) CheckSession(ctx *gin.Context, ssid string) error {
-	val, err := h.cmd.Exists(ctx, fmt.Sprintf("users:ssid:%s", ssid)).Result()
+func (h *RedisJWTHandler) CheckSession(ctx *gin.Context, uid int64, ssid string) error {
+	val, err := h.cmd.Exists(ctx, h.ssidKey(ssid), h.userKey(uid)).Result()
 	switch err {
 	case redis.Nil:
 		return nil
@@ -71,7 +95,7 @@ func (h *RedisJWTHandler) CheckSession(ctx *gin.Context, ssid string) error {
 		if val == 0 {
 			return nil
 		}
-		return errors.New("session 已经无效了")
+		return ErrSessionRevoked
 	default:
 		return err
 	}
@@ -88,14 +112,15 @@ func (h *RedisJWTHandler) ExtractToken(ctx *gin.Context) string {
 	return segs[1]
 }
 
-func (h *RedisJWTHandler) SetJWTToken(ctx *gin.Context, uid int64, ssid string) error {
+func (h *RedisJWTHandler) SetJWTToken(ctx *gin.Context, uid int64, ssid string, roles []string) error {
 	claims := UserClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute * 30)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(atExpiration)),
 		},
 		Id:        uid,
 		Ssid:      ssid,
 		UserAgent: ctx.Request.UserAgent(),
+		Roles:     roles,
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS512, claims)
 	tokenStr, err := token.SignedString(AtKey)
@@ -105,3 +130,11 @@ func (h *RedisJWTHandler) SetJWTToken(ctx *gin.Context, uid int64, ssid string)
 	ctx.Header("x-jwt-token", tokenStr)
 	return nil
 }
+
+func (h *RedisJWTHandler) ssidKey(ssid string) string {
+	return fmt.Sprintf("users:ssid:%s", ssid)
+}
+
+func (h *RedisJWTHandler) userKey(uid int64) string {
+	return fmt.Sprintf("users:revoked:%d", uid)
+}
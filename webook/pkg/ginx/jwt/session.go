@@ -0,0 +1,131 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+// 最近活跃时间不需要很精确，避免每个请求都写一次 redis
+const touchInterval = time.Minute
+
+// 每个用户一个 hash，field 是 ssid，value 是 Session 的 JSON
+
+func (h *RedisJWTHandler) saveSession(ctx *gin.Context, uid int64, s Session) error {
+	val, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	key := h.sessionsKey(uid)
+	pipe := h.cmd.TxPipeline()
+	pipe.HSet(ctx, key, s.Ssid, val)
+	// 最后一次登录之后，整个索引最多再活一个 refresh token 的周期
+	pipe.Expire(ctx, key, rtExpiration)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (h *RedisJWTHandler) TouchSession(ctx *gin.Context, uid int64, ssid string) error {
+	val, err := h.cmd.HGet(ctx, h.sessionsKey(uid), ssid).Bytes()
+	if err == redis.Nil {
+		// 上线这个功能之前登录的，没有索引，不用管
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var s Session
+	err = json.Unmarshal(val, &s)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if now.Sub(s.LastSeen) < touchInterval {
+		return nil
+	}
+	s.LastSeen = now
+	s.IP = ctx.ClientIP()
+	val, err = json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return h.cmd.HSet(ctx, h.sessionsKey(uid), ssid, val).Err()
+}
+
+func (h *RedisJWTHandler) Sessions(ctx *gin.Context, uid int64) ([]Session, error) {
+	key := h.sessionsKey(uid)
+	vals, err := h.cmd.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+	res := make([]Session, 0, len(vals))
+	var expired []string
+	now := time.Now()
+	for ssid, val := range vals {
+		var s Session
+		err = json.Unmarshal([]byte(val), &s)
+		if err != nil {
+			// 坏数据，顺手清掉
+			expired = append(expired, ssid)
+			continue
+		}
+		if now.Sub(s.LoginTime) > rtExpiration {
+			// refresh token 都过期了，这个 session 已经没用了
+			expired = append(expired, ssid)
+			continue
+		}
+		res = append(res, s)
+	}
+	if len(expired) > 0 {
+		// 清理失败也不影响返回结果
+		_ = h.cmd.HDel(ctx, key, expired...).Err()
+	}
+	return res, nil
+}
+
+func (h *RedisJWTHandler) RevokeSession(ctx *gin.Context, uid int64, ssid string) error {
+	pipe := h.cmd.TxPipeline()
+	// 把 ssid 放进黑名单，短 token 和长 token 都用不了了
+	pipe.Set(ctx, h.ssidKey(ssid), "", rtExpiration)
+	pipe.HDel(ctx, h.sessionsKey(uid), ssid)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (h *RedisJWTHandler) RevokeOtherSessions(ctx *gin.Context, uid int64, keep string) error {
+	ssids, err := h.cmd.HKeys(ctx, h.sessionsKey(uid)).Result()
+	if err != nil {
+		return err
+	}
+	pipe := h.cmd.TxPipeline()
+	var others []string
+	for _, ssid := range ssids {
+		if ssid == keep {
+			continue
+		}
+		others = append(others, ssid)
+		pipe.Set(ctx, h.ssidKey(ssid), "", rtExpiration)
+	}
+	if len(others) == 0 {
+		return nil
+	}
+	pipe.HDel(ctx, h.sessionsKey(uid), others...)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (h *RedisJWTHandler) RevokeUser(ctx context.Context, uid int64) error {
+	pipe := h.cmd.TxPipeline()
+	// 按用户拉黑，没有进索引的老 session 也一起作废
+	pipe.Set(ctx, h.userKey(uid), "", rtExpiration)
+	pipe.Del(ctx, h.sessionsKey(uid))
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (h *RedisJWTHandler) sessionsKey(uid int64) string {
+	return fmt.Sprintf("users:sessions:%d", uid)
+}
@@ -1,16 +1,39 @@
 package jwt
 
 import (
+	"context"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"time"
 )
 
 type Handler interface {
-	SetLoginToken(ctx *gin.Context, uid int64) error
-	SetJWTToken(ctx *gin.Context, uid int64, ssid string) error
+	// SetLoginToken roles 会放进短 token 里面，用来做权限校验
+	SetLoginToken(ctx *gin.Context, uid int64, roles []string) error
+	SetJWTToken(ctx *gin.Context, uid int64, ssid string, roles []string) error
 	ClearToken(ctx *gin.Context) error
-	CheckSession(ctx *gin.Context, ssid string) error
+	// CheckSession 这个 ssid 被撤销了，或者整个用户被作废了，都返回 ErrSessionRevoked
+	CheckSession(ctx *gin.Context, uid int64, ssid string) error
 	ExtractToken(ctx *gin.Context) string
+
+	// TouchSession 刷新一下 session 的最近活跃时间
+	TouchSession(ctx *gin.Context, uid int64, ssid string) error
+	// Sessions 某个用户所有还有效的登录
+	Sessions(ctx *gin.Context, uid int64) ([]Session, error)
+	RevokeSession(ctx *gin.Context, uid int64, ssid string) error
+	// RevokeOtherSessions 除了 keep 之外的全部踢下线
+	RevokeOtherSessions(ctx *gin.Context, uid int64, keep string) error
+	// RevokeUser 账号被清除的时候用，这个用户签发过的 token 全部作废，不在 HTTP 请求里面调用
+	RevokeUser(ctx context.Context, uid int64) error
+}
+
+// Session 一次登录，也就是一个 ssid
+type Session struct {
+	Ssid      string
+	UserAgent string
+	IP        string
+	LoginTime time.Time
+	LastSeen  time.Time
 }
 
 type RefreshClaims struct {
@@ -27,5 +50,17 @@ type UserClaims struct {
 	// 自己随便加
 	UserAgent string
 
-	VIP bool
+	// 签发的时候的角色，改了角色要重新登录或者刷新 token 才生效
+	Roles []string
+}
+
+func (c *UserClaims) HasAnyRole(roles ...string) bool {
+	for _, have := range c.Roles {
+		for _, want := range roles {
+			if have == want {
+				return true
+			}
+		}
+	}
+	return false
 }
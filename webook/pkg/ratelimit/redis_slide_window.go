@@ -0,0 +1,29 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+// RedisSlidingWindowLimiter 和 Builder 用的是同一个滑动窗口脚本
+type RedisSlidingWindowLimiter struct {
+	cmd redis.Cmdable
+	// 窗口大小
+	interval time.Duration
+	// 阈值
+	rate int
+}
+
+func NewRedisSlidingWindowLimiter(cmd redis.Cmdable, interval time.Duration, rate int) Limiter {
+	return &RedisSlidingWindowLimiter{
+		cmd:      cmd,
+		interval: interval,
+		rate:     rate,
+	}
+}
+
+func (r *RedisSlidingWindowLimiter) Limit(ctx context.Context, key string) (bool, error) {
+	return r.cmd.Eval(ctx, luaScript, []string{key},
+		r.interval.Milliseconds(), r.rate, time.Now().UnixMilli()).Bool()
+}
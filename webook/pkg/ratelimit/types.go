@@ -0,0 +1,10 @@
+package ratelimit
+
+import "context"
+
+// Limiter 限流器，不局限于 HTTP 请求
+type Limiter interface {
+	// Limit 有没有触发限流，key 就是限流对象
+	// true 就是要限流
+	Limit(ctx context.Context, key string) (bool, error)
+}
@@ -0,0 +1,147 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"time"
+	"webook/pkg/logger"
+	"webook/pkg/sms"
+)
+
+// Service 先同步发送，失败了就存起来，由后台异步重试
+// 这样服务商限流或者崩溃的时候，调用方不会感知到错误
+type Service struct {
+	svc   sms.Service
+	store Store
+	l     logger.LoggerV1
+
+	// 最多重试几次
+	maxRetry int
+	// 第一次重试的间隔，之后每次翻倍
+	baseInterval time.Duration
+	// 重试间隔的上限
+	maxInterval time.Duration
+	// 异步发送一条短信的超时时间
+	sendTimeout time.Duration
+	// 没有短信要发的时候，歇一会
+	idleInterval time.Duration
+}
+
+func NewService(svc sms.Service, store Store, l logger.LoggerV1) *Service {
+	return &Service{
+		svc:          svc,
+		store:        store,
+		l:            l,
+		maxRetry:     5,
+		baseInterval: time.Second * 10,
+		maxInterval:  time.Minute * 10,
+		sendTimeout:  time.Second * 3,
+		idleInterval: time.Second,
+	}
+}
+
+func (s *Service) Send(ctx context.Context, tplId string, args []string, numbers ...string) error {
+	err := s.svc.Send(ctx, tplId, args, numbers...)
+	if err == nil {
+		return nil
+	}
+	s.l.Warn("同步发送短信失败，转异步",
+		logger.String("tpl_id", tplId),
+		logger.Error(err))
+	er := s.store.Add(ctx, Message{
+		TplId:   tplId,
+		Args:    args,
+		Numbers: numbers,
+	})
+	if er != nil {
+		// 存都存不进去，只能把原本的错误还给调用方
+		s.l.Error("保存异步短信失败",
+			logger.String("tpl_id", tplId),
+			logger.Error(er))
+		return err
+	}
+	return nil
+}
+
+// StartAsyncCycle 后台不断地抢占短信来发送，直到 ctx 被取消
+func (s *Service) StartAsyncCycle(ctx context.Context) {
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+		processed := s.asyncSend(ctx)
+		if processed {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.idleInterval):
+		}
+	}
+}
+
+// asyncSend 发送一条短信，返回有没有抢到短信
+func (s *Service) asyncSend(ctx context.Context) bool {
+	msg, err := s.store.Preempt(ctx)
+	if errors.Is(err, ErrNoMessage) {
+		return false
+	}
+	if err != nil {
+		s.l.Error("抢占异步短信失败", logger.Error(err))
+		return false
+	}
+	if msg.RetryCnt >= s.maxRetry {
+		// 一发就把实例搞崩或者卡住的短信，被反复抢回来，不能一直发下去
+		s.l.Warn("异步短信抢回来的次数太多，放弃发送",
+			logger.Int64("id", msg.Id),
+			logger.Int("retry_cnt", msg.RetryCnt))
+		s.updated(msg, s.store.Fail(ctx, msg))
+		return true
+	}
+	sendCtx, cancel := context.WithTimeout(ctx, s.sendTimeout)
+	err = s.svc.Send(sendCtx, msg.TplId, msg.Args, msg.Numbers...)
+	cancel()
+	if err == nil {
+		s.updated(msg, s.store.Success(ctx, msg))
+		return true
+	}
+	s.l.Warn("异步发送短信失败",
+		logger.Int64("id", msg.Id),
+		logger.Int("retry_cnt", msg.RetryCnt),
+		logger.Error(err))
+	if msg.RetryCnt+1 >= s.maxRetry {
+		err = s.store.Fail(ctx, msg)
+	} else {
+		err = s.store.Retry(ctx, msg, time.Now().Add(s.backoff(msg.RetryCnt)))
+	}
+	s.updated(msg, err)
+	return true
+}
+
+// updated 记录更新短信状态的错误
+func (s *Service) updated(msg Message, err error) {
+	if errors.Is(err, ErrPreemptLost) {
+		// 状态交给抢走的那个实例去更新
+		s.l.Warn("异步短信已经被别的实例抢占",
+			logger.Int64("id", msg.Id))
+		return
+	}
+	if err != nil {
+		s.l.Error("更新异步短信状态失败",
+			logger.Int64("id", msg.Id),
+			logger.Error(err))
+	}
+}
+
+// backoff 指数退避，retryCnt 从 0 开始
+func (s *Service) backoff(retryCnt int) time.Duration {
+	interval := s.baseInterval
+	for i := 0; i < retryCnt; i++ {
+		interval *= 2
+		if interval >= s.maxInterval {
+			return s.maxInterval
+		}
+	}
+	return interval
+}
@@ -0,0 +1,238 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"time"
+	"webook/pkg/logger"
+	smsmocks "webook/pkg/sms/mocks"
+)
+
+// memoryStore 测试用的内存实现
+type memoryStore struct {
+	msgs      map[int64]*Message
+	nextRetry map[int64]time.Time
+	status    map[int64]string
+	addErr    error
+	// 模拟发得太慢，被别的实例抢走了
+	lost bool
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		msgs:      map[int64]*Message{},
+		nextRetry: map[int64]time.Time{},
+		status:    map[int64]string{},
+	}
+}
+
+func (m *memoryStore) Add(ctx context.Context, msg Message) error {
+	if m.addErr != nil {
+		return m.addErr
+	}
+	msg.Id = int64(len(m.msgs) + 1)
+	m.msgs[msg.Id] = &msg
+	m.status[msg.Id] = "waiting"
+	return nil
+}
+
+func (m *memoryStore) Preempt(ctx context.Context) (Message, error) {
+	for id, msg := range m.msgs {
+		if m.status[id] == "waiting" && !m.nextRetry[id].After(time.Now()) {
+			m.status[id] = "sending"
+			return *msg, nil
+		}
+	}
+	return Message{}, ErrNoMessage
+}
+
+func (m *memoryStore) Success(ctx context.Context, msg Message) error {
+	if m.lost {
+		return ErrPreemptLost
+	}
+	m.status[msg.Id] = "success"
+	return nil
+}
+
+func (m *memoryStore) Retry(ctx context.Context, msg Message, nextRetry time.Time) error {
+	if m.lost {
+		return ErrPreemptLost
+	}
+	m.status[msg.Id] = "waiting"
+	m.msgs[msg.Id].RetryCnt++
+	m.nextRetry[msg.Id] = nextRetry
+	return nil
+}
+
+func (m *memoryStore) Fail(ctx context.Context, msg Message) error {
+	if m.lost {
+		return ErrPreemptLost
+	}
+	m.status[msg.Id] = "failed"
+	m.msgs[msg.Id].RetryCnt++
+	return nil
+}
+
+func TestService_Send(t *testing.T) {
+	testCases := []struct {
+		name       string
+		mock       func(ctrl *gomock.Controller) *smsmocks.MockService
+		addErr     error
+		wantErr    error
+		wantStored int
+	}{
+		{
+			name: "sync success",
+			mock: func(ctrl *gomock.Controller) *smsmocks.MockService {
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "15212345678").
+					Return(nil)
+				return svc
+			},
+		},
+		{
+			name: "provider down, stored for retry",
+			mock: func(ctrl *gomock.Controller) *smsmocks.MockService {
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "15212345678").
+					Return(errors.New("provider down"))
+				return svc
+			},
+			wantStored: 1,
+		},
+		{
+			name: "store error",
+			mock: func(ctrl *gomock.Controller) *smsmocks.MockService {
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "15212345678").
+					Return(errors.New("provider down"))
+				return svc
+			},
+			addErr:  errors.New("mock db error"),
+			wantErr: errors.New("provider down"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := newMemoryStore()
+			store.addErr = tc.addErr
+			svc := NewService(tc.mock(ctrl), store, logger.NewNoOpLogger())
+			err := svc.Send(context.Background(), "tpl", []string{"123456"}, "15212345678")
+			assert.Equal(t, tc.wantErr, err)
+			assert.Len(t, store.msgs, tc.wantStored)
+		})
+	}
+}
+
+func TestService_asyncSend(t *testing.T) {
+	testCases := []struct {
+		name     string
+		mock     func(ctrl *gomock.Controller) *smsmocks.MockService
+		retryCnt int
+		lost     bool
+
+		wantStatus   string
+		wantRetryCnt int
+		wantBackoff  time.Duration
+	}{
+		{
+			name: "retry success",
+			mock: func(ctrl *gomock.Controller) *smsmocks.MockService {
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "15212345678").
+					Return(nil)
+				return svc
+			},
+			wantStatus: "success",
+		},
+		{
+			name: "retry later",
+			mock: func(ctrl *gomock.Controller) *smsmocks.MockService {
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "15212345678").
+					Return(errors.New("provider down"))
+				return svc
+			},
+			retryCnt:     2,
+			wantStatus:   "waiting",
+			wantRetryCnt: 3,
+			wantBackoff:  time.Second * 40,
+		},
+		{
+			name: "give up",
+			mock: func(ctrl *gomock.Controller) *smsmocks.MockService {
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "15212345678").
+					Return(errors.New("provider down"))
+				return svc
+			},
+			retryCnt:     4,
+			wantStatus:   "failed",
+			wantRetryCnt: 5,
+		},
+		{
+			// 卡住被抢回来的次数也算，用完了就不再发
+			name: "reclaimed too many times",
+			mock: func(ctrl *gomock.Controller) *smsmocks.MockService {
+				return smsmocks.NewMockService(ctrl)
+			},
+			retryCnt:     5,
+			wantStatus:   "failed",
+			wantRetryCnt: 6,
+		},
+		{
+			// 状态留给抢走的实例去改
+			name: "preempt lost",
+			mock: func(ctrl *gomock.Controller) *smsmocks.MockService {
+				svc := smsmocks.NewMockService(ctrl)
+				svc.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "15212345678").
+					Return(nil)
+				return svc
+			},
+			lost:       true,
+			wantStatus: "sending",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			store := newMemoryStore()
+			err := store.Add(context.Background(), Message{
+				TplId:    "tpl",
+				Args:     []string{"123456"},
+				Numbers:  []string{"15212345678"},
+				RetryCnt: tc.retryCnt,
+			})
+			require.NoError(t, err)
+			store.lost = tc.lost
+			svc := NewService(tc.mock(ctrl), store, logger.NewNoOpLogger())
+			start := time.Now()
+			processed := svc.asyncSend(context.Background())
+			assert.True(t, processed)
+			assert.Equal(t, tc.wantStatus, store.status[1])
+			assert.Equal(t, tc.wantRetryCnt, store.msgs[1].RetryCnt)
+			if tc.wantBackoff > 0 {
+				assert.WithinDuration(t, start.Add(tc.wantBackoff), store.nextRetry[1], time.Second)
+			}
+			// 没有短信了
+			assert.False(t, svc.asyncSend(context.Background()))
+		})
+	}
+}
+
+func TestService_backoff(t *testing.T) {
+	svc := NewService(nil, nil, logger.NewNoOpLogger())
+	assert.Equal(t, time.Second*10, svc.backoff(0))
+	assert.Equal(t, time.Second*20, svc.backoff(1))
+	assert.Equal(t, time.Second*160, svc.backoff(4))
+	assert.Equal(t, time.Minute*10, svc.backoff(10))
+}
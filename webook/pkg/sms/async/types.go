@@ -0,0 +1,38 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrNoMessage = errors.New("没有等待发送的短信")
+	// ErrPreemptLost 发得太慢，短信已经被别的实例当成卡住的抢走了
+	ErrPreemptLost = errors.New("短信已经被别的实例抢占了")
+)
+
+// Message 一条等待重试的短信
+type Message struct {
+	Id      int64
+	TplId   string
+	Args    []string
+	Numbers []string
+	// 已经重试了几次，卡住被别的实例抢走也算一次
+	RetryCnt int
+	// 抢占的时间，更新状态的时候用来确认还是自己抢到的那一次
+	PreemptedAt time.Time
+}
+
+// Store 持久化等待重试的短信，要求多实例部署的时候同一条短信只会被一个实例抢到
+type Store interface {
+	Add(ctx context.Context, msg Message) error
+	// Preempt 抢占一条已经到了重试时间的短信，没有的话返回 ErrNoMessage
+	Preempt(ctx context.Context) (Message, error)
+	// Success 下面这几个只有 msg 还是自己抢占的时候才会更新，不然返回 ErrPreemptLost
+	Success(ctx context.Context, msg Message) error
+	// Retry 发送失败，等到 nextRetry 再试
+	Retry(ctx context.Context, msg Message, nextRetry time.Time) error
+	// Fail 重试次数用完了，彻底放弃
+	Fail(ctx context.Context, msg Message) error
+}
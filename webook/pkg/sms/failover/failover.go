@@ -0,0 +1,47 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"webook/pkg/logger"
+	"webook/pkg/sms"
+)
+
+var ErrAllFailed = errors.New("全部服务商都失败了")
+
+// FailoverSMSService 轮询所有的服务商，一个失败了就换下一个
+type FailoverSMSService struct {
+	svcs []sms.Service
+	// 每次从不同的服务商开始，把负载摊开
+	idx uint64
+	l   logger.LoggerV1
+}
+
+func NewFailoverSMSService(svcs []sms.Service, l logger.LoggerV1) sms.Service {
+	return &FailoverSMSService{
+		svcs: svcs,
+		l:    l,
+	}
+}
+
+func (f *FailoverSMSService) Send(ctx context.Context, tplId string, args []string, numbers ...string) error {
+	idx := atomic.AddUint64(&f.idx, 1)
+	length := uint64(len(f.svcs))
+	for i := idx; i < idx+length; i++ {
+		svc := f.svcs[i%length]
+		err := svc.Send(ctx, tplId, args, numbers...)
+		if err == nil {
+			return nil
+		}
+		// 只有调用方自己的 ctx 过期了才停下，换服务商也没用
+		// 服务商自己超时也会返回 DeadlineExceeded，这种要换下一个
+		if ctx.Err() != nil {
+			return err
+		}
+		f.l.Warn("短信服务商发送失败",
+			logger.Int("idx", int(i%length)),
+			logger.Error(err))
+	}
+	return ErrAllFailed
+}
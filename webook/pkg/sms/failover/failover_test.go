@@ -0,0 +1,101 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"webook/pkg/logger"
+	"webook/pkg/sms"
+	smsmocks "webook/pkg/sms/mocks"
+)
+
+func TestFailoverSMSService_Send(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) []sms.Service
+		ctx     func() context.Context
+		wantErr error
+	}{
+		{
+			name: "first one success",
+			mock: func(ctrl *gomock.Controller) []sms.Service {
+				svc0 := smsmocks.NewMockService(ctrl)
+				svc1 := smsmocks.NewMockService(ctrl)
+				// idx 从 1 开始
+				svc1.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "15212345678").
+					Return(nil)
+				return []sms.Service{svc0, svc1}
+			},
+		},
+		{
+			name: "fail over to the next one",
+			mock: func(ctrl *gomock.Controller) []sms.Service {
+				svc0 := smsmocks.NewMockService(ctrl)
+				svc1 := smsmocks.NewMockService(ctrl)
+				svc1.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "15212345678").
+					Return(errors.New("provider down"))
+				svc0.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "15212345678").
+					Return(nil)
+				return []sms.Service{svc0, svc1}
+			},
+		},
+		{
+			name: "all failed",
+			mock: func(ctrl *gomock.Controller) []sms.Service {
+				svc0 := smsmocks.NewMockService(ctrl)
+				svc1 := smsmocks.NewMockService(ctrl)
+				svc1.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "15212345678").
+					Return(errors.New("provider down"))
+				svc0.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "15212345678").
+					Return(errors.New("provider down"))
+				return []sms.Service{svc0, svc1}
+			},
+			wantErr: ErrAllFailed,
+		},
+		{
+			name: "provider timeout fail over",
+			mock: func(ctrl *gomock.Controller) []sms.Service {
+				svc0 := smsmocks.NewMockService(ctrl)
+				svc1 := smsmocks.NewMockService(ctrl)
+				// 服务商自己的超时，调用方的 ctx 还没过期
+				svc1.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "15212345678").
+					Return(context.DeadlineExceeded)
+				svc0.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "15212345678").
+					Return(nil)
+				return []sms.Service{svc0, svc1}
+			},
+		},
+		{
+			name: "caller gave up",
+			mock: func(ctrl *gomock.Controller) []sms.Service {
+				svc0 := smsmocks.NewMockService(ctrl)
+				svc1 := smsmocks.NewMockService(ctrl)
+				svc1.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "15212345678").
+					Return(context.Canceled)
+				return []sms.Service{svc0, svc1}
+			},
+			ctx: func() context.Context {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx
+			},
+			wantErr: context.Canceled,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			ctx := context.Background()
+			if tc.ctx != nil {
+				ctx = tc.ctx()
+			}
+			svc := NewFailoverSMSService(tc.mock(ctrl), logger.NewNoOpLogger())
+			err := svc.Send(ctx, "tpl", []string{"123456"}, "15212345678")
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
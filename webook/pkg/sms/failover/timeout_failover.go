@@ -0,0 +1,49 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"webook/pkg/sms"
+)
+
+// TimeoutFailoverSMSService 连续超时 threshold 次之后，切换到下一个服务商
+// 超时往往说明服务商出问题了，而别的错误多半是请求本身的问题，不切换
+type TimeoutFailoverSMSService struct {
+	svcs []sms.Service
+	// 当前正在用的服务商
+	idx int32
+	// 连续超时的次数
+	cnt int32
+	// 阈值，连续超时超过这个数字就切换
+	threshold int32
+}
+
+func NewTimeoutFailoverSMSService(svcs []sms.Service, threshold int32) sms.Service {
+	return &TimeoutFailoverSMSService{
+		svcs:      svcs,
+		threshold: threshold,
+	}
+}
+
+func (t *TimeoutFailoverSMSService) Send(ctx context.Context, tplId string, args []string, numbers ...string) error {
+	idx := atomic.LoadInt32(&t.idx)
+	cnt := atomic.LoadInt32(&t.cnt)
+	if cnt >= t.threshold {
+		newIdx := (idx + 1) % int32(len(t.svcs))
+		// 并发的时候只有一个人能切换成功
+		if atomic.CompareAndSwapInt32(&t.idx, idx, newIdx) {
+			atomic.StoreInt32(&t.cnt, 0)
+		}
+		idx = atomic.LoadInt32(&t.idx)
+	}
+	err := t.svcs[idx].Send(ctx, tplId, args, numbers...)
+	switch {
+	case err == nil:
+		// 连续超时被打断了
+		atomic.StoreInt32(&t.cnt, 0)
+	case errors.Is(err, context.DeadlineExceeded):
+		atomic.AddInt32(&t.cnt, 1)
+	}
+	return err
+}
@@ -0,0 +1,111 @@
+package failover
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"webook/pkg/sms"
+	smsmocks "webook/pkg/sms/mocks"
+)
+
+func TestTimeoutFailoverSMSService_Send(t *testing.T) {
+	testCases := []struct {
+		name      string
+		mock      func(ctrl *gomock.Controller) []sms.Service
+		idx       int32
+		cnt       int32
+		threshold int32
+
+		wantErr error
+		wantIdx int32
+		wantCnt int32
+	}{
+		{
+			name: "no switch",
+			mock: func(ctrl *gomock.Controller) []sms.Service {
+				svc0 := smsmocks.NewMockService(ctrl)
+				svc0.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil)
+				return []sms.Service{svc0, smsmocks.NewMockService(ctrl)}
+			},
+			cnt:       2,
+			threshold: 3,
+			wantIdx:   0,
+			wantCnt:   0,
+		},
+		{
+			name: "switch after threshold",
+			mock: func(ctrl *gomock.Controller) []sms.Service {
+				svc1 := smsmocks.NewMockService(ctrl)
+				svc1.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil)
+				return []sms.Service{smsmocks.NewMockService(ctrl), svc1}
+			},
+			cnt:       3,
+			threshold: 3,
+			wantIdx:   1,
+			wantCnt:   0,
+		},
+		{
+			name: "wrap around",
+			mock: func(ctrl *gomock.Controller) []sms.Service {
+				svc0 := smsmocks.NewMockService(ctrl)
+				svc0.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil)
+				return []sms.Service{svc0, smsmocks.NewMockService(ctrl)}
+			},
+			idx:       1,
+			cnt:       3,
+			threshold: 3,
+			wantIdx:   0,
+			wantCnt:   0,
+		},
+		{
+			name: "timeout counted",
+			mock: func(ctrl *gomock.Controller) []sms.Service {
+				svc0 := smsmocks.NewMockService(ctrl)
+				svc0.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(context.DeadlineExceeded)
+				return []sms.Service{svc0, smsmocks.NewMockService(ctrl)}
+			},
+			cnt:       1,
+			threshold: 3,
+			wantErr:   context.DeadlineExceeded,
+			wantIdx:   0,
+			wantCnt:   2,
+		},
+		{
+			name: "other error not counted",
+			mock: func(ctrl *gomock.Controller) []sms.Service {
+				svc0 := smsmocks.NewMockService(ctrl)
+				svc0.EXPECT().Send(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(errors.New("bad template"))
+				return []sms.Service{svc0, smsmocks.NewMockService(ctrl)}
+			},
+			cnt:       1,
+			threshold: 3,
+			wantErr:   errors.New("bad template"),
+			wantIdx:   0,
+			wantCnt:   1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc := &TimeoutFailoverSMSService{
+				svcs:      tc.mock(ctrl),
+				idx:       tc.idx,
+				cnt:       tc.cnt,
+				threshold: tc.threshold,
+			}
+			err := svc.Send(context.Background(), "tpl", []string{"123456"}, "15212345678")
+			assert.Equal(t, tc.wantErr, err)
+			assert.Equal(t, tc.wantIdx, svc.idx)
+			assert.Equal(t, tc.wantCnt, svc.cnt)
+		})
+	}
+}
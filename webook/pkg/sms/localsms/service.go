@@ -0,0 +1,45 @@
+package localsms
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// Message 一条"发出去"的短信
+type Message struct {
+	TplId   string
+	Args    []string
+	Numbers []string
+}
+
+// Service 本地开发和测试用的实现，不真的发短信，只是打日志并且记下来
+type Service struct {
+	mu   sync.Mutex
+	msgs []Message
+}
+
+func NewService() *Service {
+	return &Service{}
+}
+
+func (s *Service) Send(ctx context.Context, tplId string, args []string, numbers ...string) error {
+	log.Println("发送短信", tplId, args, numbers)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.msgs = append(s.msgs, Message{
+		TplId:   tplId,
+		Args:    args,
+		Numbers: numbers,
+	})
+	return nil
+}
+
+// Messages 到目前为止发送过的所有短信
+func (s *Service) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := make([]Message, len(s.msgs))
+	copy(res, s.msgs)
+	return res
+}
@@ -0,0 +1,37 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"webook/pkg/ratelimit"
+	"webook/pkg/sms"
+)
+
+var ErrLimited = errors.New("短信服务触发了限流")
+
+// RateLimitSMSService 服务商一般都有 QPS 限制，超了会被拒绝，不如我们自己先挡住
+type RateLimitSMSService struct {
+	svc     sms.Service
+	limiter ratelimit.Limiter
+	key     string
+}
+
+func NewRateLimitSMSService(svc sms.Service, limiter ratelimit.Limiter, key string) sms.Service {
+	return &RateLimitSMSService{
+		svc:     svc,
+		limiter: limiter,
+		key:     key,
+	}
+}
+
+func (r *RateLimitSMSService) Send(ctx context.Context, tplId string, args []string, numbers ...string) error {
+	limited, err := r.limiter.Limit(ctx, r.key)
+	if err != nil {
+		// redis 出问题了，保守一点，不发
+		return err
+	}
+	if limited {
+		return ErrLimited
+	}
+	return r.svc.Send(ctx, tplId, args, numbers...)
+}
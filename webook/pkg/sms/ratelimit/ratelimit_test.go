@@ -0,0 +1,65 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"testing"
+	"webook/pkg/ratelimit"
+	limitmocks "webook/pkg/ratelimit/mocks"
+	"webook/pkg/sms"
+	smsmocks "webook/pkg/sms/mocks"
+)
+
+func TestRateLimitSMSService_Send(t *testing.T) {
+	testCases := []struct {
+		name    string
+		mock    func(ctrl *gomock.Controller) (sms.Service, ratelimit.Limiter)
+		wantErr error
+	}{
+		{
+			name: "not limited",
+			mock: func(ctrl *gomock.Controller) (sms.Service, ratelimit.Limiter) {
+				svc := smsmocks.NewMockService(ctrl)
+				limiter := limitmocks.NewMockLimiter(ctrl)
+				limiter.EXPECT().Limit(gomock.Any(), "sms:local").Return(false, nil)
+				svc.EXPECT().Send(gomock.Any(), "tpl", []string{"123456"}, "15212345678").
+					Return(nil)
+				return svc, limiter
+			},
+		},
+		{
+			name: "limited",
+			mock: func(ctrl *gomock.Controller) (sms.Service, ratelimit.Limiter) {
+				svc := smsmocks.NewMockService(ctrl)
+				limiter := limitmocks.NewMockLimiter(ctrl)
+				limiter.EXPECT().Limit(gomock.Any(), "sms:local").Return(true, nil)
+				return svc, limiter
+			},
+			wantErr: ErrLimited,
+		},
+		{
+			name: "limiter error",
+			mock: func(ctrl *gomock.Controller) (sms.Service, ratelimit.Limiter) {
+				svc := smsmocks.NewMockService(ctrl)
+				limiter := limitmocks.NewMockLimiter(ctrl)
+				limiter.EXPECT().Limit(gomock.Any(), "sms:local").
+					Return(false, errors.New("mock redis error"))
+				return svc, limiter
+			},
+			wantErr: errors.New("mock redis error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			svc, limiter := tc.mock(ctrl)
+			limitSvc := NewRateLimitSMSService(svc, limiter, "sms:local")
+			err := limitSvc.Send(context.Background(), "tpl", []string{"123456"}, "15212345678")
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,9 @@
+package sms
+
+import "context"
+
+// Service 发送短信的抽象，屏蔽掉不同的短信供应商
+type Service interface {
+	// Send 按照模板发送短信，args 是模板里面的参数，numbers 是手机号码
+	Send(ctx context.Context, tplId string, args []string, numbers ...string) error
+}
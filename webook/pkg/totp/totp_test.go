@@ -0,0 +1,58 @@
+package totp
+
+import (
+	"encoding/base32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestGenerateCode(t *testing.T) {
+	// RFC 6238 附录 B 的测试向量，取 8 位的后 6 位
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	testCases := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+	}
+	for _, tc := range testCases {
+		code, err := GenerateCode(secret, time.Unix(tc.unix, 0))
+		require.NoError(t, err)
+		assert.Equal(t, tc.want, code)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	now := time.Now()
+	code, err := GenerateCode(secret, now)
+	require.NoError(t, err)
+
+	assert.True(t, Validate(code, secret, now))
+	// 上一个周期的验证码也认
+	assert.True(t, Validate(code, secret, now.Add(time.Second*period)))
+	assert.False(t, Validate(code, secret, now.Add(time.Minute*5)))
+	assert.False(t, Validate("12345", secret, now))
+}
+
+func TestValidateStep(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	now := time.Unix(1700000000, 0)
+	code, err := GenerateCode(secret, now)
+	require.NoError(t, err)
+
+	step, ok := ValidateStep(code, secret, now)
+	assert.True(t, ok)
+	assert.Equal(t, now.Unix()/period, step)
+	// 过了一个周期还认，但时间步还是生成的那一个
+	step, ok = ValidateStep(code, secret, now.Add(time.Second*period))
+	assert.True(t, ok)
+	assert.Equal(t, now.Unix()/period, step)
+}
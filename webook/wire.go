@@ -5,12 +5,14 @@ package main
 import (
 	"github.com/google/wire"
 	"webook/internal/event"
+	"webook/internal/job"
 	"webook/internal/repository"
 	"webook/internal/repository/cache"
 	"webook/internal/repository/dao"
 	"webook/internal/service"
 	"webook/internal/web"
 	"webook/ioc"
+	ijwt "webook/pkg/ginx/jwt"
 )
 
 var thirdPartySet = wire.NewSet( // 第三方依赖
@@ -20,13 +22,41 @@ var thirdPartySet = wire.NewSet( // 第三方依赖
 	ioc.InitSaramaClient,
 	ioc.InitSyncProducer,
 	ioc.InitConsumers,
+	ioc.InitJobs,
+	ioc.InitSMSService,
+	ioc.InitWechatService,
+	ioc.InitEmailService,
+	ioc.InitUserDataService,
 )
 
 var userSvcProvider = wire.NewSet(
 	dao.NewUserDAO,
 	cache.NewUserCache,
 	repository.NewUserRepository,
-	service.NewUserService)
+	service.NewUserService,
+	cache.NewRedisLoginAttemptCache,
+	repository.NewLoginAttemptRepository,
+	service.NewLoginGuard,
+
+	cache.NewRedisCodeCache,
+	repository.NewCodeRepository,
+	service.NewCodeService,
+
+	dao.NewGORMAsyncSMSDAO,
+	repository.NewAsyncSMSRepository,
+
+	cache.NewRedisTokenCache,
+	repository.NewTokenRepository,
+	service.NewPasswordResetService,
+	service.NewEmailVerifyService,
+
+	dao.NewGORMMFADAO,
+	cache.NewRedisMFACache,
+	repository.NewMFARepository,
+	service.NewMFAService,
+
+	dao.NewGORMUserDataDAO,
+	repository.NewUserDataRepository)
 
 var articlSvcProvider = wire.NewSet(
 	dao.NewGORMArticleDAO,
@@ -39,8 +69,34 @@ var articlSvcProvider = wire.NewSet(
 	service.NewInteractiveService,
 	cache.NewInteractiveRedisCache,
 
+	dao.NewGORMFollowDAO,
+	cache.NewRedisFollowCache,
+	repository.NewFollowRepository,
+	service.NewFollowService,
+
+	dao.NewGORMBlockDAO,
+	cache.NewRedisBlockCache,
+	repository.NewBlockRepository,
+	service.NewBlockService,
+
+	service.NewAuthorService,
+
+	dao.NewGORMSeriesDAO,
+	cache.NewRedisSeriesCache,
+	repository.NewSeriesRepository,
+	service.NewSeriesService,
+
+	dao.NewGORMFeedDAO,
+	repository.NewFeedRepository,
+	service.NewFeedService,
+	// 消费者只依赖 FanOut，不能直接依赖 service 包
+	wire.Bind(new(event.FeedFanOut), new(service.FeedService)),
+
 	event.NewInteractiveReadEventConsumer,
+	event.NewFeedPublishedEventConsumer,
 	event.NewSaramaSyncProducer,
+
+	job.NewScheduledPublishJob,
 )
 
 func InitWebServer() *App {
@@ -50,8 +106,16 @@ func InitWebServer() *App {
 		articlSvcProvider,
 
 		// handler 部分
+		ijwt.NewRedisJWTHandler,
 		web.NewUserHandler,
 		web.NewArticleHandler,
+		web.NewOAuth2WechatHandler,
+		web.NewAdminHandler,
+		web.NewFollowHandler,
+		web.NewFeedHandler,
+		web.NewBlockHandler,
+		web.NewAuthorHandler,
+		web.NewSeriesHandler,
 		ioc.InitMiddlewares,
 		ioc.InitWeb,
 		wire.Struct(new(App), "*"),
@@ -9,49 +9,104 @@ package main
 import (
 	"github.com/google/wire"
 	"webook/internal/event"
+	"webook/internal/job"
 	"webook/internal/repository"
 	"webook/internal/repository/cache"
 	"webook/internal/repository/dao"
 	"webook/internal/service"
 	"webook/internal/web"
 	"webook/ioc"
+	"webook/pkg/ginx/jwt"
 )
 
 // Injectors from wire.go:
 
 func InitWebServer() *App {
 	cmdable := ioc.InitRedis()
-	v := ioc.InitMiddlewares(cmdable)
+	handler := jwt.NewRedisJWTHandler(cmdable)
+	v := ioc.InitMiddlewares(cmdable, handler)
 	db := ioc.InitDB()
 	userDAO := dao.NewUserDAO(db)
 	userCache := cache.NewUserCache(cmdable)
 	userRepository := repository.NewUserRepository(userDAO, userCache)
-	userService := service.NewUserService(userRepository)
-	userHandler := web.NewUserHandler(userService)
-	articleDAO := dao.NewGORMArticleDAO(db)
+	loginAttemptCache := cache.NewRedisLoginAttemptCache(cmdable)
+	loginAttemptRepository := repository.NewLoginAttemptRepository(loginAttemptCache)
+	tokenCache := cache.NewRedisTokenCache(cmdable)
+	tokenRepository := repository.NewTokenRepository(tokenCache)
+	emailService := ioc.InitEmailService()
+	loginGuard := service.NewLoginGuard(loginAttemptRepository, tokenRepository, userRepository, emailService)
+	userService := service.NewUserService(userRepository, loginGuard)
+	codeCache := cache.NewRedisCodeCache(cmdable)
+	codeRepository := repository.NewCodeRepository(codeCache)
+	asyncSMSDAO := dao.NewGORMAsyncSMSDAO(db)
+	asyncSMSRepository := repository.NewAsyncSMSRepository(asyncSMSDAO)
 	loggerV1 := ioc.InitLogger()
-	articleRepository := repository.NewArticleRepository(articleDAO, loggerV1)
-	articleService := service.NewArticleService(articleRepository)
+	smsService := ioc.InitSMSService(cmdable, asyncSMSRepository, loggerV1)
+	codeService := service.NewCodeService(codeRepository, smsService)
+	passwordResetService := service.NewPasswordResetService(tokenRepository, userRepository, emailService)
+	emailVerifyService := service.NewEmailVerifyService(tokenRepository, userRepository, emailService)
+	mfadao := dao.NewGORMMFADAO(db)
+	mfaCache := cache.NewRedisMFACache(cmdable)
+	mfaRepository := repository.NewMFARepository(mfadao, mfaCache)
+	mfaService := service.NewMFAService(mfaRepository, tokenRepository)
+	userDataDAO := dao.NewGORMUserDataDAO(db)
+	articleCache := cache.NewRedisArticleCache(cmdable)
+	followCache := cache.NewRedisFollowCache(cmdable)
+	blockCache := cache.NewRedisBlockCache(cmdable)
+	userDataRepository := repository.NewUserDataRepository(userDataDAO, userRepository, userCache, articleCache, followCache, blockCache)
+	userDataService := ioc.InitUserDataService(userRepository, userDataRepository, handler, loggerV1)
+	userHandler := web.NewUserHandler(userService, codeService, passwordResetService, emailVerifyService, mfaService, userDataService, handler)
+	articleDAO := dao.NewGORMArticleDAO(db)
+	seriesDAO := dao.NewGORMSeriesDAO(db)
+	seriesCache := cache.NewRedisSeriesCache(cmdable)
+	seriesRepository := repository.NewSeriesRepository(seriesDAO, seriesCache, loggerV1)
+	articleRepository := repository.NewArticleRepository(articleDAO, articleCache, userRepository, seriesRepository, loggerV1)
+	client := ioc.InitSaramaClient()
+	syncProducer := ioc.InitSyncProducer(client)
+	producer := event.NewSaramaSyncProducer(syncProducer)
+	articleService := service.NewArticleService(articleRepository, producer, loggerV1)
 	interactiveDAO := dao.NewGORMInteractiveDAO(db)
 	interactiveCache := cache.NewInteractiveRedisCache(cmdable)
 	interactiveRepository := repository.NewCachedInteractiveRepository(interactiveDAO, loggerV1, interactiveCache)
-	interactiveService := service.NewInteractiveService(interactiveRepository)
-	articleHandler := web.NewArticleHandler(articleService, interactiveService, loggerV1)
-	engine := ioc.InitWeb(v, userHandler, articleHandler)
-	client := ioc.InitSaramaClient()
+	blockDAO := dao.NewGORMBlockDAO(db)
+	blockRepository := repository.NewBlockRepository(blockDAO, blockCache, loggerV1)
+	interactiveService := service.NewInteractiveService(interactiveRepository, articleRepository, blockRepository)
+	followDAO := dao.NewGORMFollowDAO(db)
+	followRepository := repository.NewFollowRepository(followDAO, followCache, loggerV1)
+	followService := service.NewFollowService(followRepository, userRepository)
+	seriesService := service.NewSeriesService(seriesRepository)
+	blockService := service.NewBlockService(blockRepository, userRepository)
+	articleHandler := web.NewArticleHandler(articleService, interactiveService, followService, seriesService, blockService, loggerV1)
+	wechatService := ioc.InitWechatService()
+	oAuth2WechatHandler := web.NewOAuth2WechatHandler(wechatService, userService, mfaService, handler, loggerV1)
+	adminHandler := web.NewAdminHandler(userService, handler, loggerV1)
+	followHandler := web.NewFollowHandler(followService, loggerV1)
+	feedDAO := dao.NewGORMFeedDAO(db)
+	feedRepository := repository.NewFeedRepository(feedDAO)
+	feedService := service.NewFeedService(feedRepository)
+	feedHandler := web.NewFeedHandler(feedService, loggerV1)
+	blockHandler := web.NewBlockHandler(blockService, loggerV1)
+	authorService := service.NewAuthorService(userRepository, articleRepository, followRepository)
+	authorHandler := web.NewAuthorHandler(authorService, loggerV1)
+	seriesHandler := web.NewSeriesHandler(seriesService, blockService, loggerV1)
+	engine := ioc.InitWeb(v, userHandler, articleHandler, oAuth2WechatHandler, adminHandler, followHandler, feedHandler, blockHandler, authorHandler, seriesHandler)
 	interactiveReadEventConsumer := event.NewInteractiveReadEventConsumer(interactiveRepository, client, loggerV1)
-	v2 := ioc.InitConsumers(interactiveReadEventConsumer)
+	feedPublishedEventConsumer := event.NewFeedPublishedEventConsumer(feedService, client, loggerV1)
+	v2 := ioc.InitConsumers(interactiveReadEventConsumer, feedPublishedEventConsumer)
+	scheduledPublishJob := job.NewScheduledPublishJob(articleService, loggerV1)
+	v3 := ioc.InitJobs(scheduledPublishJob)
 	app := &App{
 		server:    engine,
 		consumers: v2,
+		jobs:      v3,
 	}
 	return app
 }
 
 // wire.go:
 
-var thirdPartySet = wire.NewSet(ioc.InitRedis, ioc.InitDB, ioc.InitLogger, ioc.InitSaramaClient, ioc.InitSyncProducer, ioc.InitConsumers)
+var thirdPartySet = wire.NewSet(ioc.InitRedis, ioc.InitDB, ioc.InitLogger, ioc.InitSaramaClient, ioc.InitSyncProducer, ioc.InitConsumers, ioc.InitJobs, ioc.InitSMSService, ioc.InitWechatService, ioc.InitEmailService, ioc.InitUserDataService)
 
-var userSvcProvider = wire.NewSet(dao.NewUserDAO, cache.NewUserCache, repository.NewUserRepository, service.NewUserService)
+var userSvcProvider = wire.NewSet(dao.NewUserDAO, cache.NewUserCache, repository.NewUserRepository, service.NewUserService, cache.NewRedisLoginAttemptCache, repository.NewLoginAttemptRepository, service.NewLoginGuard, cache.NewRedisCodeCache, repository.NewCodeRepository, service.NewCodeService, dao.NewGORMAsyncSMSDAO, repository.NewAsyncSMSRepository, cache.NewRedisTokenCache, repository.NewTokenRepository, service.NewPasswordResetService, service.NewEmailVerifyService, dao.NewGORMMFADAO, cache.NewRedisMFACache, repository.NewMFARepository, service.NewMFAService, dao.NewGORMUserDataDAO, repository.NewUserDataRepository)
 
-var articlSvcProvider = wire.NewSet(dao.NewGORMArticleDAO, repository.NewArticleRepository, service.NewArticleService, cache.NewRedisArticleCache, dao.NewGORMInteractiveDAO, repository.NewCachedInteractiveRepository, service.NewInteractiveService, cache.NewInteractiveRedisCache, event.NewInteractiveReadEventConsumer, event.NewSaramaSyncProducer)
+var articlSvcProvider = wire.NewSet(dao.NewGORMArticleDAO, repository.NewArticleRepository, service.NewArticleService, cache.NewRedisArticleCache, dao.NewGORMInteractiveDAO, repository.NewCachedInteractiveRepository, service.NewInteractiveService, cache.NewInteractiveRedisCache, dao.NewGORMFollowDAO, cache.NewRedisFollowCache, repository.NewFollowRepository, service.NewFollowService, dao.NewGORMBlockDAO, cache.NewRedisBlockCache, repository.NewBlockRepository, service.NewBlockService, service.NewAuthorService, dao.NewGORMSeriesDAO, cache.NewRedisSeriesCache, repository.NewSeriesRepository, service.NewSeriesService, dao.NewGORMFeedDAO, repository.NewFeedRepository, service.NewFeedService, wire.Bind(new(event.FeedFanOut), new(service.FeedService)), event.NewInteractiveReadEventConsumer, event.NewFeedPublishedEventConsumer, event.NewSaramaSyncProducer, job.NewScheduledPublishJob)